@@ -0,0 +1,110 @@
+package config
+
+import (
+	"finance-tracker-backend/models"
+	"log"
+
+	"gorm.io/gorm"
+)
+
+// Kolom yang menunjuk ke category milik user, di-remap saat category global
+// disalin menjadi milik user. Query-nya menerima (new_id, old_id, user_id).
+var categoryReferences = []string{
+	"UPDATE transactions SET category_id = ? WHERE category_id = ? AND user_id = ?",
+	"UPDATE transaction_splits SET category_id = ? WHERE category_id = ? AND transaction_id IN (SELECT id FROM transactions WHERE user_id = ?)",
+	"UPDATE recurring_transactions SET category_id = ? WHERE category_id = ? AND user_id = ?",
+	"UPDATE budgets SET category_id = ? WHERE category_id = ? AND user_id = ?",
+	"UPDATE envelopes SET category_id = ? WHERE category_id = ? AND user_id = ?",
+	"UPDATE goals SET category_id = ? WHERE category_id = ? AND user_id = ?",
+	"UPDATE payees SET default_category_id = ? WHERE default_category_id = ? AND user_id = ?",
+}
+
+// CopyCategories menyalin category (beserta hubungan parent-nya) menjadi milik user.
+// Mengembalikan map ID lama -> ID baru.
+func CopyCategories(tx *gorm.DB, userID uint, categories []models.Category) (map[uint]uint, error) {
+	mapping := make(map[uint]uint, len(categories))
+	copies := make([]models.Category, len(categories))
+	for i, category := range categories {
+		copies[i] = models.Category{UserID: userID, Name: category.Name, Type: category.Type}
+	}
+	if len(copies) == 0 {
+		return mapping, nil
+	}
+	if err := tx.Create(&copies).Error; err != nil {
+		return nil, err
+	}
+	for i, category := range categories {
+		mapping[category.ID] = copies[i].ID
+	}
+
+	// Parent diisi setelah semua ID baru diketahui
+	for i, category := range categories {
+		if category.ParentID == nil {
+			continue
+		}
+		parentID, ok := mapping[*category.ParentID]
+		if !ok {
+			continue
+		}
+		if err := tx.Model(&copies[i]).Update("parent_id", parentID).Error; err != nil {
+			return nil, err
+		}
+	}
+	return mapping, nil
+}
+
+// CopyDefaultCategories menyalin category default sistem (user_id = 0) ke user baru
+func CopyDefaultCategories(tx *gorm.DB, userID uint) error {
+	var defaults []models.Category
+	if err := tx.Where("user_id = ?", 0).Order("id ASC").Find(&defaults).Error; err != nil {
+		return err
+	}
+	_, err := CopyCategories(tx, userID, defaults)
+	return err
+}
+
+// MigrateCategoryOwnership memindahkan user lama dari category global ke salinan miliknya sendiri.
+// Dulu semua user berbagi satu tabel category; sekarang category dengan user_id = 0 hanya
+// default sistem yang disalin ke user baru. User yang belum punya category sama sekali
+// mendapat salinan semua category global, dan semua referensinya di-remap ke salinan itu.
+// Aman dijalankan berulang kali: user yang sudah punya category (termasuk yang sudah dihapus) dilewati.
+func MigrateCategoryOwnership() error {
+	var globals []models.Category
+	if err := DB.Where("user_id = ?", 0).Order("id ASC").Find(&globals).Error; err != nil {
+		return err
+	}
+	if len(globals) == 0 {
+		return nil
+	}
+
+	var userIDs []uint
+	if err := DB.Model(&models.User{}).
+		Where("id NOT IN (SELECT DISTINCT user_id FROM categories WHERE user_id <> 0)").
+		Pluck("id", &userIDs).Error; err != nil {
+		return err
+	}
+
+	for _, userID := range userIDs {
+		err := DB.Transaction(func(tx *gorm.DB) error {
+			mapping, err := CopyCategories(tx, userID, globals)
+			if err != nil {
+				return err
+			}
+			for oldID, newID := range mapping {
+				for _, stmt := range categoryReferences {
+					if err := tx.Exec(stmt, newID, oldID, userID).Error; err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if len(userIDs) > 0 {
+		log.Printf("Copied shared categories to %d existing user(s)", len(userIDs))
+	}
+	return nil
+}
@@ -27,6 +27,8 @@ func ConnectDB() {
 	// Connect to database with Logger
 	DB, err = gorm.Open(mysql.Open(dsn), &gorm.Config{
 		Logger: logger.Default.LogMode(logger.Info),
+		// Error duplicate key jadi gorm.ErrDuplicatedKey supaya bisa dipetakan ke 409
+		TranslateError: true,
 	})
 	if err != nil {
 		log.Fatal("Failed to connect to database:", err)
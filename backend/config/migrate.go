@@ -0,0 +1,45 @@
+package config
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+)
+
+// Kolom uang yang dulu dibuat sebagai decimal(15,2) (float64 di Go) dan
+// sekarang memakai models.Money dengan decimal(19,4)
+var moneyColumns = []struct {
+	Table      string
+	Column     string
+	Definition string
+}{
+	{"transactions", "amount", "DECIMAL(19,4) NOT NULL"},
+	{"accounts", "opening_balance", "DECIMAL(19,4) NOT NULL DEFAULT 0"},
+	{"transfers", "amount", "DECIMAL(19,4) NOT NULL"},
+	{"transfers", "to_amount", "DECIMAL(19,4) NOT NULL"},
+}
+
+// MigrateMoneyColumns melebarkan kolom uang lama ke decimal(19,4) sebelum AutoMigrate.
+// Hanya menambah digit, jadi data decimal(15,2) yang sudah ada tetap exact.
+// Aman dijalankan berulang kali: kolom yang sudah 4 desimal atau belum ada dilewati.
+func MigrateMoneyColumns() error {
+	for _, col := range moneyColumns {
+		var scale sql.NullInt64
+		if err := DB.Raw(
+			"SELECT NUMERIC_SCALE FROM information_schema.COLUMNS WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ? AND COLUMN_NAME = ?",
+			col.Table, col.Column,
+		).Scan(&scale).Error; err != nil {
+			return err
+		}
+		if !scale.Valid || scale.Int64 >= 4 {
+			continue
+		}
+
+		stmt := fmt.Sprintf("ALTER TABLE `%s` MODIFY `%s` %s", col.Table, col.Column, col.Definition)
+		if err := DB.Exec(stmt).Error; err != nil {
+			return fmt.Errorf("migrate %s.%s: %w", col.Table, col.Column, err)
+		}
+		log.Printf("Migrated %s.%s to exact money column", col.Table, col.Column)
+	}
+	return nil
+}
@@ -0,0 +1,191 @@
+package controllers
+
+import (
+	"finance-tracker-backend/config"
+	"finance-tracker-backend/models"
+	"finance-tracker-backend/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type AccountRequest struct {
+	Name           string        `json:"name"`
+	Kind           string        `json:"kind"` // "bank", "cash", "credit_card" atau "ewallet"
+	OpeningBalance *models.Money `json:"opening_balance"`
+	Currency       string        `json:"currency"`
+	Archived       *bool         `json:"archived"`
+}
+
+func isValidAccountKind(kind string) bool {
+	switch kind {
+	case "bank", "cash", "credit_card", "ewallet":
+		return true
+	}
+	return false
+}
+
+// Cari account milik user, dipakai juga oleh transaction controller
+func findUserAccount(userID uint, accountID interface{}) (*models.Account, error) {
+	var account models.Account
+	if err := config.DB.Where("id = ? AND user_id = ?", accountID, userID).First(&account).Error; err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+// Get All Accounts
+func GetAccounts(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	var accounts []models.Account
+	query := config.DB.Where("user_id = ?", userID)
+
+	// Archived accounts disembunyikan kecuali diminta
+	if c.Query("include_archived") != "true" {
+		query = query.Where("archived = ?", false)
+	}
+
+	if err := query.Order("name ASC").Find(&accounts).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch accounts"})
+	}
+
+	return c.JSON(fiber.Map{
+		"accounts": accounts,
+	})
+}
+
+// Get Single Account
+func GetAccount(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	account, err := findUserAccount(userID, c.Params("id"))
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Account not found"})
+	}
+
+	return c.JSON(fiber.Map{
+		"account": account,
+	})
+}
+
+// Create Account
+func CreateAccount(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	req := new(AccountRequest)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request"})
+	}
+
+	// Validasi
+	if req.Name == "" || req.Kind == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "Name and kind are required"})
+	}
+	if !isValidAccountKind(req.Kind) {
+		return c.Status(400).JSON(fiber.Map{"error": "Kind must be 'bank', 'cash', 'credit_card' or 'ewallet'"})
+	}
+
+	account := models.Account{
+		UserID:   userID,
+		Name:     req.Name,
+		Kind:     req.Kind,
+		Currency: userBaseCurrency(userID),
+	}
+	if req.OpeningBalance != nil {
+		account.OpeningBalance = *req.OpeningBalance
+	}
+	if req.Currency != "" {
+		currency, ok := utils.NormalizeCurrency(req.Currency)
+		if !ok {
+			return c.Status(400).JSON(fiber.Map{"error": "Currency must be a 3-letter ISO code"})
+		}
+		account.Currency = currency
+	}
+	if req.Archived != nil {
+		account.Archived = *req.Archived
+	}
+	account.OpeningBalance = account.OpeningBalance.Round(account.Currency)
+
+	if err := config.DB.Create(&account).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to create account"})
+	}
+
+	return c.Status(201).JSON(fiber.Map{
+		"message": "Account created successfully",
+		"account": account,
+	})
+}
+
+// Update Account
+func UpdateAccount(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	account, err := findUserAccount(userID, c.Params("id"))
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Account not found"})
+	}
+
+	req := new(AccountRequest)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request"})
+	}
+
+	// Update fields
+	if req.Name != "" {
+		account.Name = req.Name
+	}
+	if req.Kind != "" {
+		if !isValidAccountKind(req.Kind) {
+			return c.Status(400).JSON(fiber.Map{"error": "Kind must be 'bank', 'cash', 'credit_card' or 'ewallet'"})
+		}
+		account.Kind = req.Kind
+	}
+	if req.OpeningBalance != nil {
+		account.OpeningBalance = *req.OpeningBalance
+	}
+	if req.Currency != "" {
+		currency, ok := utils.NormalizeCurrency(req.Currency)
+		if !ok {
+			return c.Status(400).JSON(fiber.Map{"error": "Currency must be a 3-letter ISO code"})
+		}
+		account.Currency = currency
+	}
+	if req.Archived != nil {
+		account.Archived = *req.Archived
+	}
+	account.OpeningBalance = account.OpeningBalance.Round(account.Currency)
+
+	if err := config.DB.Save(account).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to update account"})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Account updated successfully",
+		"account": account,
+	})
+}
+
+// Delete Account (bukan DeleteAccount di auth_controller yang menghapus user)
+func DeleteFinancialAccount(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	account, err := findUserAccount(userID, c.Params("id"))
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Account not found"})
+	}
+
+	// Account yang masih punya transaksi cukup di-archive supaya history tetap utuh
+	var count int64
+	config.DB.Model(&models.Transaction{}).Where("account_id = ?", account.ID).Count(&count)
+	if count > 0 {
+		return c.Status(400).JSON(fiber.Map{"error": "Account still has transactions, archive it instead"})
+	}
+
+	if err := config.DB.Delete(account).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to delete account"})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Account deleted successfully",
+	})
+}
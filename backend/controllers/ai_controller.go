@@ -3,8 +3,11 @@ package controllers
 import (
 	"context"
 	"encoding/json"
+	"finance-tracker-backend/config"
+	"finance-tracker-backend/models"
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/generative-ai-go/genai"
@@ -17,10 +20,12 @@ type AIRequest struct {
 }
 
 type TransactionSummary struct {
-	Date     string  `json:"date"`
-	Category string  `json:"category"`
-	Amount   float64 `json:"amount"`
-	Type     string  `json:"type"` // income or expense
+	ID       uint         `json:"id,omitempty"` // when set, split transactions are expanded per line
+	Date     string       `json:"date"`
+	Category string       `json:"category"`
+	Amount   models.Money `json:"amount"`
+	Currency string       `json:"currency,omitempty"` // empty means the user's base currency
+	Type     string       `json:"type"`               // income, expense or transfer
 }
 
 func GetFinancialInsight(c *fiber.Ctx) error {
@@ -34,15 +39,60 @@ func GetFinancialInsight(c *fiber.Ctx) error {
 		return c.Status(400).JSON(fiber.Map{"error": "Invalid request body"})
 	}
 
+	// Convert every amount into the user's base currency so totals are comparable
+	userID := c.Locals("userID").(uint)
+	baseCurrency := userBaseCurrency(userID)
+	rates, err := loadRateTable(userID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to load exchange rates"})
+	}
+
+	// Without explicit data, summarise the user's latest transactions from the database
+	if len(req.Transactions) == 0 {
+		summaries, err := recentTransactionSummaries(userID, 100)
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": "Failed to load transactions"})
+		}
+		req.Transactions = summaries
+	} else {
+		summaries, err := expandSplitSummaries(userID, req.Transactions)
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": "Failed to load transaction splits"})
+		}
+		req.Transactions = summaries
+	}
+
 	// Simplify data for token efficiency
-	var totalIncome, totalExpense float64
+	// Transfers between accounts are neither income nor expense, so drop them
+	var totalIncome, totalExpense models.Money
+	filtered := make([]TransactionSummary, 0, len(req.Transactions))
 	for _, tx := range req.Transactions {
-		if tx.Type == "income" {
+		if tx.Currency != "" && tx.Currency != baseCurrency {
+			date := time.Now()
+			if len(tx.Date) >= 10 {
+				if parsed, err := time.Parse("2006-01-02", tx.Date[:10]); err == nil {
+					date = parsed
+				}
+			}
+			converted, err := rates.Convert(tx.Amount, tx.Currency, baseCurrency, date)
+			if err != nil {
+				return c.Status(422).JSON(fiber.Map{"error": err.Error()})
+			}
+			tx.Amount = converted
+		}
+		tx.Currency = baseCurrency
+
+		switch tx.Type {
+		case "income":
 			totalIncome += tx.Amount
-		} else {
+		case "expense":
 			totalExpense += tx.Amount
+		default:
+			continue
 		}
+		filtered = append(filtered, tx)
 	}
+	req.Transactions = filtered
 	// summaryText = fmt.Sprintf("Total Income: %.2f, Total Expense: %.2f. Trasaction details: ", totalIncome, totalExpense)
 
 	// Limit transactions to last 20 for context window efficiency if needed,
@@ -64,9 +114,11 @@ func GetFinancialInsight(c *fiber.Ctx) error {
 		Analisa transaksi berikut dan berikan saran keuangan singkat (maksimal 2 kalimat) dalam Bahasa Indonesia.
 		Fokus pada penghematan atau pola pengeluaran yang tidak wajar. Jangan terlalu kaku.
 		
+		Semua nominal dalam mata uang %s.
+
 		Data Transaksi:
 		%s
-	`, string(txJSON))
+	`, baseCurrency, string(txJSON))
 
 	resp, err := model.GenerateContent(ctx, genai.Text(prompt))
 	if err != nil {
@@ -88,3 +140,79 @@ func GetFinancialInsight(c *fiber.Ctx) error {
 
 	return c.JSON(fiber.Map{"insight": aiResponse})
 }
+
+// Build summaries from the latest transactions, one entry per split line
+func recentTransactionSummaries(userID uint, limit int) ([]TransactionSummary, error) {
+	var transactions []models.Transaction
+	if err := config.DB.Where("user_id = ?", userID).
+		Preload("Category").Preload("Splits.Category").
+		Order("date DESC").Limit(limit).Find(&transactions).Error; err != nil {
+		return nil, err
+	}
+
+	summaries := make([]TransactionSummary, 0, len(transactions))
+	for _, tx := range transactions {
+		base := TransactionSummary{
+			ID:       tx.ID,
+			Date:     tx.Date.Format("2006-01-02"),
+			Amount:   tx.Amount,
+			Currency: tx.Currency,
+			Type:     "transfer",
+		}
+		if tx.Category != nil {
+			base.Category = tx.Category.Name
+			base.Type = tx.Category.Type
+		}
+		summaries = append(summaries, splitSummaries(base, tx.Splits)...)
+	}
+	return summaries, nil
+}
+
+// Replace summaries that point at split transactions with one entry per split line
+func expandSplitSummaries(userID uint, summaries []TransactionSummary) ([]TransactionSummary, error) {
+	var ids []uint
+	for _, summary := range summaries {
+		if summary.ID != 0 {
+			ids = append(ids, summary.ID)
+		}
+	}
+	if len(ids) == 0 {
+		return summaries, nil
+	}
+
+	var splits []models.TransactionSplit
+	if err := config.DB.
+		Joins("JOIN transactions ON transactions.id = transaction_splits.transaction_id").
+		Where("transaction_splits.transaction_id IN ? AND transactions.user_id = ?", ids, userID).
+		Preload("Category").Find(&splits).Error; err != nil {
+		return nil, err
+	}
+
+	byTransaction := make(map[uint][]models.TransactionSplit)
+	for _, split := range splits {
+		byTransaction[split.TransactionID] = append(byTransaction[split.TransactionID], split)
+	}
+
+	expanded := make([]TransactionSummary, 0, len(summaries))
+	for _, summary := range summaries {
+		expanded = append(expanded, splitSummaries(summary, byTransaction[summary.ID])...)
+	}
+	return expanded, nil
+}
+
+func splitSummaries(base TransactionSummary, splits []models.TransactionSplit) []TransactionSummary {
+	if len(splits) == 0 {
+		return []TransactionSummary{base}
+	}
+	lines := make([]TransactionSummary, 0, len(splits))
+	for _, split := range splits {
+		line := base
+		line.Amount = split.Amount
+		if split.Category != nil {
+			line.Category = split.Category.Name
+			line.Type = split.Category.Type
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
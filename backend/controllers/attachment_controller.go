@@ -0,0 +1,269 @@
+package controllers
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"finance-tracker-backend/config"
+	"finance-tracker-backend/models"
+	"finance-tracker-backend/storage"
+	"finance-tracker-backend/utils"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+const (
+	// Batas ukuran satu file attachment (foto struk dari HP biasanya 2-5 MB)
+	maxAttachmentSize = 10 << 20
+	// Sisi terpanjang thumbnail dalam pixel
+	thumbnailSize = 320
+)
+
+// MIME type yang boleh di-upload (dideteksi dari isi file, bukan dari nama file) beserta extension-nya
+var allowedAttachmentTypes = map[string]string{
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+	"image/gif":       ".gif",
+	"image/webp":      ".webp",
+	"application/pdf": ".pdf",
+}
+
+// Key storage acak per user supaya nama file asli tidak pernah dipakai sebagai path
+func newAttachmentKey(userID uint, ext string) (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("attachments/%d/%s%s", userID, hex.EncodeToString(buf), ext), nil
+}
+
+// Cari attachment milik user
+func findUserAttachment(userID uint, id interface{}) (*models.Attachment, error) {
+	var attachment models.Attachment
+	if err := config.DB.Where("id = ? AND user_id = ?", id, userID).First(&attachment).Error; err != nil {
+		return nil, err
+	}
+	return &attachment, nil
+}
+
+// Hapus baris attachment milik transaksi-transaksi ini di dalam DB transaction.
+// File-nya dikembalikan supaya baru dihapus dari storage setelah commit.
+func deleteTransactionAttachments(tx *gorm.DB, transactionIDs []uint) ([]models.Attachment, error) {
+	var attachments []models.Attachment
+	if len(transactionIDs) == 0 {
+		return nil, nil
+	}
+	if err := tx.Where("transaction_id IN ?", transactionIDs).Find(&attachments).Error; err != nil {
+		return nil, err
+	}
+	if len(attachments) == 0 {
+		return nil, nil
+	}
+	if err := tx.Where("transaction_id IN ?", transactionIDs).Delete(&models.Attachment{}).Error; err != nil {
+		return nil, err
+	}
+	return attachments, nil
+}
+
+// Hapus file (dan thumbnail) dari storage. Gagal hapus cukup di-log karena metadata sudah terhapus.
+func removeAttachmentFiles(attachments []models.Attachment) {
+	for _, attachment := range attachments {
+		for _, key := range []string{attachment.StorageKey, attachment.ThumbnailKey} {
+			if key == "" {
+				continue
+			}
+			if err := storage.Files.Delete(key); err != nil {
+				log.Printf("Failed to delete attachment file %s: %v", key, err)
+			}
+		}
+	}
+}
+
+// Kirim isi file dari storage ke client
+func sendStoredFile(c *fiber.Ctx, key, contentType, fileName string) error {
+	reader, err := storage.Files.Get(key)
+	if errors.Is(err, storage.ErrNotFound) {
+		return c.Status(404).JSON(fiber.Map{"error": "File not found"})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to read file"})
+	}
+
+	disposition := "inline"
+	if c.Query("download") == "true" {
+		disposition = "attachment"
+	}
+	c.Set(fiber.HeaderContentType, contentType)
+	c.Set(fiber.HeaderContentDisposition, mime.FormatMediaType(disposition, map[string]string{"filename": fileName}))
+	c.Set(fiber.HeaderXContentTypeOptions, "nosniff")
+	c.Set(fiber.HeaderCacheControl, "private, max-age=3600")
+
+	// Stream di-close oleh fasthttp setelah selesai dikirim
+	return c.SendStream(reader)
+}
+
+// Get Attachments of a Transaction
+func GetAttachments(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+	id := c.Params("id")
+
+	var transaction models.Transaction
+	if err := config.DB.Where("id = ? AND user_id = ?", id, userID).First(&transaction).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Transaction not found"})
+	}
+
+	var attachments []models.Attachment
+	if err := config.DB.Where("transaction_id = ? AND user_id = ?", transaction.ID, userID).Order("created_at ASC").Find(&attachments).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch attachments"})
+	}
+
+	return c.JSON(fiber.Map{
+		"attachments": attachments,
+	})
+}
+
+// Upload Attachment ke transaksi (multipart field "file")
+func UploadAttachment(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+	id := c.Params("id")
+
+	var transaction models.Transaction
+	if err := config.DB.Where("id = ? AND user_id = ?", id, userID).First(&transaction).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Transaction not found"})
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "File is required"})
+	}
+	if fileHeader.Size > maxAttachmentSize {
+		return c.Status(400).JSON(fiber.Map{"error": "File is too large (max 10 MB)"})
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Failed to read file"})
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxAttachmentSize+1))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Failed to read file"})
+	}
+	if len(data) == 0 {
+		return c.Status(400).JSON(fiber.Map{"error": "File is empty"})
+	}
+	if len(data) > maxAttachmentSize {
+		return c.Status(400).JSON(fiber.Map{"error": "File is too large (max 10 MB)"})
+	}
+
+	// Validasi tipe file dari isinya
+	contentType := http.DetectContentType(data)
+	ext, ok := allowedAttachmentTypes[contentType]
+	if !ok {
+		return c.Status(415).JSON(fiber.Map{"error": "Only JPEG, PNG, GIF, WebP images and PDF files are allowed"})
+	}
+
+	key, err := newAttachmentKey(userID, ext)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to save attachment"})
+	}
+	if err := storage.Files.Put(key, data, contentType); err != nil {
+		log.Println("Failed to store attachment:", err)
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to save attachment"})
+	}
+
+	fileName := filepath.Base(strings.ReplaceAll(fileHeader.Filename, "\\", "/"))
+	if fileName == "." || fileName == "/" {
+		fileName = "attachment" + ext
+	}
+	// Ambil 255 karakter terakhir (ekstensi tetap ada), dipotong per rune supaya UTF-8 tidak terbelah
+	if runes := []rune(fileName); len(runes) > 255 {
+		fileName = string(runes[len(runes)-255:])
+	}
+
+	attachment := models.Attachment{
+		UserID:        userID,
+		TransactionID: transaction.ID,
+		FileName:      fileName,
+		ContentType:   contentType,
+		Size:          int64(len(data)),
+		StorageKey:    key,
+	}
+
+	// Thumbnail untuk gambar, kalau gagal (format tidak didukung, gambar rusak) upload tetap jalan
+	if strings.HasPrefix(contentType, "image/") {
+		if thumb, err := utils.MakeThumbnail(data, thumbnailSize); err == nil {
+			thumbKey := strings.TrimSuffix(key, ext) + "_thumb.jpg"
+			if err := storage.Files.Put(thumbKey, thumb, "image/jpeg"); err == nil {
+				attachment.ThumbnailKey = thumbKey
+				attachment.HasThumbnail = true
+			}
+		}
+	}
+
+	if err := config.DB.Create(&attachment).Error; err != nil {
+		removeAttachmentFiles([]models.Attachment{attachment})
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to save attachment"})
+	}
+
+	return c.Status(201).JSON(fiber.Map{
+		"message":    "Attachment uploaded successfully",
+		"attachment": attachment,
+	})
+}
+
+// Download Attachment (?download=true untuk memaksa download)
+func DownloadAttachment(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	attachment, err := findUserAttachment(userID, c.Params("id"))
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Attachment not found"})
+	}
+
+	return sendStoredFile(c, attachment.StorageKey, attachment.ContentType, attachment.FileName)
+}
+
+// Get Attachment Thumbnail (hanya untuk gambar)
+func GetAttachmentThumbnail(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	attachment, err := findUserAttachment(userID, c.Params("id"))
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Attachment not found"})
+	}
+	if !attachment.HasThumbnail {
+		return c.Status(404).JSON(fiber.Map{"error": "Attachment has no thumbnail"})
+	}
+
+	name := strings.TrimSuffix(attachment.FileName, filepath.Ext(attachment.FileName)) + "_thumb.jpg"
+	return sendStoredFile(c, attachment.ThumbnailKey, "image/jpeg", name)
+}
+
+// Delete Attachment
+func DeleteAttachment(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	attachment, err := findUserAttachment(userID, c.Params("id"))
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Attachment not found"})
+	}
+
+	if err := config.DB.Delete(attachment).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to delete attachment"})
+	}
+	removeAttachmentFiles([]models.Attachment{*attachment})
+
+	return c.JSON(fiber.Map{
+		"message": "Attachment deleted successfully",
+	})
+}
@@ -6,6 +6,7 @@ import (
 	"finance-tracker-backend/utils"
 
 	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
 )
 
 type RegisterRequest struct {
@@ -50,7 +51,14 @@ func Register(c *fiber.Ctx) error {
 		Password: hashedPassword,
 	}
 
-	if err := config.DB.Create(&user).Error; err != nil {
+	// User baru langsung mendapat salinan category default
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&user).Error; err != nil {
+			return err
+		}
+		return config.CopyDefaultCategories(tx, user.ID)
+	})
+	if err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": "Failed to create user"})
 	}
 
@@ -122,9 +130,10 @@ func GetProfile(c *fiber.Ctx) error {
 
 	return c.JSON(fiber.Map{
 		"user": fiber.Map{
-			"id":    user.ID,
-			"name":  user.Name,
-			"email": user.Email,
+			"id":            user.ID,
+			"name":          user.Name,
+			"email":         user.Email,
+			"base_currency": user.BaseCurrency,
 		},
 	})
 }
@@ -0,0 +1,367 @@
+package controllers
+
+import (
+	"finance-tracker-backend/config"
+	"finance-tracker-backend/models"
+	"finance-tracker-backend/utils"
+	"math"
+	"math/big"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type BudgetRequest struct {
+	CategoryID uint         `json:"category_id"`
+	Period     string       `json:"period"` // "weekly", "monthly" (default) atau "yearly"
+	Amount     models.Money `json:"amount"`
+	Currency   string       `json:"currency"` // kosong = base currency user
+}
+
+type BudgetProgress struct {
+	BudgetID     uint         `json:"budget_id"`
+	CategoryID   uint         `json:"category_id"`
+	CategoryName string       `json:"category_name"`
+	Period       string       `json:"period"`
+	StartDate    string       `json:"start_date"`
+	EndDate      string       `json:"end_date"`
+	Currency     string       `json:"currency"`
+	Amount       models.Money `json:"amount"`
+	Spent        models.Money `json:"spent"`
+	Remaining    models.Money `json:"remaining"` // negatif kalau over budget
+	Percentage   float64      `json:"percentage"`
+	OverBudget   bool         `json:"over_budget"`
+}
+
+func isValidBudgetPeriod(period string) bool {
+	switch period {
+	case "weekly", "monthly", "yearly":
+		return true
+	}
+	return false
+}
+
+// Rentang tanggal periode yang memuat day (minggu dimulai Senin), end inklusif
+func budgetPeriod(period string, day time.Time) (time.Time, time.Time) {
+	day = time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.UTC)
+	switch period {
+	case "weekly":
+		start := day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+		return start, start.AddDate(0, 0, 6)
+	case "yearly":
+		start := time.Date(day.Year(), 1, 1, 0, 0, 0, 0, time.UTC)
+		return start, start.AddDate(1, 0, -1)
+	default:
+		start := time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, time.UTC)
+		return start, start.AddDate(0, 1, -1)
+	}
+}
+
+// Category budget harus category expense
+func findBudgetCategory(userID, categoryID uint) (*models.Category, int, string) {
+	category, err := findUserCategory(userID, categoryID)
+	if err != nil {
+		return nil, 404, "Category not found"
+	}
+	if category.Type != "expense" {
+		return nil, 400, "Budgets can only be set on expense categories"
+	}
+	return category, 0, ""
+}
+
+// Jumlah transaksi per category, jenis, currency dan hari dalam rentang tanggal.
+// Join-nya sama dengan balanceRows: transaksi split dihitung per baris split.
+type categoryRow struct {
+	CategoryID uint
+	Kind       string
+	Currency   string
+	Day        time.Time
+	Amount     models.Money
+}
+
+func categoryRows(userID uint, start, end time.Time) ([]categoryRow, error) {
+	var rows []categoryRow
+	err := config.DB.Table("transactions").
+		Select("COALESCE(transaction_splits.category_id, transactions.category_id) AS category_id, "+
+			"COALESCE(split_categories.type, categories.type, '') AS kind, "+
+			"transactions.currency, DATE(transactions.date) AS day, SUM(COALESCE(transaction_splits.amount, transactions.amount)) AS amount").
+		Joins("LEFT JOIN transaction_splits ON transaction_splits.transaction_id = transactions.id").
+		Joins("LEFT JOIN categories ON transactions.category_id = categories.id").
+		Joins("LEFT JOIN categories AS split_categories ON transaction_splits.category_id = split_categories.id").
+		Where("transactions.user_id = ? AND transactions.deleted_at IS NULL AND transactions.transfer_id IS NULL", userID).
+		Where("transactions.date >= ? AND transactions.date < ?", start, end.AddDate(0, 0, 1)).
+		Group("category_id, kind, transactions.currency, day").
+		Scan(&rows).Error
+	return rows, err
+}
+
+// Get All Budgets
+func GetBudgets(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	var budgets []models.Budget
+	query := config.DB.Where("user_id = ?", userID)
+
+	// Optional filter by period
+	if period := c.Query("period"); period != "" {
+		query = query.Where("period = ?", period)
+	}
+
+	if err := query.Preload("Category").Order("id ASC").Find(&budgets).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch budgets"})
+	}
+
+	return c.JSON(fiber.Map{
+		"budgets": budgets,
+	})
+}
+
+// Get Single Budget
+func GetBudget(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	var budget models.Budget
+	if err := config.DB.Where("id = ? AND user_id = ?", c.Params("id"), userID).Preload("Category").First(&budget).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Budget not found"})
+	}
+
+	return c.JSON(fiber.Map{
+		"budget": budget,
+	})
+}
+
+// Create Budget
+func CreateBudget(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	req := new(BudgetRequest)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request"})
+	}
+
+	// Validasi
+	if req.CategoryID == 0 || req.Amount <= 0 {
+		return c.Status(400).JSON(fiber.Map{"error": "Category and a positive amount are required"})
+	}
+	if req.Period == "" {
+		req.Period = "monthly"
+	}
+	if !isValidBudgetPeriod(req.Period) {
+		return c.Status(400).JSON(fiber.Map{"error": "Period must be 'weekly', 'monthly' or 'yearly'"})
+	}
+
+	category, status, msg := findBudgetCategory(userID, req.CategoryID)
+	if status != 0 {
+		return c.Status(status).JSON(fiber.Map{"error": msg})
+	}
+
+	// Satu budget per category per periode
+	var count int64
+	config.DB.Model(&models.Budget{}).Where("user_id = ? AND category_id = ? AND period = ?", userID, category.ID, req.Period).Count(&count)
+	if count > 0 {
+		return c.Status(409).JSON(fiber.Map{"error": "A budget for this category and period already exists"})
+	}
+
+	currency := userBaseCurrency(userID)
+	if req.Currency != "" {
+		code, ok := utils.NormalizeCurrency(req.Currency)
+		if !ok {
+			return c.Status(400).JSON(fiber.Map{"error": "Currency must be a 3-letter ISO code"})
+		}
+		currency = code
+	}
+
+	budget := models.Budget{
+		UserID:     userID,
+		CategoryID: category.ID,
+		Period:     req.Period,
+		Amount:     req.Amount.Round(currency),
+		Currency:   currency,
+	}
+
+	if err := config.DB.Create(&budget).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to create budget"})
+	}
+
+	config.DB.Preload("Category").First(&budget, budget.ID)
+
+	return c.Status(201).JSON(fiber.Map{
+		"message": "Budget created successfully",
+		"budget":  budget,
+	})
+}
+
+// Update Budget
+func UpdateBudget(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	var budget models.Budget
+	if err := config.DB.Where("id = ? AND user_id = ?", c.Params("id"), userID).First(&budget).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Budget not found"})
+	}
+
+	req := new(BudgetRequest)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request"})
+	}
+
+	// Update fields
+	if req.CategoryID != 0 {
+		category, status, msg := findBudgetCategory(userID, req.CategoryID)
+		if status != 0 {
+			return c.Status(status).JSON(fiber.Map{"error": msg})
+		}
+		budget.CategoryID = category.ID
+	}
+	if req.Period != "" {
+		if !isValidBudgetPeriod(req.Period) {
+			return c.Status(400).JSON(fiber.Map{"error": "Period must be 'weekly', 'monthly' or 'yearly'"})
+		}
+		budget.Period = req.Period
+	}
+	if req.Amount != 0 {
+		if req.Amount < 0 {
+			return c.Status(400).JSON(fiber.Map{"error": "Amount must be positive"})
+		}
+		budget.Amount = req.Amount
+	}
+	if req.Currency != "" {
+		code, ok := utils.NormalizeCurrency(req.Currency)
+		if !ok {
+			return c.Status(400).JSON(fiber.Map{"error": "Currency must be a 3-letter ISO code"})
+		}
+		budget.Currency = code
+	}
+	budget.Amount = budget.Amount.Round(budget.Currency)
+
+	var count int64
+	config.DB.Model(&models.Budget{}).Where("user_id = ? AND category_id = ? AND period = ? AND id <> ?", userID, budget.CategoryID, budget.Period, budget.ID).Count(&count)
+	if count > 0 {
+		return c.Status(409).JSON(fiber.Map{"error": "A budget for this category and period already exists"})
+	}
+
+	if err := config.DB.Omit("Category").Save(&budget).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to update budget"})
+	}
+
+	config.DB.Preload("Category").First(&budget, budget.ID)
+
+	return c.JSON(fiber.Map{
+		"message": "Budget updated successfully",
+		"budget":  budget,
+	})
+}
+
+// Delete Budget
+func DeleteBudget(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	var budget models.Budget
+	if err := config.DB.Where("id = ? AND user_id = ?", c.Params("id"), userID).First(&budget).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Budget not found"})
+	}
+
+	if err := config.DB.Delete(&budget).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to delete budget"})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Budget deleted successfully",
+	})
+}
+
+// Get Budget Progress: spent/remaining/percentage tiap budget untuk periode
+// yang memuat ?date=YYYY-MM-DD (default hari ini)
+func GetBudgetProgress(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	day := todayUTC()
+	if date := c.Query("date"); date != "" {
+		parsed, err := time.Parse("2006-01-02", date)
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "Invalid date format. Use YYYY-MM-DD"})
+		}
+		day = parsed
+	}
+
+	var budgets []models.Budget
+	query := config.DB.Where("user_id = ?", userID)
+	if period := c.Query("period"); period != "" {
+		query = query.Where("period = ?", period)
+	}
+	if err := query.Preload("Category").Order("id ASC").Find(&budgets).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch budgets"})
+	}
+
+	rates, err := loadRateTable(userID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to load exchange rates"})
+	}
+
+	includeDescendants := c.Query("include_descendants") == "true"
+
+	// Rows diambil sekali per jenis periode
+	rowsByPeriod := map[string][]categoryRow{}
+	progress := make([]BudgetProgress, 0, len(budgets))
+	for _, budget := range budgets {
+		start, end := budgetPeriod(budget.Period, day)
+		rows, ok := rowsByPeriod[budget.Period]
+		if !ok {
+			if rows, err = categoryRows(userID, start, end); err != nil {
+				return c.Status(500).JSON(fiber.Map{"error": "Failed to calculate budget progress"})
+			}
+			rowsByPeriod[budget.Period] = rows
+		}
+
+		// include_descendants=true: budget category induk ikut menghitung pengeluaran sub-category
+		included := map[uint]bool{budget.CategoryID: true}
+		if includeDescendants {
+			ids, err := categoryWithDescendants(userID, budget.CategoryID)
+			if err != nil {
+				return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch categories"})
+			}
+			for _, id := range ids {
+				included[id] = true
+			}
+		}
+
+		var spent models.Money
+		for _, row := range rows {
+			if !included[row.CategoryID] || row.Kind != "expense" {
+				continue
+			}
+			converted, err := rates.Convert(row.Amount, row.Currency, budget.Currency, row.Day)
+			if err != nil {
+				return c.Status(422).JSON(fiber.Map{"error": err.Error()})
+			}
+			spent += converted
+		}
+
+		item := BudgetProgress{
+			BudgetID:   budget.ID,
+			CategoryID: budget.CategoryID,
+			Period:     budget.Period,
+			StartDate:  start.Format("2006-01-02"),
+			EndDate:    end.Format("2006-01-02"),
+			Currency:   budget.Currency,
+			Amount:     budget.Amount,
+			Spent:      spent,
+			Remaining:  budget.Amount - spent,
+			OverBudget: spent > budget.Amount,
+		}
+		if budget.Category != nil {
+			item.CategoryName = budget.Category.Name
+		}
+		if budget.Amount > 0 {
+			ratio := new(big.Rat).Quo(spent.Rat(), budget.Amount.Rat())
+			percentage, _ := new(big.Rat).Mul(ratio, big.NewRat(100, 1)).Float64()
+			item.Percentage = math.Round(percentage*100) / 100
+		}
+		progress = append(progress, item)
+	}
+
+	return c.JSON(fiber.Map{
+		"date":     day.Format("2006-01-02"),
+		"progress": progress,
+	})
+}
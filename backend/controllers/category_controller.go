@@ -3,43 +3,342 @@ package controllers
 import (
 	"finance-tracker-backend/config"
 	"finance-tracker-backend/models"
+	"strconv"
 
 	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
 )
 
 type CategoryRequest struct {
-	Name string `json:"name"`
-	Type string `json:"type"` // "income" atau "expense"
+	Name              string `json:"name"`
+	Type              string `json:"type"`                // "income" atau "expense", sub-category ikut type parent
+	ParentID          *uint  `json:"parent_id"`           // 0 = jadikan category utama
+	ConfirmTypeChange bool   `json:"confirm_type_change"` // wajib true kalau ganti type category yang sudah punya transaksi
 }
 
-// Get All Categories
+type MergeCategoryRequest struct {
+	TargetID uint `json:"target_id"` // category tujuan, category di URL dihapus setelah digabung
+}
+
+// Jumlah data yang masih memakai category
+type categoryUsage struct {
+	Transactions int64 `json:"transactions"`
+	Splits       int64 `json:"splits"`
+	Recurring    int64 `json:"recurring"`
+	Budgets      int64 `json:"budgets"`
+	Envelopes    int64 `json:"envelopes"`
+}
+
+func (u categoryUsage) inUse() bool {
+	return u.Transactions+u.Splits+u.Recurring+u.Budgets+u.Envelopes > 0
+}
+
+// Category milik user (category default sistem dengan user_id 0 tidak ikut)
+func findUserCategory(userID uint, id interface{}) (*models.Category, error) {
+	var category models.Category
+	if err := config.DB.Where("id = ? AND user_id = ?", id, userID).First(&category).Error; err != nil {
+		return nil, err
+	}
+	return &category, nil
+}
+
+// Map parent -> children dari semua category user, untuk traversal tree
+func categoryChildren(userID uint) (map[uint][]uint, error) {
+	var categories []models.Category
+	if err := config.DB.Select("id", "parent_id").Where("user_id = ?", userID).Find(&categories).Error; err != nil {
+		return nil, err
+	}
+	children := make(map[uint][]uint, len(categories))
+	for _, category := range categories {
+		if category.ParentID != nil {
+			children[*category.ParentID] = append(children[*category.ParentID], category.ID)
+		}
+	}
+	return children, nil
+}
+
+// ID category beserta semua turunannya (anak, cucu, ...)
+func categoryWithDescendants(userID, id uint) ([]uint, error) {
+	children, err := categoryChildren(userID)
+	if err != nil {
+		return nil, err
+	}
+	ids := []uint{id}
+	seen := map[uint]bool{id: true}
+	for i := 0; i < len(ids); i++ {
+		for _, child := range children[ids[i]] {
+			if !seen[child] {
+				seen[child] = true
+				ids = append(ids, child)
+			}
+		}
+	}
+	return ids, nil
+}
+
+// Category yang difilter dari query category_id; dengan include_descendants=true
+// semua sub-category ikut. nil kalau tidak ada filter.
+func categoryFilterIDs(c *fiber.Ctx) ([]uint, int, string) {
+	value := c.Query("category_id")
+	if value == "" {
+		return nil, 0, ""
+	}
+	id, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return nil, 400, "Invalid category_id"
+	}
+	if c.Query("include_descendants") != "true" {
+		return []uint{uint(id)}, 0, ""
+	}
+	userID := c.Locals("userID").(uint)
+	ids, err := categoryWithDescendants(userID, uint(id))
+	if err != nil {
+		return nil, 500, "Failed to fetch categories"
+	}
+	return ids, 0, ""
+}
+
+// Susun list flat jadi tree (root = category tanpa parent atau parent-nya tidak ikut di list)
+func buildCategoryTree(categories []models.Category) []models.Category {
+	index := make(map[uint]int, len(categories))
+	for i, category := range categories {
+		index[category.ID] = i
+	}
+	children := make(map[uint][]uint, len(categories))
+	var roots []uint
+	for _, category := range categories {
+		if category.ParentID != nil {
+			if _, ok := index[*category.ParentID]; ok {
+				children[*category.ParentID] = append(children[*category.ParentID], category.ID)
+				continue
+			}
+		}
+		roots = append(roots, category.ID)
+	}
+
+	var build func(id uint) models.Category
+	build = func(id uint) models.Category {
+		node := categories[index[id]]
+		node.Children = []models.Category{}
+		for _, child := range children[id] {
+			node.Children = append(node.Children, build(child))
+		}
+		return node
+	}
+
+	tree := make([]models.Category, 0, len(roots))
+	for _, id := range roots {
+		tree = append(tree, build(id))
+	}
+	return tree
+}
+
+// Validasi parent baru: harus ada dan bukan category itu sendiri atau turunannya (mencegah cycle)
+func findParentCategory(userID, categoryID, parentID uint) (*models.Category, int, string) {
+	parent, err := findUserCategory(userID, parentID)
+	if err != nil {
+		return nil, 404, "Parent category not found"
+	}
+	if categoryID != 0 {
+		descendants, err := categoryWithDescendants(userID, categoryID)
+		if err != nil {
+			return nil, 500, "Failed to fetch categories"
+		}
+		for _, id := range descendants {
+			if id == parent.ID {
+				return nil, 400, "A category cannot be moved under itself or its descendants"
+			}
+		}
+	}
+	return parent, 0, ""
+}
+
+// Hitung pemakaian category-category ini (transaksi yang sudah di-soft-delete tidak dihitung)
+func countCategoryUsage(db *gorm.DB, ids []uint) (categoryUsage, error) {
+	var usage categoryUsage
+	counts := []struct {
+		model interface{}
+		query string
+		dest  *int64
+	}{
+		{&models.Transaction{}, "category_id IN ?", &usage.Transactions},
+		{&models.TransactionSplit{}, "category_id IN ?", &usage.Splits},
+		{&models.RecurringTransaction{}, "category_id IN ?", &usage.Recurring},
+		{&models.Budget{}, "category_id IN ?", &usage.Budgets},
+		{&models.Envelope{}, "category_id IN ?", &usage.Envelopes},
+	}
+	for _, count := range counts {
+		if err := db.Model(count.model).Where(count.query, ids).Count(count.dest).Error; err != nil {
+			return usage, err
+		}
+	}
+	return usage, nil
+}
+
+// Pindahkan semua data yang memakai source ke target di dalam DB transaction.
+// Budget dengan periode yang sudah ada di target dibuang, assignment amplop
+// di bulan yang sama dijumlahkan ke amplop target.
+func moveCategoryReferences(tx *gorm.DB, userID, sourceID, targetID uint) error {
+	if err := tx.Unscoped().Model(&models.Transaction{}).Where("category_id = ? AND user_id = ?", sourceID, userID).Update("category_id", targetID).Error; err != nil {
+		return err
+	}
+	if err := tx.Model(&models.TransactionSplit{}).Where("category_id = ?", sourceID).Update("category_id", targetID).Error; err != nil {
+		return err
+	}
+	if err := tx.Unscoped().Model(&models.RecurringTransaction{}).Where("category_id = ? AND user_id = ?", sourceID, userID).Update("category_id", targetID).Error; err != nil {
+		return err
+	}
+	if err := tx.Model(&models.Goal{}).Where("category_id = ? AND user_id = ?", sourceID, userID).Update("category_id", targetID).Error; err != nil {
+		return err
+	}
+	if err := tx.Model(&models.Payee{}).Where("default_category_id = ? AND user_id = ?", sourceID, userID).Update("default_category_id", targetID).Error; err != nil {
+		return err
+	}
+
+	// Budget
+	var periods []string
+	if err := tx.Model(&models.Budget{}).Where("category_id = ? AND user_id = ?", targetID, userID).Pluck("period", &periods).Error; err != nil {
+		return err
+	}
+	if len(periods) > 0 {
+		if err := tx.Where("category_id = ? AND user_id = ? AND period IN ?", sourceID, userID, periods).Delete(&models.Budget{}).Error; err != nil {
+			return err
+		}
+	}
+	if err := tx.Model(&models.Budget{}).Where("category_id = ? AND user_id = ?", sourceID, userID).Update("category_id", targetID).Error; err != nil {
+		return err
+	}
+
+	// Envelope
+	var source models.Envelope
+	if err := tx.Where("category_id = ? AND user_id = ?", sourceID, userID).Limit(1).Find(&source).Error; err != nil {
+		return err
+	}
+	if source.ID == 0 {
+		return nil
+	}
+	var target models.Envelope
+	if err := tx.Where("category_id = ? AND user_id = ?", targetID, userID).Limit(1).Find(&target).Error; err != nil {
+		return err
+	}
+	if target.ID == 0 {
+		return tx.Model(&source).Update("category_id", targetID).Error
+	}
+
+	var assignments []models.EnvelopeAssignment
+	if err := tx.Where("envelope_id = ?", source.ID).Find(&assignments).Error; err != nil {
+		return err
+	}
+	for _, assignment := range assignments {
+		var existing models.EnvelopeAssignment
+		if err := tx.Where("envelope_id = ? AND month = ?", target.ID, assignment.Month).Limit(1).Find(&existing).Error; err != nil {
+			return err
+		}
+		if existing.ID == 0 {
+			if err := tx.Model(&assignment).Update("envelope_id", target.ID).Error; err != nil {
+				return err
+			}
+			continue
+		}
+		if err := tx.Model(&existing).Update("amount", existing.Amount+assignment.Amount).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&assignment).Error; err != nil {
+			return err
+		}
+	}
+	return tx.Delete(&source).Error
+}
+
+// Hapus semua data yang memakai category (cascade). Transaksi split ditolak sebelumnya,
+// jadi di sini hanya transaksi biasa. Mengembalikan attachment yang file-nya perlu dihapus.
+func deleteCategoryReferences(tx *gorm.DB, userID, categoryID uint) ([]models.Attachment, error) {
+	var transactionIDs []uint
+	if err := tx.Model(&models.Transaction{}).Where("category_id = ? AND user_id = ?", categoryID, userID).Pluck("id", &transactionIDs).Error; err != nil {
+		return nil, err
+	}
+	attachments, err := deleteTransactions(tx, transactionIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Where("category_id = ? AND user_id = ?", categoryID, userID).Delete(&models.RecurringTransaction{}).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Where("category_id = ? AND user_id = ?", categoryID, userID).Delete(&models.Budget{}).Error; err != nil {
+		return nil, err
+	}
+	var envelopeIDs []uint
+	if err := tx.Model(&models.Envelope{}).Where("category_id = ? AND user_id = ?", categoryID, userID).Pluck("id", &envelopeIDs).Error; err != nil {
+		return nil, err
+	}
+	if len(envelopeIDs) > 0 {
+		if err := tx.Where("envelope_id IN ?", envelopeIDs).Delete(&models.EnvelopeAssignment{}).Error; err != nil {
+			return nil, err
+		}
+		if err := tx.Where("id IN ?", envelopeIDs).Delete(&models.Envelope{}).Error; err != nil {
+			return nil, err
+		}
+	}
+	if err := tx.Model(&models.Goal{}).Where("category_id = ? AND user_id = ?", categoryID, userID).Update("category_id", nil).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Model(&models.Payee{}).Where("default_category_id = ? AND user_id = ?", categoryID, userID).Update("default_category_id", nil).Error; err != nil {
+		return nil, err
+	}
+	return attachments, nil
+}
+
+// Get All Categories (tree, atau list biasa dengan ?flat=true)
 func GetCategories(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
 	var categories []models.Category
-	
+
 	// Optional filter by type (income/expense)
 	typeFilter := c.Query("type")
-	
-	query := config.DB
+
+	query := config.DB.Where("user_id = ?", userID)
 	if typeFilter != "" {
 		query = query.Where("type = ?", typeFilter)
 	}
-	
-	if err := query.Find(&categories).Error; err != nil {
+
+	if err := query.Order("name ASC").Find(&categories).Error; err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch categories"})
 	}
 
+	if c.Query("flat") == "true" {
+		return c.JSON(fiber.Map{
+			"categories": categories,
+		})
+	}
+
 	return c.JSON(fiber.Map{
-		"categories": categories,
+		"categories": buildCategoryTree(categories),
 	})
 }
 
 // Create Category
 func CreateCategory(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
 	req := new(CategoryRequest)
 	if err := c.BodyParser(req); err != nil {
 		return c.Status(400).JSON(fiber.Map{"error": "Invalid request"})
 	}
 
+	// Sub-category ikut type parent
+	var parentID *uint
+	if req.ParentID != nil && *req.ParentID != 0 {
+		parent, status, msg := findParentCategory(userID, 0, *req.ParentID)
+		if status != 0 {
+			return c.Status(status).JSON(fiber.Map{"error": msg})
+		}
+		if req.Type != "" && req.Type != parent.Type {
+			return c.Status(400).JSON(fiber.Map{"error": "Sub-category type must match its parent"})
+		}
+		req.Type = parent.Type
+		parentID = &parent.ID
+	}
+
 	// Validasi
 	if req.Name == "" || req.Type == "" {
 		return c.Status(400).JSON(fiber.Map{"error": "Name and type are required"})
@@ -50,8 +349,10 @@ func CreateCategory(c *fiber.Ctx) error {
 	}
 
 	category := models.Category{
-		Name: req.Name,
-		Type: req.Type,
+		UserID:   userID,
+		Name:     req.Name,
+		Type:     req.Type,
+		ParentID: parentID,
 	}
 
 	if err := config.DB.Create(&category).Error; err != nil {
@@ -66,10 +367,11 @@ func CreateCategory(c *fiber.Ctx) error {
 
 // Update Category
 func UpdateCategory(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
 	id := c.Params("id")
-	
-	var category models.Category
-	if err := config.DB.First(&category, id).Error; err != nil {
+
+	category, err := findUserCategory(userID, id)
+	if err != nil {
 		return c.Status(404).JSON(fiber.Map{"error": "Category not found"})
 	}
 
@@ -79,17 +381,64 @@ func UpdateCategory(c *fiber.Ctx) error {
 	}
 
 	// Update fields
+	originalType := category.Type
 	if req.Name != "" {
 		category.Name = req.Name
 	}
+	if req.ParentID != nil {
+		if *req.ParentID == 0 {
+			category.ParentID = nil
+		} else {
+			parent, status, msg := findParentCategory(userID, category.ID, *req.ParentID)
+			if status != 0 {
+				return c.Status(status).JSON(fiber.Map{"error": msg})
+			}
+			category.ParentID = &parent.ID
+			category.Type = parent.Type
+		}
+	}
 	if req.Type != "" {
 		if req.Type != "income" && req.Type != "expense" {
 			return c.Status(400).JSON(fiber.Map{"error": "Type must be 'income' or 'expense'"})
 		}
+		if category.ParentID != nil && req.Type != category.Type {
+			return c.Status(400).JSON(fiber.Map{"error": "Sub-category type must match its parent"})
+		}
 		category.Type = req.Type
 	}
 
-	if err := config.DB.Save(&category).Error; err != nil {
+	// Type diturunkan ke semua sub-category dalam DB transaction yang sama
+	descendants, err := categoryWithDescendants(userID, category.ID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to update category"})
+	}
+
+	// Ganti type membalik transaksi lama (income <-> expense), jadi harus dikonfirmasi
+	if category.Type != originalType {
+		usage, err := countCategoryUsage(config.DB, descendants)
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": "Failed to update category"})
+		}
+		if category.Type == "income" && usage.Budgets+usage.Envelopes > 0 {
+			return c.Status(400).JSON(fiber.Map{"error": "Budgets and envelopes can only use expense categories, remove them first"})
+		}
+		if usage.Transactions+usage.Splits+usage.Recurring > 0 && !req.ConfirmTypeChange {
+			return c.Status(409).JSON(fiber.Map{
+				"error": "Changing the type turns existing " + originalType + " transactions into " + category.Type + ", set confirm_type_change to proceed",
+				"usage": usage,
+			})
+		}
+	}
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Transactions", "Children").Save(category).Error; err != nil {
+			return err
+		}
+		if len(descendants) > 1 {
+			return tx.Model(&models.Category{}).Where("id IN ?", descendants[1:]).Update("type", category.Type).Error
+		}
+		return nil
+	})
+	if err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": "Failed to update category"})
 	}
 
@@ -99,20 +448,138 @@ func UpdateCategory(c *fiber.Ctx) error {
 	})
 }
 
-// Delete Category
+// Delete Category (sub-category pindah ke parent category yang dihapus).
+// Kalau category masih dipakai, wajib pilih ?replacement_id= (data dipindah ke category itu)
+// atau ?cascade=true (transaksi, recurring, budget dan amplop di category ini ikut dihapus).
 func DeleteCategory(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
 	id := c.Params("id")
-	
-	var category models.Category
-	if err := config.DB.First(&category, id).Error; err != nil {
+
+	category, err := findUserCategory(userID, id)
+	if err != nil {
 		return c.Status(404).JSON(fiber.Map{"error": "Category not found"})
 	}
 
-	if err := config.DB.Delete(&category).Error; err != nil {
+	var replacement *models.Category
+	if value := c.Query("replacement_id"); value != "" {
+		replacementID, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "Invalid replacement_id"})
+		}
+		if uint(replacementID) == category.ID {
+			return c.Status(400).JSON(fiber.Map{"error": "A different replacement category is required"})
+		}
+		if replacement, err = findUserCategory(userID, replacementID); err != nil {
+			return c.Status(404).JSON(fiber.Map{"error": "Replacement category not found"})
+		}
+		if replacement.Type != category.Type {
+			return c.Status(400).JSON(fiber.Map{"error": "Replacement category must have the same type"})
+		}
+	}
+	cascade := c.Query("cascade") == "true"
+	if replacement != nil && cascade {
+		return c.Status(400).JSON(fiber.Map{"error": "Use either replacement_id or cascade, not both"})
+	}
+
+	usage, err := countCategoryUsage(config.DB, []uint{category.ID})
+	if err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": "Failed to delete category"})
 	}
+	if usage.inUse() && replacement == nil && !cascade {
+		return c.Status(409).JSON(fiber.Map{
+			"error": "Category is in use, provide replacement_id or cascade=true",
+			"usage": usage,
+		})
+	}
+	// Baris split tidak bisa dihapus sendiri tanpa merusak total transaksi
+	if cascade && usage.Splits > 0 {
+		return c.Status(409).JSON(fiber.Map{
+			"error": "Category is used in split transactions, provide replacement_id instead",
+			"usage": usage,
+		})
+	}
+
+	var attachments []models.Attachment
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if replacement != nil {
+			if err := moveCategoryReferences(tx, userID, category.ID, replacement.ID); err != nil {
+				return err
+			}
+		} else {
+			var err error
+			if attachments, err = deleteCategoryReferences(tx, userID, category.ID); err != nil {
+				return err
+			}
+		}
+		if err := tx.Model(&models.Category{}).Where("parent_id = ?", category.ID).Update("parent_id", category.ParentID).Error; err != nil {
+			return err
+		}
+		return tx.Delete(category).Error
+	})
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to delete category"})
+	}
+
+	// File attachment baru dihapus setelah commit
+	removeAttachmentFiles(attachments)
 
 	return c.JSON(fiber.Map{
 		"message": "Category deleted successfully",
 	})
-}
\ No newline at end of file
+}
+
+// Merge Category: semua transaksi dan data lain dipindah ke target, sub-category
+// pindah ke bawah target, lalu category sumber dihapus
+func MergeCategory(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	source, err := findUserCategory(userID, c.Params("id"))
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Category not found"})
+	}
+
+	req := new(MergeCategoryRequest)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request"})
+	}
+	if req.TargetID == 0 || req.TargetID == source.ID {
+		return c.Status(400).JSON(fiber.Map{"error": "A different target category is required"})
+	}
+
+	target, err := findUserCategory(userID, req.TargetID)
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Target category not found"})
+	}
+	if target.Type != source.Type {
+		return c.Status(400).JSON(fiber.Map{"error": "Categories must have the same type to merge"})
+	}
+
+	// Target tidak boleh turunan source, supaya sub-category tidak jadi cycle
+	descendants, err := categoryWithDescendants(userID, source.ID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to merge categories"})
+	}
+	for _, id := range descendants {
+		if id == target.ID {
+			return c.Status(400).JSON(fiber.Map{"error": "A category cannot be merged into its own sub-category"})
+		}
+	}
+
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := moveCategoryReferences(tx, userID, source.ID, target.ID); err != nil {
+			return err
+		}
+		if err := tx.Model(&models.Category{}).Where("parent_id = ?", source.ID).Update("parent_id", target.ID).Error; err != nil {
+			return err
+		}
+		return tx.Delete(source).Error
+	})
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to merge categories"})
+	}
+
+	return c.JSON(fiber.Map{
+		"message":  "Categories merged successfully",
+		"category": target,
+	})
+}
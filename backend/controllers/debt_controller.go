@@ -0,0 +1,532 @@
+package controllers
+
+import (
+	"errors"
+	"finance-tracker-backend/config"
+	"finance-tracker-backend/models"
+	"finance-tracker-backend/utils"
+	"math/big"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+type DebtRequest struct {
+	Name         string       `json:"name"`
+	Kind         string       `json:"kind"` // "loan" (default), "installment", "paylater" atau "credit_card"
+	Lender       string       `json:"lender"`
+	Principal    models.Money `json:"principal"`
+	Currency     string       `json:"currency"`      // kosong = base currency user
+	InterestRate *models.Rate `json:"interest_rate"` // persen, misalnya 1.75
+	RatePeriod   string       `json:"rate_period"`   // "yearly" (default) atau "monthly"
+	Method       string       `json:"method"`        // "flat" (default), "effective" atau "annuity"
+	TermMonths   int          `json:"term_months"`
+	StartDate    string       `json:"start_date"` // jatuh tempo cicilan pertama
+	AccountID    *uint        `json:"account_id"` // 0 = lepas account
+}
+
+type DebtPaymentRequest struct {
+	TransactionID uint          `json:"transaction_id"`
+	Amount        *models.Money `json:"amount"` // wajib kalau tanpa transaksi, default = amount transaksi
+	Date          string        `json:"date"`   // wajib kalau tanpa transaksi
+	Note          string        `json:"note"`
+}
+
+type DueInstallment struct {
+	utils.Installment
+	Due     models.Money `json:"due"` // sisa yang belum dibayar dari cicilan ini
+	Overdue bool         `json:"overdue"`
+}
+
+type DebtReport struct {
+	DebtID             uint             `json:"debt_id"`
+	Name               string           `json:"name"`
+	Currency           string           `json:"currency"`
+	Principal          models.Money     `json:"principal"`
+	TotalInterest      models.Money     `json:"total_interest"` // bunga sesuai jadwal sampai lunas
+	TotalPaid          models.Money     `json:"total_paid"`
+	PrincipalPaid      models.Money     `json:"principal_paid"`
+	InterestPaid       models.Money     `json:"interest_paid"`
+	RemainingPrincipal models.Money     `json:"remaining_principal"`
+	RemainingTotal     models.Money     `json:"remaining_total"` // pokok + bunga yang belum dibayar
+	PaidInstallments   int              `json:"paid_installments"`
+	OverdueAmount      models.Money     `json:"overdue_amount"`
+	NextDue            *DueInstallment  `json:"next_due"`
+	Upcoming           []DueInstallment `json:"upcoming"`
+	PaidOff            bool             `json:"paid_off"`
+}
+
+// Berapa cicilan ke depan yang ditampilkan di report
+const debtUpcomingInstallments = 3
+
+func isValidDebtKind(kind string) bool {
+	switch kind {
+	case "loan", "installment", "paylater", "credit_card":
+		return true
+	}
+	return false
+}
+
+func isValidDebtMethod(method string) bool {
+	switch method {
+	case "flat", "effective", "annuity":
+		return true
+	}
+	return false
+}
+
+// Bunga per bulan sebagai pecahan (1.75% per bulan -> 0.0175)
+func debtMonthlyRate(debt *models.Debt) *big.Rat {
+	rate := new(big.Rat).Quo(debt.InterestRate.Rat(), big.NewRat(100, 1))
+	if debt.RatePeriod == "yearly" {
+		rate.Quo(rate, big.NewRat(12, 1))
+	}
+	return rate
+}
+
+func debtSchedule(debt *models.Debt) ([]utils.Installment, error) {
+	return utils.AmortizationSchedule(debt.Principal, debtMonthlyRate(debt), debt.TermMonths, debt.Method, debt.StartDate, debt.Currency)
+}
+
+// Report dihitung dengan mengalokasikan total pembayaran ke cicilan secara berurutan,
+// di tiap cicilan bunga dibayar lebih dulu baru pokok
+func debtReport(debt *models.Debt, schedule []utils.Installment, payments []models.DebtPayment, today time.Time) DebtReport {
+	report := DebtReport{
+		DebtID:    debt.ID,
+		Name:      debt.Name,
+		Currency:  debt.Currency,
+		Principal: debt.Principal,
+		Upcoming:  []DueInstallment{},
+	}
+	for _, payment := range payments {
+		report.TotalPaid += payment.Amount
+	}
+
+	left := report.TotalPaid
+	for _, item := range schedule {
+		report.TotalInterest += item.Interest
+
+		interest := min(left, item.Interest)
+		left -= interest
+		principal := min(left, item.Principal)
+		left -= principal
+
+		report.InterestPaid += interest
+		report.PrincipalPaid += principal
+
+		due := item.Payment - interest - principal
+		if due <= 0 {
+			report.PaidInstallments++
+			continue
+		}
+		dueDate, _ := time.Parse("2006-01-02", item.DueDate)
+		entry := DueInstallment{Installment: item, Due: due, Overdue: dueDate.Before(today)}
+		if entry.Overdue {
+			report.OverdueAmount += due
+		}
+		if len(report.Upcoming) < debtUpcomingInstallments {
+			report.Upcoming = append(report.Upcoming, entry)
+		}
+	}
+
+	// Kelebihan bayar (pelunasan dipercepat) langsung mengurangi pokok
+	report.PrincipalPaid += min(left, debt.Principal-report.PrincipalPaid)
+
+	report.RemainingPrincipal = debt.Principal - report.PrincipalPaid
+	report.RemainingTotal = debt.Principal + report.TotalInterest - report.PrincipalPaid - report.InterestPaid
+	if report.RemainingPrincipal <= 0 {
+		report.RemainingPrincipal = 0
+		report.RemainingTotal = 0
+		report.Upcoming = []DueInstallment{}
+		report.OverdueAmount = 0
+		report.PaidOff = true
+	}
+	if len(report.Upcoming) > 0 {
+		next := report.Upcoming[0]
+		report.NextDue = &next
+	}
+	return report
+}
+
+// Terapkan field request ke debt lalu validasi jadwalnya
+func applyDebtRequest(userID uint, debt *models.Debt, req *DebtRequest) (int, string) {
+	if req.Name != "" {
+		debt.Name = req.Name
+	}
+	if req.Kind != "" {
+		if !isValidDebtKind(req.Kind) {
+			return 400, "Kind must be 'loan', 'installment', 'paylater' or 'credit_card'"
+		}
+		debt.Kind = req.Kind
+	}
+	if req.Lender != "" {
+		debt.Lender = req.Lender
+	}
+	if req.Principal != 0 {
+		debt.Principal = req.Principal
+	}
+	if req.Currency != "" {
+		code, ok := utils.NormalizeCurrency(req.Currency)
+		if !ok {
+			return 400, "Currency must be a 3-letter ISO code"
+		}
+		debt.Currency = code
+	}
+	if req.InterestRate != nil {
+		debt.InterestRate = *req.InterestRate
+	}
+	if req.RatePeriod != "" {
+		if req.RatePeriod != "yearly" && req.RatePeriod != "monthly" {
+			return 400, "Rate period must be 'yearly' or 'monthly'"
+		}
+		debt.RatePeriod = req.RatePeriod
+	}
+	if req.Method != "" {
+		if !isValidDebtMethod(req.Method) {
+			return 400, "Method must be 'flat', 'effective' or 'annuity'"
+		}
+		debt.Method = req.Method
+	}
+	if req.TermMonths != 0 {
+		debt.TermMonths = req.TermMonths
+	}
+	if req.StartDate != "" {
+		date, err := time.Parse("2006-01-02", req.StartDate)
+		if err != nil {
+			return 400, "Invalid date format. Use YYYY-MM-DD"
+		}
+		debt.StartDate = date
+	}
+	if req.AccountID != nil {
+		if *req.AccountID == 0 {
+			debt.AccountID = nil
+		} else {
+			account, err := findUserAccount(userID, *req.AccountID)
+			if err != nil {
+				return 404, "Account not found"
+			}
+			debt.AccountID = &account.ID
+		}
+	}
+
+	debt.Principal = debt.Principal.Round(debt.Currency)
+	if debt.Principal <= 0 || debt.TermMonths <= 0 || debt.TermMonths > 600 {
+		return 400, "Principal must be positive and term must be 1-600 months"
+	}
+	if debt.InterestRate < 0 {
+		return 400, "Interest rate must not be negative"
+	}
+	return 0, ""
+}
+
+// Get All Debts (beserta ringkasan sisa pokok dan cicilan berikutnya)
+func GetDebts(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	var debts []models.Debt
+	if err := config.DB.Where("user_id = ?", userID).Preload("Payments").Order("start_date ASC").Find(&debts).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch debts"})
+	}
+
+	today := todayUTC()
+	includePaid := c.Query("include_paid") == "true"
+	result := make([]fiber.Map, 0, len(debts))
+	for i := range debts {
+		schedule, err := debtSchedule(&debts[i])
+		if err != nil {
+			continue
+		}
+		report := debtReport(&debts[i], schedule, debts[i].Payments, today)
+		if report.PaidOff && !includePaid {
+			continue
+		}
+		debts[i].Payments = nil
+		result = append(result, fiber.Map{"debt": debts[i], "report": report})
+	}
+
+	return c.JSON(fiber.Map{
+		"debts": result,
+	})
+}
+
+// Get Single Debt (beserta pembayaran)
+func GetDebt(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	var debt models.Debt
+	if err := config.DB.Where("id = ? AND user_id = ?", c.Params("id"), userID).
+		Preload("Account").
+		Preload("Payments", func(db *gorm.DB) *gorm.DB { return db.Order("date ASC") }).
+		First(&debt).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Debt not found"})
+	}
+
+	return c.JSON(fiber.Map{
+		"debt": debt,
+	})
+}
+
+// Create Debt
+func CreateDebt(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	req := new(DebtRequest)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request"})
+	}
+
+	// Validasi
+	if req.Name == "" || req.Principal == 0 || req.TermMonths == 0 || req.StartDate == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "Name, principal, term and start date are required"})
+	}
+
+	debt := models.Debt{
+		UserID:     userID,
+		Kind:       "loan",
+		Currency:   userBaseCurrency(userID),
+		RatePeriod: "yearly",
+		Method:     "flat",
+	}
+	if status, msg := applyDebtRequest(userID, &debt, req); status != 0 {
+		return c.Status(status).JSON(fiber.Map{"error": msg})
+	}
+
+	schedule, err := debtSchedule(&debt)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	if err := config.DB.Create(&debt).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to create debt"})
+	}
+
+	return c.Status(201).JSON(fiber.Map{
+		"message":  "Debt created successfully",
+		"debt":     debt,
+		"schedule": schedule,
+	})
+}
+
+// Update Debt (jadwal dihitung ulang, pembayaran yang sudah ada dialokasikan ulang)
+func UpdateDebt(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	var debt models.Debt
+	if err := config.DB.Where("id = ? AND user_id = ?", c.Params("id"), userID).First(&debt).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Debt not found"})
+	}
+
+	req := new(DebtRequest)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request"})
+	}
+
+	// Currency tidak bisa diubah karena pembayaran sudah tercatat dalam currency debt
+	if req.Currency != "" {
+		if code, ok := utils.NormalizeCurrency(req.Currency); !ok || code != debt.Currency {
+			return c.Status(400).JSON(fiber.Map{"error": "Debt currency cannot be changed"})
+		}
+	}
+	if status, msg := applyDebtRequest(userID, &debt, req); status != 0 {
+		return c.Status(status).JSON(fiber.Map{"error": msg})
+	}
+	if _, err := debtSchedule(&debt); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	if err := config.DB.Omit("Account", "Payments").Save(&debt).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to update debt"})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Debt updated successfully",
+		"debt":    debt,
+	})
+}
+
+// Delete Debt (pembayaran ikut dihapus, transaksinya tetap ada)
+func DeleteDebt(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	var debt models.Debt
+	if err := config.DB.Where("id = ? AND user_id = ?", c.Params("id"), userID).First(&debt).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Debt not found"})
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("debt_id = ?", debt.ID).Delete(&models.DebtPayment{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&debt).Error
+	})
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to delete debt"})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Debt deleted successfully",
+	})
+}
+
+// Get Amortization Schedule
+func GetDebtSchedule(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	var debt models.Debt
+	if err := config.DB.Where("id = ? AND user_id = ?", c.Params("id"), userID).First(&debt).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Debt not found"})
+	}
+
+	schedule, err := debtSchedule(&debt)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{
+		"debt_id":  debt.ID,
+		"method":   debt.Method,
+		"currency": debt.Currency,
+		"schedule": schedule,
+	})
+}
+
+// Get Debt Report: sisa pokok, bunga yang sudah dibayar, dan cicilan yang akan/sudah jatuh tempo
+func GetDebtReport(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	var debt models.Debt
+	if err := config.DB.Where("id = ? AND user_id = ?", c.Params("id"), userID).Preload("Payments").First(&debt).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Debt not found"})
+	}
+
+	schedule, err := debtSchedule(&debt)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{
+		"report": debtReport(&debt, schedule, debt.Payments, todayUTC()),
+	})
+}
+
+// Add Debt Payment: hubungkan transaksi pembayaran cicilan (amount dikonversi ke currency debt)
+// atau catat pembayaran manual dengan amount + date
+func AddDebtPayment(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	var debt models.Debt
+	if err := config.DB.Where("id = ? AND user_id = ?", c.Params("id"), userID).First(&debt).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Debt not found"})
+	}
+
+	req := new(DebtPaymentRequest)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request"})
+	}
+
+	payment := models.DebtPayment{
+		UserID: userID,
+		DebtID: debt.ID,
+		Note:   req.Note,
+	}
+
+	if req.TransactionID != 0 {
+		var transaction models.Transaction
+		if err := config.DB.Where("id = ? AND user_id = ?", req.TransactionID, userID).Preload("Category").First(&transaction).Error; err != nil {
+			return c.Status(404).JSON(fiber.Map{"error": "Transaction not found"})
+		}
+
+		// Pembayaran harus uang keluar: expense atau leg transfer keluar (amount negatif),
+		// dari account debt kalau debt punya account
+		outflow := transaction.Amount < 0
+		if transaction.TransferID == nil {
+			outflow = transaction.Category != nil && transaction.Category.Type == "expense"
+		}
+		if !outflow {
+			return c.Status(400).JSON(fiber.Map{"error": "Transaction must be an expense or an outgoing transfer"})
+		}
+		if debt.AccountID != nil && (transaction.AccountID == nil || *transaction.AccountID != *debt.AccountID) {
+			return c.Status(400).JSON(fiber.Map{"error": "Transaction is not in the debt's account"})
+		}
+
+		var count int64
+		config.DB.Model(&models.DebtPayment{}).Where("transaction_id = ?", transaction.ID).Count(&count)
+		if count > 0 {
+			return c.Status(409).JSON(fiber.Map{"error": "Transaction is already linked to a debt payment"})
+		}
+
+		payment.TransactionID = &transaction.ID
+		payment.Date = transaction.Date
+		if req.Amount != nil {
+			payment.Amount = *req.Amount
+		} else {
+			// Leg transfer keluar bernilai negatif, yang dihitung nilai pembayarannya
+			amount := transaction.Amount
+			if amount < 0 {
+				amount = -amount
+			}
+			rates, err := loadRateTable(userID)
+			if err != nil {
+				return c.Status(500).JSON(fiber.Map{"error": "Failed to load exchange rates"})
+			}
+			converted, err := rates.Convert(amount, transaction.Currency, debt.Currency, transaction.Date)
+			if err != nil {
+				return c.Status(422).JSON(fiber.Map{"error": err.Error()})
+			}
+			payment.Amount = converted
+		}
+	} else {
+		if req.Amount == nil || req.Date == "" {
+			return c.Status(400).JSON(fiber.Map{"error": "Transaction, or amount and date, are required"})
+		}
+		payment.Amount = *req.Amount
+	}
+	if req.Date != "" {
+		date, err := time.Parse("2006-01-02", req.Date)
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "Invalid date format. Use YYYY-MM-DD"})
+		}
+		payment.Date = date
+	}
+	payment.Amount = payment.Amount.Round(debt.Currency)
+	if payment.Amount <= 0 {
+		return c.Status(400).JSON(fiber.Map{"error": "Payment amount must be positive"})
+	}
+
+	if err := config.DB.Create(&payment).Error; err != nil {
+		// Request lain menghubungkan transaksi yang sama di antara pengecekan dan insert
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return c.Status(409).JSON(fiber.Map{"error": "Transaction is already linked to a debt payment"})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to add payment"})
+	}
+
+	var payments []models.DebtPayment
+	config.DB.Where("debt_id = ?", debt.ID).Find(&payments)
+	schedule, _ := debtSchedule(&debt)
+
+	return c.Status(201).JSON(fiber.Map{
+		"message": "Payment added successfully",
+		"payment": payment,
+		"report":  debtReport(&debt, schedule, payments, todayUTC()),
+	})
+}
+
+// Delete Debt Payment (transaksinya tetap ada)
+func DeleteDebtPayment(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	var payment models.DebtPayment
+	if err := config.DB.Where("id = ? AND debt_id = ? AND user_id = ?", c.Params("paymentId"), c.Params("id"), userID).First(&payment).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Payment not found"})
+	}
+
+	if err := config.DB.Delete(&payment).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to delete payment"})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Payment deleted successfully",
+	})
+}
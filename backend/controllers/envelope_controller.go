@@ -0,0 +1,384 @@
+package controllers
+
+import (
+	"finance-tracker-backend/config"
+	"finance-tracker-backend/models"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type EnvelopeRequest struct {
+	CategoryID   uint   `json:"category_id"`
+	RolloverMode string `json:"rollover_mode"` // "full" (default), "positive" atau "none"
+}
+
+type AssignRequest struct {
+	Month  string       `json:"month"` // Format: "2024-01"
+	Amount models.Money `json:"amount"`
+}
+
+type EnvelopeMonth struct {
+	EnvelopeID   uint         `json:"envelope_id"`
+	CategoryID   uint         `json:"category_id"`
+	CategoryName string       `json:"category_name"`
+	RolloverMode string       `json:"rollover_mode"`
+	CarriedOver  models.Money `json:"carried_over"` // sisa (atau overspending) dari bulan sebelumnya
+	Assigned     models.Money `json:"assigned"`
+	Activity     models.Money `json:"activity"` // pengeluaran bulan ini (refund mengurangi)
+	Available    models.Money `json:"available"`
+	Overspent    bool         `json:"overspent"`
+}
+
+type EnvelopeMonthView struct {
+	Month              string          `json:"month"`
+	BaseCurrency       string          `json:"base_currency"`
+	Income             models.Money    `json:"income"`
+	CarriedOver        models.Money    `json:"carried_over"` // ready to assign dari bulan sebelumnya
+	TotalAssigned      models.Money    `json:"total_assigned"`
+	TotalActivity      models.Money    `json:"total_activity"`
+	TotalAvailable     models.Money    `json:"total_available"`
+	UnbudgetedActivity models.Money    `json:"unbudgeted_activity"` // pengeluaran di category tanpa amplop
+	ReadyToAssign      models.Money    `json:"ready_to_assign"`     // uang yang belum di-assign (negatif = over-assigned)
+	Envelopes          []EnvelopeMonth `json:"envelopes"`
+}
+
+func isValidRolloverMode(mode string) bool {
+	switch mode {
+	case "full", "positive", "none":
+		return true
+	}
+	return false
+}
+
+// Parse "2024-01" jadi tanggal 1 bulan tersebut
+func parseMonth(value string) (time.Time, bool) {
+	month, err := time.Parse("2006-01", value)
+	return month, err == nil
+}
+
+func monthOf(day time.Time) time.Time {
+	return time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// Hitung month view dengan menjalankan semua bulan dari transaksi/assignment pertama
+// sampai bulan yang diminta, karena isi amplop dan ready to assign bergantung pada bulan-bulan sebelumnya.
+// Semua nilai dalam base currency user.
+func envelopeMonthView(userID uint, target time.Time) (*EnvelopeMonthView, error) {
+	baseCurrency := userBaseCurrency(userID)
+	rates, err := loadRateTable(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var envelopes []models.Envelope
+	if err := config.DB.Where("user_id = ?", userID).Preload("Category").Order("id ASC").Find(&envelopes).Error; err != nil {
+		return nil, err
+	}
+	envelopeOf := make(map[uint]int, len(envelopes)) // category_id -> index
+	for i, envelope := range envelopes {
+		envelopeOf[envelope.CategoryID] = i
+	}
+
+	var assignments []models.EnvelopeAssignment
+	if err := config.DB.Where("user_id = ? AND month <= ?", userID, target).Find(&assignments).Error; err != nil {
+		return nil, err
+	}
+
+	rows, err := categoryRows(userID, time.Date(1, 1, 1, 0, 0, 0, 0, time.UTC), target.AddDate(0, 1, -1))
+	if err != nil {
+		return nil, err
+	}
+
+	// Kelompokkan per bulan
+	first := target
+	income := map[time.Time]models.Money{}
+	activity := map[time.Time]map[uint]models.Money{} // bulan -> category -> pengeluaran
+	for _, row := range rows {
+		converted, err := rates.Convert(row.Amount, row.Currency, baseCurrency, row.Day)
+		if err != nil {
+			return nil, err
+		}
+		month := monthOf(row.Day)
+		if month.Before(first) {
+			first = month
+		}
+		switch row.Kind {
+		case "income":
+			income[month] += converted
+		case "expense":
+			if activity[month] == nil {
+				activity[month] = map[uint]models.Money{}
+			}
+			activity[month][row.CategoryID] += converted
+		}
+	}
+
+	assigned := map[time.Time]map[uint]models.Money{} // bulan -> envelope -> assigned
+	for _, assignment := range assignments {
+		month := monthOf(assignment.Month)
+		if month.Before(first) {
+			first = month
+		}
+		if assigned[month] == nil {
+			assigned[month] = map[uint]models.Money{}
+		}
+		assigned[month][assignment.EnvelopeID] += assignment.Amount
+	}
+
+	available := make([]models.Money, len(envelopes)) // isi amplop di akhir bulan sebelumnya
+	var readyToAssign models.Money
+	var view *EnvelopeMonthView
+
+	for month := first; !month.After(target); month = month.AddDate(0, 1, 0) {
+		view = &EnvelopeMonthView{
+			Month:        month.Format("2006-01"),
+			BaseCurrency: baseCurrency,
+			Income:       income[month],
+			Envelopes:    make([]EnvelopeMonth, 0, len(envelopes)),
+		}
+
+		// Rollover dari bulan sebelumnya sesuai mode tiap amplop
+		for i, envelope := range envelopes {
+			carried := available[i]
+			switch {
+			case envelope.RolloverMode == "none":
+				readyToAssign += carried
+				carried = 0
+			case envelope.RolloverMode == "positive" && carried < 0:
+				readyToAssign += carried
+				carried = 0
+			}
+			available[i] = carried
+		}
+		view.CarriedOver = readyToAssign
+		readyToAssign += income[month]
+
+		for categoryID, spent := range activity[month] {
+			if _, ok := envelopeOf[categoryID]; !ok {
+				view.UnbudgetedActivity += spent
+			}
+		}
+		readyToAssign -= view.UnbudgetedActivity
+
+		for i, envelope := range envelopes {
+			item := EnvelopeMonth{
+				EnvelopeID:   envelope.ID,
+				CategoryID:   envelope.CategoryID,
+				RolloverMode: envelope.RolloverMode,
+				CarriedOver:  available[i],
+				Assigned:     assigned[month][envelope.ID],
+				Activity:     activity[month][envelope.CategoryID],
+			}
+			if envelope.Category != nil {
+				item.CategoryName = envelope.Category.Name
+			}
+			item.Available = item.CarriedOver + item.Assigned - item.Activity
+			item.Overspent = item.Available < 0
+			available[i] = item.Available
+
+			readyToAssign -= item.Assigned
+			view.TotalAssigned += item.Assigned
+			view.TotalActivity += item.Activity
+			view.TotalAvailable += item.Available
+			view.Envelopes = append(view.Envelopes, item)
+		}
+		view.ReadyToAssign = readyToAssign
+	}
+
+	return view, nil
+}
+
+// Get All Envelopes
+func GetEnvelopes(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	var envelopes []models.Envelope
+	if err := config.DB.Where("user_id = ?", userID).Preload("Category").Order("id ASC").Find(&envelopes).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch envelopes"})
+	}
+
+	return c.JSON(fiber.Map{
+		"envelopes": envelopes,
+	})
+}
+
+// Create Envelope
+func CreateEnvelope(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	req := new(EnvelopeRequest)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request"})
+	}
+
+	// Validasi
+	if req.CategoryID == 0 {
+		return c.Status(400).JSON(fiber.Map{"error": "Category is required"})
+	}
+	if req.RolloverMode == "" {
+		req.RolloverMode = "full"
+	}
+	if !isValidRolloverMode(req.RolloverMode) {
+		return c.Status(400).JSON(fiber.Map{"error": "Rollover mode must be 'full', 'positive' or 'none'"})
+	}
+
+	category, status, msg := findBudgetCategory(userID, req.CategoryID)
+	if status != 0 {
+		return c.Status(status).JSON(fiber.Map{"error": msg})
+	}
+
+	var count int64
+	config.DB.Model(&models.Envelope{}).Where("user_id = ? AND category_id = ?", userID, category.ID).Count(&count)
+	if count > 0 {
+		return c.Status(409).JSON(fiber.Map{"error": "An envelope for this category already exists"})
+	}
+
+	envelope := models.Envelope{
+		UserID:       userID,
+		CategoryID:   category.ID,
+		RolloverMode: req.RolloverMode,
+	}
+
+	if err := config.DB.Create(&envelope).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to create envelope"})
+	}
+	envelope.Category = category
+
+	return c.Status(201).JSON(fiber.Map{
+		"message":  "Envelope created successfully",
+		"envelope": envelope,
+	})
+}
+
+// Update Envelope (rollover mode)
+func UpdateEnvelope(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	var envelope models.Envelope
+	if err := config.DB.Where("id = ? AND user_id = ?", c.Params("id"), userID).First(&envelope).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Envelope not found"})
+	}
+
+	req := new(EnvelopeRequest)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request"})
+	}
+
+	if req.RolloverMode != "" {
+		if !isValidRolloverMode(req.RolloverMode) {
+			return c.Status(400).JSON(fiber.Map{"error": "Rollover mode must be 'full', 'positive' or 'none'"})
+		}
+		envelope.RolloverMode = req.RolloverMode
+	}
+
+	if err := config.DB.Save(&envelope).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to update envelope"})
+	}
+
+	config.DB.Preload("Category").First(&envelope, envelope.ID)
+
+	return c.JSON(fiber.Map{
+		"message":  "Envelope updated successfully",
+		"envelope": envelope,
+	})
+}
+
+// Delete Envelope (assignment-nya ikut dihapus, uangnya kembali ke ready to assign)
+func DeleteEnvelope(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	var envelope models.Envelope
+	if err := config.DB.Where("id = ? AND user_id = ?", c.Params("id"), userID).First(&envelope).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Envelope not found"})
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("envelope_id = ?", envelope.ID).Delete(&models.EnvelopeAssignment{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&envelope).Error
+	})
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to delete envelope"})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Envelope deleted successfully",
+	})
+}
+
+// Assign uang ke amplop untuk satu bulan (menimpa assignment sebelumnya, 0 = hapus)
+func AssignEnvelope(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	var envelope models.Envelope
+	if err := config.DB.Where("id = ? AND user_id = ?", c.Params("id"), userID).First(&envelope).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Envelope not found"})
+	}
+
+	req := new(AssignRequest)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request"})
+	}
+
+	month, ok := parseMonth(req.Month)
+	if !ok {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid month format. Use YYYY-MM"})
+	}
+	amount := req.Amount.Round(userBaseCurrency(userID))
+
+	if amount == 0 {
+		if err := config.DB.Where("envelope_id = ? AND month = ?", envelope.ID, month).Delete(&models.EnvelopeAssignment{}).Error; err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": "Failed to assign envelope"})
+		}
+	} else {
+		assignment := models.EnvelopeAssignment{
+			UserID:     userID,
+			EnvelopeID: envelope.ID,
+			Month:      month,
+			Amount:     amount,
+		}
+		err := config.DB.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "envelope_id"}, {Name: "month"}},
+			DoUpdates: clause.AssignmentColumns([]string{"amount", "updated_at"}),
+		}).Create(&assignment).Error
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": "Failed to assign envelope"})
+		}
+	}
+
+	// Kembalikan month view terbaru supaya client langsung dapat ready to assign yang baru
+	view, err := envelopeMonthView(userID, month)
+	if err != nil {
+		return c.Status(422).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Envelope assigned successfully",
+		"month":   view,
+	})
+}
+
+// Get Envelope Month View (?month=YYYY-MM, default bulan ini)
+func GetEnvelopeMonth(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	month := monthOf(todayUTC())
+	if value := c.Query("month"); value != "" {
+		parsed, ok := parseMonth(value)
+		if !ok {
+			return c.Status(400).JSON(fiber.Map{"error": "Invalid month format. Use YYYY-MM"})
+		}
+		month = parsed
+	}
+
+	view, err := envelopeMonthView(userID, month)
+	if err != nil {
+		return c.Status(422).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(view)
+}
@@ -0,0 +1,254 @@
+package controllers
+
+import (
+	"finance-tracker-backend/config"
+	"finance-tracker-backend/models"
+	"finance-tracker-backend/utils"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ExchangeRateRequest struct {
+	FromCurrency string      `json:"from_currency"`
+	ToCurrency   string      `json:"to_currency"`
+	Rate         models.Rate `json:"rate"`
+	Date         string      `json:"date"` // Format: "2024-01-15"
+}
+
+type BaseCurrencyRequest struct {
+	BaseCurrency string `json:"base_currency"`
+}
+
+// Batas ukuran file rate yang di-upload (file historis ECB sekitar 1-2 MB)
+const maxRatesFileSize = 10 << 20
+
+// Table rate global (user_id 0) di-cache karena dipakai hampir tiap request yang
+// mengonversi currency dan bisa berisi seluruh histori ECB. Dikosongkan oleh
+// UpsertExchangeRates setiap kali ada rate global yang ditulis.
+var (
+	globalRatesMu sync.Mutex
+	globalRates   *utils.RateTable
+)
+
+func globalRateTable() (*utils.RateTable, error) {
+	globalRatesMu.Lock()
+	defer globalRatesMu.Unlock()
+
+	if globalRates == nil {
+		var rates []models.ExchangeRate
+		if err := config.DB.Where("user_id = ?", 0).Find(&rates).Error; err != nil {
+			return nil, err
+		}
+		globalRates = utils.NewRateTable(rates)
+	}
+	return globalRates, nil
+}
+
+func forgetGlobalRates() {
+	globalRatesMu.Lock()
+	globalRates = nil
+	globalRatesMu.Unlock()
+}
+
+// Rate global (dari cache) + milik user. Rate user menimpa rate global di tanggal yang sama.
+func loadRateTable(userID uint) (*utils.RateTable, error) {
+	global, err := globalRateTable()
+	if err != nil {
+		return nil, err
+	}
+	var own []models.ExchangeRate
+	if err := config.DB.Where("user_id = ?", userID).Find(&own).Error; err != nil {
+		return nil, err
+	}
+	return global.Overlay(own), nil
+}
+
+// Base currency user (default IDR)
+func userBaseCurrency(userID uint) string {
+	var user models.User
+	if err := config.DB.Select("base_currency").First(&user, userID).Error; err != nil || user.BaseCurrency == "" {
+		return "IDR"
+	}
+	return user.BaseCurrency
+}
+
+// UpsertExchangeRates menyimpan rate (insert atau update rate di pair + tanggal yang sama)
+func UpsertExchangeRates(db *gorm.DB, rates []models.ExchangeRate) error {
+	if len(rates) == 0 {
+		return nil
+	}
+	err := db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "from_currency"}, {Name: "to_currency"}, {Name: "date"}},
+		DoUpdates: clause.AssignmentColumns([]string{"rate", "source", "updated_at"}),
+	}).CreateInBatches(&rates, 500).Error
+
+	for _, rate := range rates {
+		if rate.UserID == 0 {
+			forgetGlobalRates()
+			break
+		}
+	}
+	return err
+}
+
+// Get Exchange Rates (global + milik user)
+func GetExchangeRates(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	var rates []models.ExchangeRate
+	query := config.DB.Where("user_id IN ?", []uint{0, userID})
+
+	// Optional filter by currency pair & tanggal
+	if from, ok := utils.NormalizeCurrency(c.Query("from")); ok {
+		query = query.Where("from_currency = ?", from)
+	}
+	if to, ok := utils.NormalizeCurrency(c.Query("to")); ok {
+		query = query.Where("to_currency = ?", to)
+	}
+	if startDate := c.Query("start_date"); startDate != "" {
+		query = query.Where("date >= ?", startDate)
+	}
+	if endDate := c.Query("end_date"); endDate != "" {
+		query = query.Where("date <= ?", endDate)
+	}
+
+	if err := query.Order("date DESC").Limit(1000).Find(&rates).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch exchange rates"})
+	}
+
+	return c.JSON(fiber.Map{
+		"exchange_rates": rates,
+	})
+}
+
+// Create Exchange Rate (input manual)
+func CreateExchangeRate(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	req := new(ExchangeRateRequest)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request"})
+	}
+
+	from, okFrom := utils.NormalizeCurrency(req.FromCurrency)
+	to, okTo := utils.NormalizeCurrency(req.ToCurrency)
+	if !okFrom || !okTo || from == to {
+		return c.Status(400).JSON(fiber.Map{"error": "Two different 3-letter currency codes are required"})
+	}
+	if req.Rate <= 0 || req.Date == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "Positive rate and date are required"})
+	}
+
+	date, err := time.Parse("2006-01-02", req.Date)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid date format. Use YYYY-MM-DD"})
+	}
+
+	rate := models.ExchangeRate{
+		UserID:       userID,
+		FromCurrency: from,
+		ToCurrency:   to,
+		Date:         date,
+		Rate:         req.Rate,
+		Source:       "manual",
+	}
+
+	if err := UpsertExchangeRates(config.DB, []models.ExchangeRate{rate}); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to save exchange rate"})
+	}
+	config.DB.Where("user_id = ? AND from_currency = ? AND to_currency = ? AND date = ?", userID, from, to, date).First(&rate)
+
+	return c.Status(201).JSON(fiber.Map{
+		"message":       "Exchange rate saved successfully",
+		"exchange_rate": rate,
+	})
+}
+
+// Import Exchange Rates dari file CSV atau XML format ECB (multipart field "file")
+func ImportExchangeRates(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "File is required"})
+	}
+	if fileHeader.Size > maxRatesFileSize {
+		return c.Status(400).JSON(fiber.Map{"error": "File is too large"})
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Failed to read file"})
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Failed to read file"})
+	}
+
+	rates, err := utils.ParseRatesFile(data)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid rates file: " + err.Error()})
+	}
+	for i := range rates {
+		rates[i].UserID = userID
+	}
+
+	if err := UpsertExchangeRates(config.DB, rates); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to save exchange rates"})
+	}
+
+	return c.JSON(fiber.Map{
+		"message":  "Exchange rates imported successfully",
+		"imported": len(rates),
+	})
+}
+
+// Delete Exchange Rate (hanya rate milik user, rate global tidak bisa dihapus)
+func DeleteExchangeRate(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+	id := c.Params("id")
+
+	var rate models.ExchangeRate
+	if err := config.DB.Where("id = ? AND user_id = ?", id, userID).First(&rate).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Exchange rate not found"})
+	}
+
+	if err := config.DB.Delete(&rate).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to delete exchange rate"})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Exchange rate deleted successfully",
+	})
+}
+
+// Update Base Currency user
+func UpdateBaseCurrency(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	req := new(BaseCurrencyRequest)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request"})
+	}
+
+	currency, ok := utils.NormalizeCurrency(req.BaseCurrency)
+	if !ok {
+		return c.Status(400).JSON(fiber.Map{"error": "Base currency must be a 3-letter ISO code"})
+	}
+
+	if err := config.DB.Model(&models.User{}).Where("id = ?", userID).Update("base_currency", currency).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to update base currency"})
+	}
+
+	return c.JSON(fiber.Map{
+		"message":       "Base currency updated successfully",
+		"base_currency": currency,
+	})
+}
@@ -0,0 +1,385 @@
+package controllers
+
+import (
+	"bufio"
+	"encoding/csv"
+	"finance-tracker-backend/config"
+	"finance-tracker-backend/models"
+	"finance-tracker-backend/utils"
+	"log"
+	"mime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// Jumlah transaksi yang dibaca dari database per putaran export
+const exportBatchSize = 500
+
+// Kolom yang bisa dipilih lewat ?columns=, urutannya mengikuti request
+var exportColumns = map[string]bool{
+	"id": true, "date": true, "account": true, "type": true, "category": true, "payee": true,
+	"description": true, "amount": true, "currency": true, "base_amount": true, "base_currency": true,
+	"tags": true, "transfer_id": true, "created_at": true,
+}
+
+var defaultExportColumns = []string{"date", "account", "type", "category", "payee", "description", "amount", "currency", "tags"}
+
+// Format angka & tanggal per locale. XLSX menyimpan angka dan tanggal asli, Excel yang
+// menampilkan pemisah ribuan/desimal; di sana locale hanya menentukan format tanggal.
+type exportLocale struct {
+	decimal    string // pemisah desimal di CSV (tanpa pemisah ribuan supaya tetap terbaca sebagai angka)
+	delimiter  rune   // pemisah kolom CSV; locale dengan koma desimal memakai ";" seperti Excel
+	dateLayout string
+	excelDate  string
+}
+
+var exportLocales = map[string]exportLocale{
+	"id":  {decimal: ",", delimiter: ';', dateLayout: "02/01/2006", excelDate: "dd/mm/yyyy"},
+	"en":  {decimal: ".", delimiter: ',', dateLayout: "01/02/2006", excelDate: "mm/dd/yyyy"},
+	"iso": {decimal: ".", delimiter: ',', dateLayout: "2006-01-02", excelDate: "yyyy-mm-dd"},
+}
+
+// Satu nilai sel export: teks, amount (dengan jumlah desimal currency-nya) atau tanggal
+type exportValue struct {
+	text     string
+	amount   *models.Money
+	decimals int
+	date     time.Time
+}
+
+// Penulis file export (CSV atau XLSX). flush dipanggil setiap selesai satu batch
+// supaya data langsung terkirim ke client.
+type transactionExporter interface {
+	header(columns []string) error
+	row(values []exportValue) error
+	flush() error
+	close() error
+}
+
+type csvExporter struct {
+	writer *csv.Writer
+	locale exportLocale
+}
+
+func (e *csvExporter) header(columns []string) error {
+	return e.writer.Write(columns)
+}
+
+// Teks yang diawali = + - @ diberi ' supaya tidak dijalankan sebagai formula saat dibuka di spreadsheet
+func csvSafeText(text string) string {
+	if text != "" && strings.ContainsRune("=+-@\t\r", rune(text[0])) {
+		return "'" + text
+	}
+	return text
+}
+
+func (e *csvExporter) row(values []exportValue) error {
+	record := make([]string, len(values))
+	for i, value := range values {
+		switch {
+		case value.amount != nil:
+			record[i] = strings.Replace(value.amount.StringFixed(value.decimals), ".", e.locale.decimal, 1)
+		case !value.date.IsZero():
+			record[i] = value.date.Format(e.locale.dateLayout)
+		default:
+			record[i] = csvSafeText(value.text)
+		}
+	}
+	return e.writer.Write(record)
+}
+
+func (e *csvExporter) flush() error {
+	e.writer.Flush()
+	return e.writer.Error()
+}
+
+func (e *csvExporter) close() error {
+	return e.flush()
+}
+
+type xlsxExporter struct {
+	writer *utils.XLSXWriter
+	output *bufio.Writer
+}
+
+func (e *xlsxExporter) header(columns []string) error {
+	return e.writer.WriteHeader(columns)
+}
+
+func (e *xlsxExporter) row(values []exportValue) error {
+	cells := make([]utils.XLSXCell, len(values))
+	for i, value := range values {
+		switch {
+		case value.amount != nil:
+			cells[i] = utils.XLSXNumber(value.amount.StringFixed(value.decimals), value.decimals)
+		case !value.date.IsZero():
+			cells[i] = utils.XLSXDate(value.date)
+		default:
+			cells[i] = utils.XLSXText(value.text)
+		}
+	}
+	return e.writer.WriteRow(cells)
+}
+
+func (e *xlsxExporter) flush() error {
+	return e.output.Flush()
+}
+
+func (e *xlsxExporter) close() error {
+	if err := e.writer.Close(); err != nil {
+		return err
+	}
+	return e.output.Flush()
+}
+
+// Nama yang dipakai di kolom export: account, payee, dan category lengkap dengan parent
+// ("Makanan > Restoran"). Dibaca sekali di awal export.
+type exportNames struct {
+	accounts     map[uint]string
+	payees       map[uint]string
+	categories   map[uint]models.Category
+	baseCurrency string
+	rates        *utils.RateTable
+}
+
+func loadExportNames(userID uint) (*exportNames, error) {
+	names := &exportNames{
+		accounts:     make(map[uint]string),
+		payees:       make(map[uint]string),
+		categories:   make(map[uint]models.Category),
+		baseCurrency: userBaseCurrency(userID),
+	}
+
+	var accounts []models.Account
+	if err := config.DB.Where("user_id = ?", userID).Find(&accounts).Error; err != nil {
+		return nil, err
+	}
+	for _, account := range accounts {
+		names.accounts[account.ID] = account.Name
+	}
+
+	var payees []models.Payee
+	if err := config.DB.Select("id", "name").Where("user_id = ?", userID).Find(&payees).Error; err != nil {
+		return nil, err
+	}
+	for _, payee := range payees {
+		names.payees[payee.ID] = payee.Name
+	}
+
+	var categories []models.Category
+	if err := config.DB.Where("user_id = ?", userID).Find(&categories).Error; err != nil {
+		return nil, err
+	}
+	for _, category := range categories {
+		names.categories[category.ID] = category
+	}
+
+	rates, err := loadRateTable(userID)
+	if err != nil {
+		return nil, err
+	}
+	names.rates = rates
+	return names, nil
+}
+
+func (n *exportNames) categoryPath(id uint) string {
+	category, ok := n.categories[id]
+	if !ok {
+		return ""
+	}
+	path := category.Name
+	// Batas kedalaman untuk berjaga-jaga kalau data parent melingkar
+	for depth := 0; category.ParentID != nil && depth < 10; depth++ {
+		if category, ok = n.categories[*category.ParentID]; !ok {
+			break
+		}
+		path = category.Name + " > " + path
+	}
+	return path
+}
+
+// Nilai kolom untuk satu transaksi. Amount ditulis apa adanya (positif untuk income/expense,
+// negatif untuk leg transfer keluar); arah dibaca dari kolom type.
+func (n *exportNames) values(transaction *models.Transaction, columns []string, locale exportLocale) []exportValue {
+	values := make([]exportValue, len(columns))
+	for i, column := range columns {
+		value := &values[i]
+		switch column {
+		case "id":
+			value.text = strconv.FormatUint(uint64(transaction.ID), 10)
+		case "date":
+			value.date = transaction.Date
+		case "account":
+			if transaction.AccountID != nil {
+				value.text = n.accounts[*transaction.AccountID]
+			}
+		case "type":
+			switch {
+			case transaction.TransferID != nil:
+				value.text = "transfer"
+			case transaction.CategoryID != nil:
+				value.text = n.categories[*transaction.CategoryID].Type
+			}
+		case "category":
+			// Transaksi split: semua category barisnya
+			if len(transaction.Splits) > 0 {
+				paths := make([]string, len(transaction.Splits))
+				for j, split := range transaction.Splits {
+					paths[j] = n.categoryPath(split.CategoryID)
+				}
+				value.text = strings.Join(paths, ", ")
+			} else if transaction.CategoryID != nil {
+				value.text = n.categoryPath(*transaction.CategoryID)
+			}
+		case "payee":
+			if transaction.PayeeID != nil {
+				value.text = n.payees[*transaction.PayeeID]
+			}
+		case "description":
+			value.text = transaction.Description
+		case "amount":
+			value.amount, value.decimals = &transaction.Amount, models.CurrencyDecimals(transaction.Currency)
+		case "currency":
+			value.text = transaction.Currency
+		case "base_amount":
+			if converted, err := n.rates.Convert(transaction.Amount, transaction.Currency, n.baseCurrency, transaction.Date); err == nil {
+				value.amount, value.decimals = &converted, models.CurrencyDecimals(n.baseCurrency)
+			}
+		case "base_currency":
+			value.text = n.baseCurrency
+		case "tags":
+			tags := make([]string, len(transaction.Tags))
+			for j, tag := range transaction.Tags {
+				tags[j] = tag.Name
+			}
+			value.text = strings.Join(tags, ", ")
+		case "transfer_id":
+			if transaction.TransferID != nil {
+				value.text = strconv.FormatUint(uint64(*transaction.TransferID), 10)
+			}
+		case "created_at":
+			value.text = transaction.CreatedAt.Format(locale.dateLayout + " 15:04")
+		}
+	}
+	return values
+}
+
+// Tulis semua transaksi yang cocok dengan filter, urut tanggal, per batch. Pagination memakai
+// posisi (date, id) transaksi terakhir supaya tidak melambat di halaman belakang seperti OFFSET.
+func writeTransactionExport(exporter transactionExporter, userID uint, filter func(*gorm.DB) *gorm.DB, names *exportNames, columns []string, locale exportLocale) error {
+	if err := exporter.header(columns); err != nil {
+		return err
+	}
+
+	var lastDate time.Time
+	var lastID uint
+	for {
+		var transactions []models.Transaction
+		query := config.DB.Where("user_id = ?", userID).Scopes(filter)
+		if lastID != 0 {
+			query = query.Where("(transactions.date > ? OR (transactions.date = ? AND transactions.id > ?))", lastDate, lastDate, lastID)
+		}
+		if err := query.Preload("Splits").Preload("Tags").
+			Order("transactions.date ASC, transactions.id ASC").Limit(exportBatchSize).Find(&transactions).Error; err != nil {
+			return err
+		}
+
+		for i := range transactions {
+			if err := exporter.row(names.values(&transactions[i], columns, locale)); err != nil {
+				return err
+			}
+		}
+		if err := exporter.flush(); err != nil {
+			return err
+		}
+		if len(transactions) < exportBatchSize {
+			return exporter.close()
+		}
+		last := transactions[len(transactions)-1]
+		lastDate, lastID = last.Date, last.ID
+	}
+}
+
+// Export Transactions: semua transaksi yang cocok dengan filter GetTransactions (category_id,
+// account_id, payee_id, tag, q, start_date/end_date) sebagai CSV atau XLSX.
+// format=csv (default)|xlsx, columns=date,amount,... (lihat exportColumns),
+// locale=id (default)|en|iso. File ditulis bertahap sambil dibaca dari database.
+func ExportTransactions(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	format := strings.ToLower(c.Query("format", "csv"))
+	if format != "csv" && format != "xlsx" {
+		return c.Status(400).JSON(fiber.Map{"error": "Format must be csv or xlsx"})
+	}
+
+	locale, ok := exportLocales[strings.ToLower(c.Query("locale", "id"))]
+	if !ok {
+		return c.Status(400).JSON(fiber.Map{"error": "Locale must be id, en or iso"})
+	}
+
+	columns := defaultExportColumns
+	if value := c.Query("columns"); value != "" {
+		columns = nil
+		for _, column := range strings.Split(value, ",") {
+			column = strings.ToLower(strings.TrimSpace(column))
+			if column == "" {
+				continue
+			}
+			if !exportColumns[column] {
+				return c.Status(400).JSON(fiber.Map{"error": "Unknown column: " + column})
+			}
+			columns = append(columns, column)
+		}
+		if len(columns) == 0 {
+			return c.Status(400).JSON(fiber.Map{"error": "At least one column is required"})
+		}
+	}
+
+	filter, _, status, msg := transactionFilterScope(c, userID)
+	if status != 0 {
+		return c.Status(status).JSON(fiber.Map{"error": msg})
+	}
+
+	names, err := loadExportNames(userID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to prepare export"})
+	}
+
+	fileName := "transactions-" + time.Now().Format("2006-01-02") + "." + format
+	if format == "xlsx" {
+		c.Set(fiber.HeaderContentType, "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+	} else {
+		c.Set(fiber.HeaderContentType, "text/csv; charset=utf-8")
+	}
+	c.Set(fiber.HeaderContentDisposition, mime.FormatMediaType("attachment", map[string]string{"filename": fileName}))
+	c.Set(fiber.HeaderCacheControl, "no-store")
+
+	// Response sudah 200 saat data mulai dikirim; error di tengah jalan hanya bisa di-log
+	// (file yang diterima client akan terpotong)
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		var exporter transactionExporter
+		if format == "xlsx" {
+			writer, err := utils.NewXLSXWriter(w, "Transactions", locale.excelDate)
+			if err != nil {
+				log.Printf("Failed to export transactions for user %d: %v", userID, err)
+				return
+			}
+			exporter = &xlsxExporter{writer: writer, output: w}
+		} else {
+			// BOM supaya Excel membaca CSV sebagai UTF-8
+			w.WriteString("\ufeff")
+			writer := csv.NewWriter(w)
+			writer.Comma = locale.delimiter
+			exporter = &csvExporter{writer: writer, locale: locale}
+		}
+
+		if err := writeTransactionExport(exporter, userID, filter, names, columns, locale); err != nil {
+			log.Printf("Failed to export transactions for user %d: %v", userID, err)
+		}
+	})
+	return nil
+}
@@ -0,0 +1,526 @@
+package controllers
+
+import (
+	"finance-tracker-backend/config"
+	"finance-tracker-backend/models"
+	"finance-tracker-backend/utils"
+	"math"
+	"math/big"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+type GoalRequest struct {
+	Name         string       `json:"name"`
+	TargetAmount models.Money `json:"target_amount"`
+	Currency     string       `json:"currency"`    // kosong = base currency user
+	TargetDate   *string      `json:"target_date"` // Format: "2025-03-30", "" = hapus target date
+	AccountID    *uint        `json:"account_id"`  // 0 = lepas account
+	CategoryID   *uint        `json:"category_id"` // 0 = lepas category
+	Archived     *bool        `json:"archived"`
+}
+
+type ContributionRequest struct {
+	TransactionID uint          `json:"transaction_id"`
+	Amount        *models.Money `json:"amount"` // wajib kalau tanpa transaksi, default = amount transaksi
+	Date          string        `json:"date"`   // wajib kalau tanpa transaksi
+	Note          string        `json:"note"`
+}
+
+type GoalProgress struct {
+	GoalID              uint          `json:"goal_id"`
+	Name                string        `json:"name"`
+	Currency            string        `json:"currency"`
+	TargetAmount        models.Money  `json:"target_amount"`
+	Saved               models.Money  `json:"saved"`
+	Remaining           models.Money  `json:"remaining"`
+	Percentage          float64       `json:"percentage"`
+	Completed           bool          `json:"completed"`
+	TargetDate          *string       `json:"target_date"`
+	MonthsLeft          *int          `json:"months_left"`
+	RequiredMonthly     *models.Money `json:"required_monthly"`     // setoran per bulan supaya tercapai di target date
+	RecentMonthlyRate   models.Money  `json:"recent_monthly_rate"`  // rata-rata setoran per bulan dalam 3 bulan terakhir
+	ProjectedCompletion *string       `json:"projected_completion"` // nil kalau rate <= 0
+	OnTrack             *bool         `json:"on_track"`             // nil kalau tidak ada target date
+}
+
+// Jendela rata-rata setoran untuk proyeksi
+const goalRateMonths = 3
+
+// Validasi account/category yang dihubungkan ke goal (0 = lepas)
+func applyGoalLinks(userID uint, goal *models.Goal, accountID, categoryID *uint) (int, string) {
+	if accountID != nil {
+		if *accountID == 0 {
+			goal.AccountID = nil
+		} else {
+			account, err := findUserAccount(userID, *accountID)
+			if err != nil {
+				return 404, "Account not found"
+			}
+			goal.AccountID = &account.ID
+		}
+	}
+	if categoryID != nil {
+		if *categoryID == 0 {
+			goal.CategoryID = nil
+		} else {
+			category, err := findUserCategory(userID, *categoryID)
+			if err != nil {
+				return 404, "Category not found"
+			}
+			goal.CategoryID = &category.ID
+		}
+	}
+	return 0, ""
+}
+
+// Jumlah bulan penuh/parsial dari from sampai to (minimal 1)
+func monthsUntil(from, to time.Time) int {
+	months := (to.Year()-from.Year())*12 + int(to.Month()-from.Month())
+	if to.Day() > from.Day() {
+		months++
+	}
+	if months < 1 {
+		months = 1
+	}
+	return months
+}
+
+// Bulatkan ke atas ke satuan terkecil currency (misalnya rupiah penuh)
+func ceilMoney(r *big.Rat, currency string) models.Money {
+	unit := int64(models.MoneyScale)
+	for i := 0; i < models.CurrencyDecimals(currency) && unit > 1; i++ {
+		unit /= 10
+	}
+	units := new(big.Rat).Quo(r, big.NewRat(unit, models.MoneyScale))
+	q, rem := new(big.Int).QuoRem(units.Num(), units.Denom(), new(big.Int))
+	if rem.Sign() > 0 {
+		q.Add(q, big.NewInt(1))
+	}
+	return models.Money(q.Int64() * unit)
+}
+
+// Hitung progress goal dari contribution-nya
+func goalProgress(goal *models.Goal, contributions []models.GoalContribution, today time.Time) GoalProgress {
+	progress := GoalProgress{
+		GoalID:       goal.ID,
+		Name:         goal.Name,
+		Currency:     goal.Currency,
+		TargetAmount: goal.TargetAmount,
+	}
+
+	recentFrom := today.AddDate(0, -goalRateMonths, 0)
+	var recent models.Money
+	for _, contribution := range contributions {
+		progress.Saved += contribution.Amount
+		if contribution.Date.After(recentFrom) && !contribution.Date.After(today) {
+			recent += contribution.Amount
+		}
+	}
+
+	progress.Remaining = goal.TargetAmount - progress.Saved
+	if progress.Remaining < 0 {
+		progress.Remaining = 0
+	}
+	progress.Completed = progress.Saved >= goal.TargetAmount
+	if goal.TargetAmount > 0 {
+		ratio := new(big.Rat).Quo(progress.Saved.Rat(), goal.TargetAmount.Rat())
+		percentage, _ := new(big.Rat).Mul(ratio, big.NewRat(100, 1)).Float64()
+		progress.Percentage = math.Round(percentage*100) / 100
+	}
+
+	rate, _ := models.MoneyFromRat(new(big.Rat).Quo(recent.Rat(), big.NewRat(goalRateMonths, 1)))
+	progress.RecentMonthlyRate = rate.Round(goal.Currency)
+
+	if progress.Completed {
+		done := today.Format("2006-01-02")
+		progress.ProjectedCompletion = &done
+	} else if rate > 0 {
+		// remaining / rate bulan lagi, dibulatkan ke atas per hari (1 bulan = 30.44 hari)
+		months, _ := new(big.Rat).Quo(progress.Remaining.Rat(), rate.Rat()).Float64()
+		projected := today.AddDate(0, 0, int(math.Ceil(months*30.44))).Format("2006-01-02")
+		progress.ProjectedCompletion = &projected
+	}
+
+	if goal.TargetDate != nil {
+		target := goal.TargetDate.Format("2006-01-02")
+		progress.TargetDate = &target
+
+		monthsLeft := monthsUntil(today, *goal.TargetDate)
+		if goal.TargetDate.Before(today) {
+			monthsLeft = 0
+		}
+		progress.MonthsLeft = &monthsLeft
+
+		// Dibulatkan ke atas supaya total setoran tidak kurang dari target
+		required := ceilMoney(new(big.Rat).Quo(progress.Remaining.Rat(), big.NewRat(int64(max(monthsLeft, 1)), 1)), goal.Currency)
+		progress.RequiredMonthly = &required
+
+		onTrack := progress.Completed || (progress.ProjectedCompletion != nil && *progress.ProjectedCompletion <= target)
+		progress.OnTrack = &onTrack
+	}
+
+	return progress
+}
+
+// Get All Goals (beserta progress)
+func GetGoals(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	var goals []models.Goal
+	query := config.DB.Where("user_id = ?", userID)
+
+	// Archived goals disembunyikan kecuali diminta
+	if c.Query("include_archived") != "true" {
+		query = query.Where("archived = ?", false)
+	}
+
+	if err := query.Preload("Contributions").Order("target_date IS NULL, target_date ASC, id ASC").Find(&goals).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch goals"})
+	}
+
+	today := todayUTC()
+	result := make([]fiber.Map, 0, len(goals))
+	for i := range goals {
+		progress := goalProgress(&goals[i], goals[i].Contributions, today)
+		goals[i].Contributions = nil
+		result = append(result, fiber.Map{"goal": goals[i], "progress": progress})
+	}
+
+	return c.JSON(fiber.Map{
+		"goals": result,
+	})
+}
+
+// Get Single Goal (beserta contribution)
+func GetGoal(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	var goal models.Goal
+	if err := config.DB.Where("id = ? AND user_id = ?", c.Params("id"), userID).
+		Preload("Account").Preload("Category").
+		Preload("Contributions", func(db *gorm.DB) *gorm.DB { return db.Order("date DESC") }).
+		First(&goal).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Goal not found"})
+	}
+
+	return c.JSON(fiber.Map{
+		"goal": goal,
+	})
+}
+
+// Create Goal
+func CreateGoal(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	req := new(GoalRequest)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request"})
+	}
+
+	// Validasi
+	if req.Name == "" || req.TargetAmount <= 0 {
+		return c.Status(400).JSON(fiber.Map{"error": "Name and a positive target amount are required"})
+	}
+
+	goal := models.Goal{
+		UserID:   userID,
+		Name:     req.Name,
+		Currency: userBaseCurrency(userID),
+	}
+	if req.Currency != "" {
+		code, ok := utils.NormalizeCurrency(req.Currency)
+		if !ok {
+			return c.Status(400).JSON(fiber.Map{"error": "Currency must be a 3-letter ISO code"})
+		}
+		goal.Currency = code
+	}
+	goal.TargetAmount = req.TargetAmount.Round(goal.Currency)
+
+	if req.TargetDate != nil && *req.TargetDate != "" {
+		date, err := time.Parse("2006-01-02", *req.TargetDate)
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "Invalid date format. Use YYYY-MM-DD"})
+		}
+		goal.TargetDate = &date
+	}
+	if status, msg := applyGoalLinks(userID, &goal, req.AccountID, req.CategoryID); status != 0 {
+		return c.Status(status).JSON(fiber.Map{"error": msg})
+	}
+	if req.Archived != nil {
+		goal.Archived = *req.Archived
+	}
+
+	if err := config.DB.Create(&goal).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to create goal"})
+	}
+
+	config.DB.Preload("Account").Preload("Category").First(&goal, goal.ID)
+
+	return c.Status(201).JSON(fiber.Map{
+		"message": "Goal created successfully",
+		"goal":    goal,
+	})
+}
+
+// Update Goal
+func UpdateGoal(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	var goal models.Goal
+	if err := config.DB.Where("id = ? AND user_id = ?", c.Params("id"), userID).First(&goal).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Goal not found"})
+	}
+
+	req := new(GoalRequest)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request"})
+	}
+
+	// Update fields
+	if req.Name != "" {
+		goal.Name = req.Name
+	}
+	if req.TargetAmount != 0 {
+		if req.TargetAmount < 0 {
+			return c.Status(400).JSON(fiber.Map{"error": "Target amount must be positive"})
+		}
+		goal.TargetAmount = req.TargetAmount
+	}
+	// Currency tidak bisa diubah karena contribution sudah tercatat dalam currency goal
+	if req.Currency != "" {
+		if code, ok := utils.NormalizeCurrency(req.Currency); !ok || code != goal.Currency {
+			return c.Status(400).JSON(fiber.Map{"error": "Goal currency cannot be changed"})
+		}
+	}
+	goal.TargetAmount = goal.TargetAmount.Round(goal.Currency)
+
+	if req.TargetDate != nil {
+		if *req.TargetDate == "" {
+			goal.TargetDate = nil
+		} else {
+			date, err := time.Parse("2006-01-02", *req.TargetDate)
+			if err != nil {
+				return c.Status(400).JSON(fiber.Map{"error": "Invalid date format. Use YYYY-MM-DD"})
+			}
+			goal.TargetDate = &date
+		}
+	}
+	if status, msg := applyGoalLinks(userID, &goal, req.AccountID, req.CategoryID); status != 0 {
+		return c.Status(status).JSON(fiber.Map{"error": msg})
+	}
+	if req.Archived != nil {
+		goal.Archived = *req.Archived
+	}
+
+	if err := config.DB.Omit("Account", "Category", "Contributions").Save(&goal).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to update goal"})
+	}
+
+	config.DB.Preload("Account").Preload("Category").First(&goal, goal.ID)
+
+	return c.JSON(fiber.Map{
+		"message": "Goal updated successfully",
+		"goal":    goal,
+	})
+}
+
+// Delete Goal (contribution ikut dihapus, transaksinya tetap ada)
+func DeleteGoal(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	var goal models.Goal
+	if err := config.DB.Where("id = ? AND user_id = ?", c.Params("id"), userID).First(&goal).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Goal not found"})
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("goal_id = ?", goal.ID).Delete(&models.GoalContribution{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&goal).Error
+	})
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to delete goal"})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Goal deleted successfully",
+	})
+}
+
+// Get Goal Progress: saved, remaining, setoran per bulan yang dibutuhkan dan proyeksi tanggal tercapai
+func GetGoalProgress(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	var goal models.Goal
+	if err := config.DB.Where("id = ? AND user_id = ?", c.Params("id"), userID).Preload("Contributions").First(&goal).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Goal not found"})
+	}
+
+	return c.JSON(fiber.Map{
+		"progress": goalProgress(&goal, goal.Contributions, todayUTC()),
+	})
+}
+
+// Amount transaksi bertanda untuk goal yang terhubung ke account: expense dinegatifkan,
+// leg transfer sudah bertanda (keluar negatif), split dihitung per category.
+func contributionAmount(transaction *models.Transaction) models.Money {
+	if transaction.TransferID != nil {
+		return transaction.Amount
+	}
+	if len(transaction.Splits) == 0 {
+		if transaction.Category != nil && transaction.Category.Type == "expense" {
+			return -transaction.Amount
+		}
+		return transaction.Amount
+	}
+	var amount models.Money
+	for _, split := range transaction.Splits {
+		if split.Category != nil && split.Category.Type == "expense" {
+			amount -= split.Amount
+		} else {
+			amount += split.Amount
+		}
+	}
+	return amount
+}
+
+// Untuk goal yang terhubung ke category, transaksi di category itu selalu setoran
+// (misalnya expense "Tabungan Motor"), berapapun tipe category-nya. Untuk transaksi
+// split hanya split di category goal yang dihitung.
+func categoryContributionAmount(transaction *models.Transaction, categoryID uint) (models.Money, bool) {
+	if len(transaction.Splits) == 0 {
+		matched := transaction.CategoryID != nil && *transaction.CategoryID == categoryID
+		return transaction.Amount, matched
+	}
+	var amount models.Money
+	matched := false
+	for _, split := range transaction.Splits {
+		if split.CategoryID == categoryID {
+			amount += split.Amount
+			matched = true
+		}
+	}
+	return amount, matched
+}
+
+// Add Contribution: hubungkan transaksi ke goal (amount dikonversi ke currency goal)
+// atau catat setoran manual dengan amount + date
+func AddGoalContribution(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	var goal models.Goal
+	if err := config.DB.Where("id = ? AND user_id = ?", c.Params("id"), userID).First(&goal).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Goal not found"})
+	}
+
+	req := new(ContributionRequest)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request"})
+	}
+
+	contribution := models.GoalContribution{
+		UserID: userID,
+		GoalID: goal.ID,
+		Note:   req.Note,
+	}
+
+	if req.TransactionID != 0 {
+		var transaction models.Transaction
+		if err := config.DB.Where("id = ? AND user_id = ?", req.TransactionID, userID).Preload("Category").Preload("Splits.Category").First(&transaction).Error; err != nil {
+			return c.Status(404).JSON(fiber.Map{"error": "Transaction not found"})
+		}
+
+		// Transaksi harus sesuai account/category yang dihubungkan ke goal
+		if goal.AccountID != nil && (transaction.AccountID == nil || *transaction.AccountID != *goal.AccountID) {
+			return c.Status(400).JSON(fiber.Map{"error": "Transaction is not in the goal's account"})
+		}
+		// Goal per account: income dan leg transfer masuk menambah goal, expense dan leg
+		// transfer keluar mengurangi. Goal per category: transaksi di category itu menambah goal.
+		amount := contributionAmount(&transaction)
+		if goal.CategoryID != nil {
+			var matched bool
+			amount, matched = categoryContributionAmount(&transaction, *goal.CategoryID)
+			if !matched {
+				return c.Status(400).JSON(fiber.Map{"error": "Transaction is not in the goal's category"})
+			}
+		}
+		if amount == 0 {
+			return c.Status(400).JSON(fiber.Map{"error": "Transaction amount is zero"})
+		}
+
+		var count int64
+		config.DB.Model(&models.GoalContribution{}).Where("goal_id = ? AND transaction_id = ?", goal.ID, transaction.ID).Count(&count)
+		if count > 0 {
+			return c.Status(409).JSON(fiber.Map{"error": "Transaction is already linked to this goal"})
+		}
+
+		contribution.TransactionID = &transaction.ID
+		contribution.Date = transaction.Date
+		if req.Amount != nil {
+			contribution.Amount = *req.Amount
+		} else {
+			rates, err := loadRateTable(userID)
+			if err != nil {
+				return c.Status(500).JSON(fiber.Map{"error": "Failed to load exchange rates"})
+			}
+			converted, err := rates.Convert(amount, transaction.Currency, goal.Currency, transaction.Date)
+			if err != nil {
+				return c.Status(422).JSON(fiber.Map{"error": err.Error()})
+			}
+			contribution.Amount = converted
+		}
+	} else {
+		if req.Amount == nil || *req.Amount == 0 || req.Date == "" {
+			return c.Status(400).JSON(fiber.Map{"error": "Transaction, or amount and date, are required"})
+		}
+		date, err := time.Parse("2006-01-02", req.Date)
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "Invalid date format. Use YYYY-MM-DD"})
+		}
+		contribution.Amount = *req.Amount
+		contribution.Date = date
+	}
+	if req.Date != "" && req.TransactionID != 0 {
+		date, err := time.Parse("2006-01-02", req.Date)
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "Invalid date format. Use YYYY-MM-DD"})
+		}
+		contribution.Date = date
+	}
+	contribution.Amount = contribution.Amount.Round(goal.Currency)
+
+	if err := config.DB.Create(&contribution).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to add contribution"})
+	}
+
+	var contributions []models.GoalContribution
+	config.DB.Where("goal_id = ?", goal.ID).Find(&contributions)
+
+	return c.Status(201).JSON(fiber.Map{
+		"message":      "Contribution added successfully",
+		"contribution": contribution,
+		"progress":     goalProgress(&goal, contributions, todayUTC()),
+	})
+}
+
+// Delete Contribution (transaksinya tetap ada)
+func DeleteGoalContribution(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	var contribution models.GoalContribution
+	if err := config.DB.Where("id = ? AND goal_id = ? AND user_id = ?", c.Params("contributionId"), c.Params("id"), userID).First(&contribution).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Contribution not found"})
+	}
+
+	if err := config.DB.Delete(&contribution).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to delete contribution"})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Contribution deleted successfully",
+	})
+}
@@ -0,0 +1,629 @@
+package controllers
+
+import (
+	"errors"
+	"finance-tracker-backend/config"
+	"finance-tracker-backend/models"
+	"finance-tracker-backend/utils"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// Ubah baris staging; field kosong tidak diubah
+type ImportRowRequest struct {
+	TransactionRequest        // account_id, category_id, amount, currency, description, date, payee_id/payee (splits & tags diabaikan)
+	Kind               string `json:"kind"`
+	TransferAccountID  uint   `json:"transfer_account_id"` // jadikan transfer ke/dari account ini (category dikosongkan)
+	Status             string `json:"status"`              // accepted atau rejected
+}
+
+// Accept/reject banyak baris sekaligus: row_ids, atau semua baris dengan status tertentu
+type ImportRowsStatusRequest struct {
+	RowIDs []uint `json:"row_ids"`
+	Status string `json:"status"` // misalnya "new" untuk accept semua baris yang bukan duplikat/conflict
+}
+
+// Hasil commit per baris staging
+type ImportRowResult struct {
+	RowID uint `json:"row_id"`
+	BulkItemResult
+}
+
+// Batch sudah di-commit/di-undo oleh request lain di antara pengecekan dan DB transaction
+var errImportBatchChanged = errors.New("import batch status changed")
+
+var importRowStatuses = map[string]bool{"new": true, "duplicate": true, "conflict": true, "accepted": true, "rejected": true}
+
+// Response upload import: default baris disimpan sebagai batch staging untuk di-review;
+// dengan form field dry_run=true hanya preview tanpa menyimpan apapun.
+func importResponse(c *fiber.Ctx, userID uint, source string, account *models.Account, rows []ImportPreviewRow, extra ...fiber.Map) error {
+	if c.FormValue("dry_run") == "true" {
+		return importPreviewResponse(c, rows, extra...)
+	}
+
+	fileName := ""
+	if fileHeader, err := c.FormFile("file"); err == nil {
+		fileName = fileHeader.Filename
+		// Dipotong per karakter (varchar 255), bukan per byte supaya UTF-8 tidak terbelah
+		if runes := []rune(fileName); len(runes) > 255 {
+			fileName = string(runes[:255])
+		}
+	}
+
+	batch, errorRows, err := stageImportRows(userID, source, fileName, account, rows)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to stage import"})
+	}
+
+	counts := make(map[string]int)
+	for _, row := range batch.Rows {
+		counts[row.Status]++
+	}
+	batch.Counts = counts
+
+	response := fiber.Map{
+		"message": "Import staged for review",
+		"batch":   batch,
+		"errors":  errorRows,
+		"summary": fiber.Map{
+			"total":      len(rows),
+			"new":        counts["new"],
+			"duplicates": counts["duplicate"],
+			"conflicts":  counts["conflict"],
+			"errors":     len(errorRows),
+		},
+	}
+	for _, fields := range extra {
+		for key, value := range fields {
+			response[key] = value
+		}
+	}
+	return c.Status(201).JSON(response)
+}
+
+// Simpan baris preview sebagai batch staging. Baris yang gagal di-parse tidak di-stage dan
+// dikembalikan terpisah. Status awal: duplicate kalau import_hash sudah pernah di-import atau
+// masih di-stage di batch lain yang belum di-commit, conflict kalau ada transaksi dengan
+// tanggal & amount sama, selain itu new.
+func stageImportRows(userID uint, source, fileName string, account *models.Account, preview []ImportPreviewRow) (*models.ImportBatch, []ImportPreviewRow, error) {
+	var hashes []string
+	for _, row := range preview {
+		if row.Error == "" && row.ImportHash != "" {
+			hashes = append(hashes, row.ImportHash)
+		}
+	}
+	staged := make(map[string]bool)
+	if len(hashes) > 0 {
+		var stagedHashes []string
+		if err := config.DB.Model(&models.ImportRow{}).
+			Joins("JOIN import_batches ON import_batches.id = import_rows.batch_id").
+			Where("import_rows.user_id = ? AND import_batches.status = ? AND import_rows.status <> ? AND import_rows.import_hash IN ?", userID, "staged", "rejected", hashes).
+			Pluck("import_rows.import_hash", &stagedHashes).Error; err != nil {
+			return nil, nil, err
+		}
+		for _, hash := range stagedHashes {
+			staged[hash] = true
+		}
+	}
+
+	batch := &models.ImportBatch{UserID: userID, Source: source, FileName: fileName, Status: "staged"}
+	if account != nil {
+		batch.AccountID = &account.ID
+	}
+
+	errorRows := []ImportPreviewRow{}
+	rows := make([]models.ImportRow, 0, len(preview))
+	for _, item := range preview {
+		if item.Error != "" {
+			errorRows = append(errorRows, item)
+			continue
+		}
+		row := models.ImportRow{
+			UserID:            userID,
+			Line:              item.Line,
+			Status:            "new",
+			Duplicate:         item.Duplicate,
+			DuplicateOf:       item.DuplicateOf,
+			ImportHash:        item.ImportHash,
+			AccountID:         batch.AccountID,
+			CategoryID:        item.CategoryID,
+			PayeeID:           item.PayeeID,
+			TransferAccountID: item.TransferAccountID,
+			Transfer:          item.Transfer,
+			Amount:            item.Amount,
+			Currency:          item.Currency,
+			Kind:              item.Kind,
+			Description:       item.Description,
+			Payee:             item.Payee,
+			Reference:         item.Reference,
+			Date:              item.Date,
+		}
+		switch {
+		case item.Duplicate == "exact":
+			row.Status = "duplicate"
+		case staged[item.ImportHash]:
+			row.Status, row.Duplicate = "duplicate", "staged"
+		case item.Duplicate == "possible":
+			row.Status = "conflict"
+		}
+		rows = append(rows, row)
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(batch).Error; err != nil {
+			return err
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+		for i := range rows {
+			rows[i].BatchID = batch.ID
+		}
+		return tx.CreateInBatches(&rows, 500).Error
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	batch.Rows = rows
+	return batch, errorRows, nil
+}
+
+// Jumlah baris per status untuk beberapa batch
+func importBatchCounts(batchIDs []uint) (map[uint]map[string]int, error) {
+	var counts []struct {
+		BatchID uint
+		Status  string
+		Total   int
+	}
+	result := make(map[uint]map[string]int, len(batchIDs))
+	if len(batchIDs) == 0 {
+		return result, nil
+	}
+	if err := config.DB.Model(&models.ImportRow{}).
+		Select("batch_id, status, COUNT(*) AS total").
+		Where("batch_id IN ?", batchIDs).
+		Group("batch_id, status").Scan(&counts).Error; err != nil {
+		return nil, err
+	}
+	for _, count := range counts {
+		if result[count.BatchID] == nil {
+			result[count.BatchID] = make(map[string]int)
+		}
+		result[count.BatchID][count.Status] = count.Total
+	}
+	return result, nil
+}
+
+// Batch milik user yang masih staged (belum di-commit)
+func findStagedBatch(userID uint, id string) (*models.ImportBatch, int, string) {
+	var batch models.ImportBatch
+	if err := config.DB.Where("id = ? AND user_id = ?", id, userID).First(&batch).Error; err != nil {
+		return nil, 404, "Import batch not found"
+	}
+	if batch.Status != "staged" {
+		return nil, 409, "Import batch is already committed, undo it first"
+	}
+	return &batch, 0, ""
+}
+
+// Ubah field baris staging dari request (field kosong tidak diubah), category harus sesuai kind
+func applyImportRowUpdate(userID uint, row *models.ImportRow, req *ImportRowRequest) (int, string) {
+	if req.Status != "" {
+		if req.Status != "accepted" && req.Status != "rejected" {
+			return 400, "Status must be 'accepted' or 'rejected'"
+		}
+		row.Status = req.Status
+	}
+	if req.Kind != "" {
+		if req.Kind != "income" && req.Kind != "expense" {
+			return 400, "Kind must be 'income' or 'expense'"
+		}
+		row.Kind = req.Kind
+	}
+	if req.AccountID != 0 {
+		account, err := findUserAccount(userID, req.AccountID)
+		if err != nil {
+			return 404, "Account not found"
+		}
+		if account.Archived {
+			return 400, "Account is archived"
+		}
+		row.AccountID = &account.ID
+		row.Currency = account.Currency
+	}
+
+	// Transfer dan category saling meniadakan: yang terakhir diisi yang berlaku
+	if req.TransferAccountID != 0 {
+		account, err := findUserAccount(userID, req.TransferAccountID)
+		if err != nil {
+			return 404, "Transfer account not found"
+		}
+		row.TransferAccountID, row.CategoryID = &account.ID, nil
+	}
+	if req.CategoryID != 0 {
+		category, err := findUserCategory(userID, req.CategoryID)
+		if err != nil {
+			return 404, "Category not found"
+		}
+		row.CategoryID, row.TransferAccountID = &category.ID, nil
+	}
+	if row.TransferAccountID != nil && row.AccountID != nil && *row.TransferAccountID == *row.AccountID {
+		return 400, "Transfer account must be different from the account"
+	}
+	if row.CategoryID != nil {
+		category, err := findUserCategory(userID, *row.CategoryID)
+		if err != nil || category.Type != row.Kind {
+			return 400, "Category type does not match the row (" + row.Kind + ")"
+		}
+	}
+
+	if req.Amount < 0 {
+		return 400, "Amount must be positive"
+	}
+	if req.Amount != 0 {
+		row.Amount = req.Amount
+	}
+	if req.Currency != "" {
+		code, ok := utils.NormalizeCurrency(req.Currency)
+		if !ok {
+			return 400, "Currency must be a 3-letter ISO code"
+		}
+		row.Currency = code
+	}
+	if req.Description != "" {
+		row.Description = req.Description
+	}
+	if req.PayeeID != 0 {
+		payee, err := findUserPayee(userID, req.PayeeID)
+		if err != nil {
+			return 404, "Payee not found"
+		}
+		row.PayeeID, row.Payee = &payee.ID, payee.Name
+	} else if name := strings.TrimSpace(req.Payee); name != "" {
+		row.PayeeID, row.Payee = nil, name
+	}
+	if req.Date != "" {
+		date, err := time.Parse("2006-01-02", req.Date)
+		if err != nil {
+			return 400, "Invalid date format. Use YYYY-MM-DD"
+		}
+		row.Date = date
+	}
+	row.Amount = row.Amount.Round(row.Currency)
+	return 0, ""
+}
+
+// Get Import Batches (terbaru dulu) dengan jumlah baris per status
+func GetImportBatches(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	var batches []models.ImportBatch
+	query := config.DB.Where("user_id = ?", userID)
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if err := query.Preload("Account").Order("id DESC").Find(&batches).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch import batches"})
+	}
+
+	ids := make([]uint, len(batches))
+	for i, batch := range batches {
+		ids[i] = batch.ID
+	}
+	counts, err := importBatchCounts(ids)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch import batches"})
+	}
+	for i := range batches {
+		batches[i].Counts = counts[batches[i].ID]
+	}
+
+	return c.JSON(fiber.Map{
+		"batches": batches,
+	})
+}
+
+// Get Import Batch beserta baris staging-nya (optional ?status=new|duplicate|conflict|accepted|rejected)
+func GetImportBatch(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+	id := c.Params("id")
+
+	status := c.Query("status")
+	if status != "" && !importRowStatuses[status] {
+		return c.Status(400).JSON(fiber.Map{"error": "Status must be new, duplicate, conflict, accepted or rejected"})
+	}
+
+	var batch models.ImportBatch
+	err := config.DB.Where("id = ? AND user_id = ?", id, userID).
+		Preload("Account").
+		Preload("Rows", func(db *gorm.DB) *gorm.DB {
+			if status != "" {
+				db = db.Where("status = ?", status)
+			}
+			return db.Order("line ASC, id ASC")
+		}).
+		Preload("Rows.Category").
+		First(&batch).Error
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Import batch not found"})
+	}
+
+	counts, err := importBatchCounts([]uint{batch.ID})
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch import batch"})
+	}
+	batch.Counts = counts[batch.ID]
+
+	return c.JSON(fiber.Map{
+		"batch": batch,
+	})
+}
+
+// Update Import Row: edit baris staging di tempat (hanya batch yang belum di-commit)
+func UpdateImportRow(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	batch, status, msg := findStagedBatch(userID, c.Params("id"))
+	if status != 0 {
+		return c.Status(status).JSON(fiber.Map{"error": msg})
+	}
+
+	var row models.ImportRow
+	if err := config.DB.Where("id = ? AND batch_id = ?", c.Params("rowId"), batch.ID).First(&row).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Import row not found"})
+	}
+
+	req := new(ImportRowRequest)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request"})
+	}
+	if status, msg := applyImportRowUpdate(userID, &row, req); status != 0 {
+		return c.Status(status).JSON(fiber.Map{"error": msg})
+	}
+
+	if err := config.DB.Save(&row).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to update import row"})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Import row updated successfully",
+		"row":     row,
+	})
+}
+
+// Set status banyak baris sekaligus (bulk accept/reject)
+func setImportRowsStatus(c *fiber.Ctx, status string) error {
+	userID := c.Locals("userID").(uint)
+
+	batch, code, msg := findStagedBatch(userID, c.Params("id"))
+	if code != 0 {
+		return c.Status(code).JSON(fiber.Map{"error": msg})
+	}
+
+	req := new(ImportRowsStatusRequest)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request"})
+	}
+
+	query := config.DB.Model(&models.ImportRow{}).Where("batch_id = ?", batch.ID)
+	switch {
+	case len(req.RowIDs) > 0:
+		query = query.Where("id IN ?", req.RowIDs)
+	case importRowStatuses[req.Status]:
+		query = query.Where("status = ?", req.Status)
+	default:
+		return c.Status(400).JSON(fiber.Map{"error": "row_ids or a valid status (new, duplicate, conflict, accepted, rejected) is required"})
+	}
+
+	result := query.Update("status", status)
+	if result.Error != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to update import rows"})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Import rows updated successfully",
+		"updated": result.RowsAffected,
+	})
+}
+
+// Accept Import Rows (bulk)
+func AcceptImportRows(c *fiber.Ctx) error {
+	return setImportRowsStatus(c, "accepted")
+}
+
+// Reject Import Rows (bulk)
+func RejectImportRows(c *fiber.Ctx) error {
+	return setImportRowsStatus(c, "rejected")
+}
+
+// Commit Import Batch: semua baris accepted divalidasi seperti POST /transactions dan disimpan
+// dalam satu DB transaction. Kalau satu baris gagal tidak ada yang disimpan. Baris yang sudah
+// ditandai duplikat saat upload dan tetap di-accept boleh di-import ulang.
+func CommitImportBatch(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	batch, status, msg := findStagedBatch(userID, c.Params("id"))
+	if status != 0 {
+		return c.Status(status).JSON(fiber.Map{"error": msg})
+	}
+
+	var rows []models.ImportRow
+	if err := config.DB.Where("batch_id = ? AND status = ?", batch.ID, "accepted").Order("line ASC, id ASC").Find(&rows).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch import rows"})
+	}
+	if len(rows) == 0 {
+		return c.Status(400).JSON(fiber.Map{"error": "No accepted rows to commit"})
+	}
+
+	commitRows := make([]importCommitRow, len(rows))
+	for i, row := range rows {
+		commitRow := importCommitRow{
+			TransactionRequest: TransactionRequest{
+				Amount:      row.Amount,
+				Currency:    row.Currency,
+				Description: row.Description,
+				Date:        row.Date.Format("2006-01-02"),
+				Payee:       row.Payee,
+			},
+			Kind:           row.Kind,
+			ImportHash:     row.ImportHash,
+			AllowDuplicate: row.Duplicate != "",
+		}
+		if row.AccountID != nil {
+			commitRow.AccountID = *row.AccountID
+		}
+		if row.CategoryID != nil {
+			commitRow.CategoryID = *row.CategoryID
+		}
+		if row.PayeeID != nil {
+			commitRow.PayeeID = *row.PayeeID
+		}
+		if row.TransferAccountID != nil {
+			commitRow.TransferAccountID = *row.TransferAccountID
+		}
+		commitRows[i] = commitRow
+	}
+
+	items, results, failed, err := buildImportItems(userID, commitRows)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to check duplicates"})
+	}
+	rowResults := make([]ImportRowResult, len(results))
+	for i, result := range results {
+		rowResults[i] = ImportRowResult{RowID: rows[result.Index].ID, BulkItemResult: result}
+	}
+	if failed {
+		return c.Status(422).JSON(fiber.Map{
+			"error":   "Some accepted rows are invalid, nothing was imported",
+			"results": rowResults,
+		})
+	}
+
+	now := time.Now()
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		// Status diubah lebih dulu dengan syarat masih staged: commit kedua yang berjalan
+		// bersamaan menunggu lock baris batch lalu mendapat 0 rows affected
+		result := tx.Model(&models.ImportBatch{}).Where("id = ? AND status = ?", batch.ID, "staged").
+			Updates(map[string]interface{}{"status": "committed", "committed_at": now})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errImportBatchChanged
+		}
+		if err := saveImportItems(tx, userID, &batch.ID, items); err != nil {
+			return err
+		}
+		for i, item := range items {
+			updates := map[string]interface{}{"transaction_id": item.id}
+			if item.transfer != nil {
+				updates["transfer_id"] = item.transfer.ID
+			}
+			if err := tx.Model(&rows[i]).Updates(updates).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if errors.Is(err, errImportBatchChanged) {
+		return c.Status(409).JSON(fiber.Map{"error": "Import batch is already committed"})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to import transactions"})
+	}
+	batch.Status, batch.CommittedAt = "committed", &now
+
+	for i, item := range items {
+		rowResults[i].ID = item.id
+	}
+
+	return c.Status(201).JSON(fiber.Map{
+		"message":  "Import batch committed successfully",
+		"imported": len(items),
+		"results":  rowResults,
+		"batch":    batch,
+	})
+}
+
+// Undo Import Batch: hapus semua transaksi dan transfer yang dibuat batch (termasuk yang sudah
+// diedit setelah commit). Batch kembali ke staged sehingga bisa direview dan di-commit ulang
+// atau dihapus.
+func UndoImportBatch(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+	id := c.Params("id")
+
+	var batch models.ImportBatch
+	if err := config.DB.Where("id = ? AND user_id = ?", id, userID).First(&batch).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Import batch not found"})
+	}
+	if batch.Status != "committed" {
+		return c.Status(409).JSON(fiber.Map{"error": "Import batch is not committed"})
+	}
+
+	var transactionIDs []uint
+	var attachments []models.Attachment
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		// Sama seperti commit: hanya satu undo yang bisa mengubah batch dari committed
+		result := tx.Model(&models.ImportBatch{}).Where("id = ? AND status = ?", batch.ID, "committed").
+			Updates(map[string]interface{}{"status": "staged", "committed_at": nil})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errImportBatchChanged
+		}
+		if err := tx.Model(&models.Transaction{}).Where("user_id = ? AND import_batch_id = ?", userID, batch.ID).Pluck("id", &transactionIDs).Error; err != nil {
+			return err
+		}
+		var err error
+		if attachments, err = deleteTransactions(tx, transactionIDs); err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ? AND import_batch_id = ?", userID, batch.ID).Delete(&models.Transfer{}).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.ImportRow{}).Where("batch_id = ?", batch.ID).
+			Updates(map[string]interface{}{"transaction_id": nil, "transfer_id": nil}).Error
+	})
+	if errors.Is(err, errImportBatchChanged) {
+		return c.Status(409).JSON(fiber.Map{"error": "Import batch is not committed"})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to undo import batch"})
+	}
+	batch.Status, batch.CommittedAt = "staged", nil
+	removeAttachmentFiles(attachments)
+
+	return c.JSON(fiber.Map{
+		"message": "Import batch undone successfully",
+		"removed": len(transactionIDs),
+		"batch":   batch,
+	})
+}
+
+// Delete Import Batch: buang batch staging beserta barisnya (batch yang sudah di-commit harus di-undo dulu)
+func DeleteImportBatch(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	batch, status, msg := findStagedBatch(userID, c.Params("id"))
+	if status != 0 {
+		return c.Status(status).JSON(fiber.Map{"error": msg})
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("batch_id = ?", batch.ID).Delete(&models.ImportRow{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(batch).Error
+	})
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to delete import batch"})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Import batch deleted successfully",
+	})
+}
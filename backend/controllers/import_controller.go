@@ -0,0 +1,936 @@
+package controllers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"finance-tracker-backend/config"
+	"finance-tracker-backend/models"
+	"finance-tracker-backend/utils"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+type ImportMappingRequest struct {
+	Name              string `json:"name"`
+	Delimiter         string `json:"delimiter"` // default ","
+	SkipRows          int    `json:"skip_rows"`
+	HasHeader         *bool  `json:"has_header"` // default true
+	DateColumn        string `json:"date_column"`
+	DateFormat        string `json:"date_format"`       // default "DD/MM/YYYY"
+	DecimalSeparator  string `json:"decimal_separator"` // "." (default) atau ","
+	AmountMode        string `json:"amount_mode"`       // signed (default), debit_credit, indicator
+	AmountColumn      string `json:"amount_column"`
+	InvertSign        bool   `json:"invert_sign"`
+	DebitColumn       string `json:"debit_column"`
+	CreditColumn      string `json:"credit_column"`
+	IndicatorColumn   string `json:"indicator_column"`
+	DebitIndicator    string `json:"debit_indicator"`
+	DescriptionColumn string `json:"description_column"`
+	CategoryColumn    string `json:"category_column"`
+	PayeeColumn       string `json:"payee_column"`
+	CurrencyColumn    string `json:"currency_column"`
+}
+
+// Baris preview import: hasil parsing + category/payee tebakan + status duplikat
+type ImportPreviewRow struct {
+	utils.StatementRow
+	ImportHash  string `json:"import_hash"`
+	CategoryID  *uint  `json:"category_id"`
+	PayeeID     *uint  `json:"payee_id"`
+	Duplicate   string `json:"duplicate,omitempty"` // exact: baris ini sudah pernah di-import, possible: ada transaksi dengan tanggal & amount sama
+	DuplicateOf *uint  `json:"duplicate_of,omitempty"`
+
+	// Baris transfer (top up / tarik saldo): account lawan, dari form transfer_account_id
+	TransferAccountID *uint `json:"transfer_account_id,omitempty"`
+}
+
+// Baris staging yang di-commit, divalidasi dengan aturan yang sama dengan POST /transactions
+type importCommitRow struct {
+	TransactionRequest
+	Kind           string // optional, kalau diisi harus sama dengan type category
+	ImportHash     string
+	AllowDuplicate bool // tetap import walaupun import_hash sudah pernah di-import
+
+	// Kalau diisi, baris disimpan sebagai transfer antara AccountID dan account ini
+	// (tanpa category). Kind menentukan arah: income = masuk ke AccountID.
+	TransferAccountID uint
+}
+
+// Batas ukuran file dan jumlah baris per import
+const (
+	maxImportFileSize = 10 << 20
+	maxImportRows     = 5000
+)
+
+// Validasi dan isi default mapping
+func applyImportMapping(mapping *models.ImportMapping, req *ImportMappingRequest) (int, string) {
+	mapping.Name = strings.TrimSpace(req.Name)
+	mapping.Delimiter = req.Delimiter
+	mapping.SkipRows = req.SkipRows
+	mapping.HasHeader = req.HasHeader == nil || *req.HasHeader
+	mapping.DateColumn = strings.TrimSpace(req.DateColumn)
+	mapping.DateFormat = strings.TrimSpace(req.DateFormat)
+	mapping.DecimalSeparator = req.DecimalSeparator
+	mapping.AmountMode = req.AmountMode
+	mapping.AmountColumn = strings.TrimSpace(req.AmountColumn)
+	mapping.InvertSign = req.InvertSign
+	mapping.DebitColumn = strings.TrimSpace(req.DebitColumn)
+	mapping.CreditColumn = strings.TrimSpace(req.CreditColumn)
+	mapping.IndicatorColumn = strings.TrimSpace(req.IndicatorColumn)
+	mapping.DebitIndicator = strings.TrimSpace(req.DebitIndicator)
+	mapping.DescriptionColumn = strings.TrimSpace(req.DescriptionColumn)
+	mapping.CategoryColumn = strings.TrimSpace(req.CategoryColumn)
+	mapping.PayeeColumn = strings.TrimSpace(req.PayeeColumn)
+	mapping.CurrencyColumn = strings.TrimSpace(req.CurrencyColumn)
+
+	if mapping.Delimiter == "" {
+		mapping.Delimiter = ","
+	}
+	if mapping.DateFormat == "" {
+		mapping.DateFormat = "DD/MM/YYYY"
+	}
+	if mapping.DecimalSeparator == "" {
+		mapping.DecimalSeparator = "."
+	}
+	if mapping.AmountMode == "" {
+		mapping.AmountMode = "signed"
+	}
+
+	// Validasi
+	if mapping.Name == "" || len(mapping.Name) > 100 {
+		return 400, "Name is required (max 100 characters)"
+	}
+	if len([]rune(mapping.Delimiter)) != 1 {
+		return 400, "Delimiter must be a single character"
+	}
+	if mapping.SkipRows < 0 {
+		return 400, "skip_rows cannot be negative"
+	}
+	if mapping.DateColumn == "" {
+		return 400, "date_column is required"
+	}
+	if mapping.DecimalSeparator != "." && mapping.DecimalSeparator != "," {
+		return 400, "decimal_separator must be '.' or ','"
+	}
+	if mapping.DecimalSeparator == mapping.Delimiter {
+		return 400, "decimal_separator cannot be the same as the delimiter"
+	}
+	switch mapping.AmountMode {
+	case "signed":
+		if mapping.AmountColumn == "" {
+			return 400, "amount_column is required"
+		}
+	case "debit_credit":
+		if mapping.DebitColumn == "" || mapping.CreditColumn == "" {
+			return 400, "debit_column and credit_column are required"
+		}
+	case "indicator":
+		if mapping.AmountColumn == "" || mapping.IndicatorColumn == "" || mapping.DebitIndicator == "" {
+			return 400, "amount_column, indicator_column and debit_indicator are required"
+		}
+	default:
+		return 400, "amount_mode must be 'signed', 'debit_credit' or 'indicator'"
+	}
+	return 0, ""
+}
+
+// Baca file upload (multipart field "file") dengan batas ukuran import
+func readImportFile(c *fiber.Ctx) ([]byte, int, string) {
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return nil, 400, "File is required"
+	}
+	if fileHeader.Size > maxImportFileSize {
+		return nil, 400, "File is too large (max 10 MB)"
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return nil, 400, "Failed to read file"
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxImportFileSize+1))
+	if err != nil || len(data) > maxImportFileSize {
+		return nil, 400, "Failed to read file"
+	}
+	return data, 0, ""
+}
+
+// Account tujuan import dari form field account_id (optional)
+func importAccount(c *fiber.Ctx, userID uint) (*models.Account, int, string) {
+	value := c.FormValue("account_id")
+	if value == "" || value == "0" {
+		return nil, 0, ""
+	}
+	account, err := findUserAccount(userID, value)
+	if err != nil {
+		return nil, 404, "Account not found"
+	}
+	if account.Archived {
+		return nil, 400, "Account is archived"
+	}
+	return account, 0, ""
+}
+
+// Sidik baris statement untuk deteksi duplikat. Kalau bank memberi ID transaksi (Reference)
+// itu yang dipakai; kalau tidak, tanggal + amount + arah + description, ditambah urutan
+// kemunculan supaya dua baris identik di file yang sama tetap dianggap dua transaksi.
+func importHash(accountID uint, row utils.StatementRow, occurrence int) string {
+	key := "ref|" + row.Reference
+	if row.Reference == "" {
+		key = fmt.Sprintf("%s|%s|%s|%s|%d", row.Date.Format("2006-01-02"), row.Amount.String(), row.Kind, normalizePayeeAlias(row.Description), occurrence)
+	}
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%d|%s", accountID, key)))
+	return hex.EncodeToString(sum[:])
+}
+
+type importExistingRow struct {
+	ID         uint
+	Date       time.Time
+	Amount     models.Money
+	ImportHash string
+}
+
+// Lengkapi baris hasil parsing untuk preview: currency, import hash, tebakan payee & category,
+// dan status duplikat terhadap transaksi yang sudah ada (di account yang sama kalau ada account).
+func previewStatementRows(userID uint, account *models.Account, rows []utils.StatementRow) ([]ImportPreviewRow, error) {
+	var accountID uint
+	defaultCurrency := ""
+	if account != nil {
+		accountID = account.ID
+		defaultCurrency = account.Currency
+	}
+	if defaultCurrency == "" {
+		defaultCurrency = userBaseCurrency(userID)
+	}
+
+	var categories []models.Category
+	if err := config.DB.Where("user_id = ?", userID).Find(&categories).Error; err != nil {
+		return nil, err
+	}
+	categoryByName := make(map[string]uint, len(categories))
+	categoryType := make(map[uint]string, len(categories))
+	for _, category := range categories {
+		categoryByName[category.Type+"|"+strings.ToLower(category.Name)] = category.ID
+		categoryType[category.ID] = category.Type
+	}
+
+	var payees []models.Payee
+	if err := config.DB.Where("user_id = ?", userID).Preload("Aliases").Find(&payees).Error; err != nil {
+		return nil, err
+	}
+
+	result := make([]ImportPreviewRow, len(rows))
+	occurrences := make(map[string]int)
+	var minDate, maxDate time.Time
+	for i, row := range rows {
+		preview := ImportPreviewRow{StatementRow: row}
+		if row.Error != "" {
+			result[i] = preview
+			continue
+		}
+		if preview.Currency == "" {
+			preview.Currency = defaultCurrency
+		}
+		preview.Amount = preview.Amount.Round(preview.Currency)
+
+		base := importHash(accountID, preview.StatementRow, 0)
+		preview.ImportHash = importHash(accountID, preview.StatementRow, occurrences[base])
+		occurrences[base]++
+
+		// Payee dari kolom payee, kalau tidak dikenal ditebak dari description
+		var payee *models.Payee
+		if preview.Payee != "" {
+			payee = matchPayeeName(payees, preview.Payee)
+		}
+		if payee == nil {
+			payee = bestPayeeMatch(payees, normalizePayeeAlias(preview.Description))
+		}
+		if payee != nil {
+			preview.PayeeID = &payee.ID
+			if preview.Payee == "" {
+				preview.Payee = payee.Name
+			}
+		}
+
+		// Category dari kolom category, kalau tidak ada pakai default category payee (type harus cocok).
+		// Baris transfer tidak punya category.
+		switch id, ok := categoryByName[preview.Kind+"|"+strings.ToLower(preview.Category)]; {
+		case preview.Transfer:
+		case ok && preview.Category != "":
+			preview.CategoryID = &id
+		case payee != nil && payee.DefaultCategoryID != nil && categoryType[*payee.DefaultCategoryID] == preview.Kind:
+			preview.CategoryID = payee.DefaultCategoryID
+		}
+
+		if minDate.IsZero() || preview.Date.Before(minDate) {
+			minDate = preview.Date
+		}
+		if preview.Date.After(maxDate) {
+			maxDate = preview.Date
+		}
+		result[i] = preview
+	}
+	if minDate.IsZero() {
+		return result, nil
+	}
+
+	// Transaksi yang sudah ada di rentang tanggal file
+	var existing []importExistingRow
+	query := config.DB.Model(&models.Transaction{}).
+		Select("id", "date", "amount", "import_hash").
+		Where("user_id = ? AND date >= ? AND date < ?", userID, minDate, maxDate.AddDate(0, 0, 1))
+	if accountID != 0 {
+		query = query.Where("account_id = ?", accountID)
+	}
+	if err := query.Order("id ASC").Scan(&existing).Error; err != nil {
+		return nil, err
+	}
+
+	byHash := make(map[string]uint, len(existing))
+	byDateAmount := make(map[string][]uint)
+	for _, transaction := range existing {
+		if transaction.ImportHash != "" {
+			byHash[transaction.ImportHash] = transaction.ID
+		}
+		key := transaction.Date.Format("2006-01-02") + "|" + transaction.Amount.String()
+		byDateAmount[key] = append(byDateAmount[key], transaction.ID)
+	}
+
+	// Satu transaksi lama hanya bisa jadi pasangan duplikat satu baris
+	claimed := make(map[uint]bool)
+	for i := range result {
+		preview := &result[i]
+		if preview.Error != "" {
+			continue
+		}
+		if id, ok := byHash[preview.ImportHash]; ok {
+			preview.Duplicate, preview.DuplicateOf = "exact", &id
+			claimed[id] = true
+		}
+	}
+	for i := range result {
+		preview := &result[i]
+		if preview.Error != "" || preview.Duplicate != "" {
+			continue
+		}
+		for _, id := range byDateAmount[preview.Date.Format("2006-01-02")+"|"+preview.Amount.String()] {
+			if !claimed[id] {
+				id := id
+				preview.Duplicate, preview.DuplicateOf = "possible", &id
+				claimed[id] = true
+				break
+			}
+		}
+	}
+	return result, nil
+}
+
+// Response preview yang sama untuk semua format import; extra berisi info khusus format
+func importPreviewResponse(c *fiber.Ctx, rows []ImportPreviewRow, extra ...fiber.Map) error {
+	valid, invalid, duplicates := 0, 0, 0
+	for _, row := range rows {
+		switch {
+		case row.Error != "":
+			invalid++
+		case row.Duplicate != "":
+			duplicates++
+		default:
+			valid++
+		}
+	}
+	response := fiber.Map{
+		"rows": rows,
+		"summary": fiber.Map{
+			"total":      len(rows),
+			"new":        valid,
+			"duplicates": duplicates,
+			"errors":     invalid,
+		},
+	}
+	for _, fields := range extra {
+		for key, value := range fields {
+			response[key] = value
+		}
+	}
+	return c.JSON(response)
+}
+
+// Perbandingan saldo statement dengan saldo account di aplikasi.
+// Saldo awal dibandingkan dengan saldo account sebelum transaksi pertama statement,
+// saldo akhir dengan saldo per closing date ditambah baris preview yang belum ada
+// (bukan duplikat dan tidak error).
+type StatementReconciliation struct {
+	AccountNumber         string        `json:"account_number"`
+	Currency              string        `json:"currency"`
+	OpeningBalance        *models.Money `json:"opening_balance"`
+	OpeningDate           *time.Time    `json:"opening_date"`
+	ClosingBalance        *models.Money `json:"closing_balance"`
+	ClosingDate           *time.Time    `json:"closing_date"`
+	AccountOpeningBalance *models.Money `json:"account_opening_balance,omitempty"`
+	OpeningDifference     *models.Money `json:"opening_difference,omitempty"` // opening - saldo aplikasi, 0 berarti cocok
+	AccountBalance        *models.Money `json:"account_balance,omitempty"`
+	ProjectedBalance      *models.Money `json:"projected_balance,omitempty"`
+	Difference            *models.Money `json:"difference,omitempty"` // closing - projected, 0 berarti cocok
+}
+
+// Saldo account (dalam currency account) per akhir hari date, dihitung seperti GetBalance
+func accountBalanceAt(userID uint, accountID uint, date time.Time) (models.Money, error) {
+	rows, err := balanceRows(userID, func(db *gorm.DB) *gorm.DB {
+		return db.Where("transactions.account_id = ? AND transactions.date < ?", accountID, date.AddDate(0, 0, 1))
+	})
+	if err != nil {
+		return 0, err
+	}
+	rates, err := loadRateTable(userID)
+	if err != nil {
+		return 0, err
+	}
+	balances, err := accountBalances(userID, rows, rates, userBaseCurrency(userID))
+	if err != nil {
+		return 0, err
+	}
+	for _, balance := range balances {
+		if balance.AccountID == accountID {
+			return balance.Balance, nil
+		}
+	}
+	return 0, gorm.ErrRecordNotFound
+}
+
+func reconcileStatement(userID uint, account *models.Account, statement utils.Statement, rows []ImportPreviewRow) (StatementReconciliation, error) {
+	result := StatementReconciliation{
+		AccountNumber:  statement.AccountNumber,
+		Currency:       statement.Currency,
+		OpeningBalance: statement.OpeningBalance,
+		OpeningDate:    statement.OpeningDate,
+		ClosingBalance: statement.ClosingBalance,
+		ClosingDate:    statement.ClosingDate,
+	}
+	if account == nil {
+		return result, nil
+	}
+
+	if statement.OpeningBalance != nil {
+		// Sehari sebelum transaksi pertama, atau tanggal saldo awal kalau statement kosong
+		var before *time.Time
+		for _, row := range rows {
+			if row.Error == "" && (before == nil || row.Date.Before(*before)) {
+				date := row.Date
+				before = &date
+			}
+		}
+		var date time.Time
+		if before != nil {
+			date = before.AddDate(0, 0, -1)
+		} else if statement.OpeningDate != nil {
+			date = *statement.OpeningDate
+		}
+		if !date.IsZero() {
+			opening, err := accountBalanceAt(userID, account.ID, date)
+			if err != nil {
+				return result, err
+			}
+			difference := *statement.OpeningBalance - opening
+			result.AccountOpeningBalance, result.OpeningDifference = &opening, &difference
+		}
+	}
+
+	if statement.ClosingBalance == nil || statement.ClosingDate == nil {
+		return result, nil
+	}
+	current, err := accountBalanceAt(userID, account.ID, *statement.ClosingDate)
+	if err != nil {
+		return result, err
+	}
+	projected := current
+	for _, row := range rows {
+		if row.Error != "" || row.Duplicate != "" || row.Date.After(*statement.ClosingDate) {
+			continue
+		}
+		if row.Kind == "income" {
+			projected += row.Amount
+		} else {
+			projected -= row.Amount
+		}
+	}
+	difference := *statement.ClosingBalance - projected
+	result.AccountBalance, result.ProjectedBalance, result.Difference = &current, &projected, &difference
+	return result, nil
+}
+
+// Get Import Mappings
+func GetImportMappings(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	var mappings []models.ImportMapping
+	if err := config.DB.Where("user_id = ?", userID).Order("name ASC").Find(&mappings).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch import mappings"})
+	}
+
+	return c.JSON(fiber.Map{
+		"mappings": mappings,
+	})
+}
+
+// Create Import Mapping
+func CreateImportMapping(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	req := new(ImportMappingRequest)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request"})
+	}
+
+	mapping := models.ImportMapping{UserID: userID}
+	if status, msg := applyImportMapping(&mapping, req); status != 0 {
+		return c.Status(status).JSON(fiber.Map{"error": msg})
+	}
+
+	var count int64
+	config.DB.Model(&models.ImportMapping{}).Where("user_id = ? AND name = ?", userID, mapping.Name).Count(&count)
+	if count > 0 {
+		return c.Status(409).JSON(fiber.Map{"error": "An import mapping with this name already exists"})
+	}
+
+	if err := config.DB.Create(&mapping).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to create import mapping"})
+	}
+
+	return c.Status(201).JSON(fiber.Map{
+		"message": "Import mapping created successfully",
+		"mapping": mapping,
+	})
+}
+
+// Update Import Mapping (semua field diganti, sama seperti saat create)
+func UpdateImportMapping(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+	id := c.Params("id")
+
+	var mapping models.ImportMapping
+	if err := config.DB.Where("id = ? AND user_id = ?", id, userID).First(&mapping).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Import mapping not found"})
+	}
+
+	req := new(ImportMappingRequest)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request"})
+	}
+
+	if status, msg := applyImportMapping(&mapping, req); status != 0 {
+		return c.Status(status).JSON(fiber.Map{"error": msg})
+	}
+
+	var count int64
+	config.DB.Model(&models.ImportMapping{}).Where("user_id = ? AND name = ? AND id <> ?", userID, mapping.Name, mapping.ID).Count(&count)
+	if count > 0 {
+		return c.Status(409).JSON(fiber.Map{"error": "An import mapping with this name already exists"})
+	}
+
+	if err := config.DB.Save(&mapping).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to update import mapping"})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Import mapping updated successfully",
+		"mapping": mapping,
+	})
+}
+
+// Delete Import Mapping
+func DeleteImportMapping(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+	id := c.Params("id")
+
+	var mapping models.ImportMapping
+	if err := config.DB.Where("id = ? AND user_id = ?", id, userID).First(&mapping).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Import mapping not found"})
+	}
+
+	if err := config.DB.Delete(&mapping).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to delete import mapping"})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Import mapping deleted successfully",
+	})
+}
+
+// Preview CSV Import (multipart: file, account_id, dan mapping_id atau mapping berisi JSON
+// ImportMappingRequest). Baris disimpan sebagai batch staging untuk di-review
+// (/import/batches); dengan dry_run=true tidak ada yang disimpan.
+func PreviewCSVImport(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	var mapping models.ImportMapping
+	if mappingID := c.FormValue("mapping_id"); mappingID != "" {
+		if err := config.DB.Where("id = ? AND user_id = ?", mappingID, userID).First(&mapping).Error; err != nil {
+			return c.Status(404).JSON(fiber.Map{"error": "Import mapping not found"})
+		}
+	} else {
+		req := new(ImportMappingRequest)
+		if err := json.Unmarshal([]byte(c.FormValue("mapping")), req); err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "mapping_id or a valid mapping is required"})
+		}
+		if req.Name == "" {
+			req.Name = "preview"
+		}
+		if status, msg := applyImportMapping(&mapping, req); status != 0 {
+			return c.Status(status).JSON(fiber.Map{"error": msg})
+		}
+	}
+
+	account, status, msg := importAccount(c, userID)
+	if status != 0 {
+		return c.Status(status).JSON(fiber.Map{"error": msg})
+	}
+
+	data, status, msg := readImportFile(c)
+	if status != 0 {
+		return c.Status(status).JSON(fiber.Map{"error": msg})
+	}
+
+	rows, err := utils.ParseStatementCSV(data, mapping)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid CSV file: " + err.Error()})
+	}
+	if len(rows) > maxImportRows {
+		return c.Status(400).JSON(fiber.Map{"error": "Too many rows, the limit is " + strconv.Itoa(maxImportRows) + " per import"})
+	}
+
+	preview, err := previewStatementRows(userID, account, rows)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to prepare import preview"})
+	}
+
+	return importResponse(c, userID, "csv", account, preview)
+}
+
+// Preview untuk format statement (OFX, QIF, MT940, CAMT.053): multipart file + account_id.
+// Kalau file berisi beberapa rekening, pilih salah satu dengan account_number.
+func previewStatementImport(c *fiber.Ctx, format string, parse func([]byte) ([]utils.Statement, error)) error {
+	userID := c.Locals("userID").(uint)
+
+	account, status, msg := importAccount(c, userID)
+	if status != 0 {
+		return c.Status(status).JSON(fiber.Map{"error": msg})
+	}
+
+	data, status, msg := readImportFile(c)
+	if status != 0 {
+		return c.Status(status).JSON(fiber.Map{"error": msg})
+	}
+
+	statements, err := parse(data)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid " + format + " file: " + err.Error()})
+	}
+
+	statement := statements[0]
+	if len(statements) > 1 {
+		number := strings.TrimSpace(c.FormValue("account_number"))
+		numbers := make([]string, 0, len(statements))
+		found := false
+		for _, candidate := range statements {
+			numbers = append(numbers, candidate.AccountNumber)
+			if number != "" && candidate.AccountNumber == number {
+				statement, found = candidate, true
+			}
+		}
+		if !found {
+			return c.Status(400).JSON(fiber.Map{
+				"error":           "File contains several accounts, choose one with account_number",
+				"account_numbers": numbers,
+			})
+		}
+	}
+	if len(statement.Rows) > maxImportRows {
+		return c.Status(400).JSON(fiber.Map{"error": "Too many rows, the limit is " + strconv.Itoa(maxImportRows) + " per import"})
+	}
+	if account != nil && statement.Currency != "" && statement.Currency != account.Currency {
+		return c.Status(400).JSON(fiber.Map{"error": "Statement currency " + statement.Currency + " does not match the account currency " + account.Currency})
+	}
+
+	preview, err := previewStatementRows(userID, account, statement.Rows)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to prepare import preview"})
+	}
+
+	reconciliation, err := reconcileStatement(userID, account, statement, preview)
+	if err != nil {
+		return c.Status(422).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return importResponse(c, userID, strings.ToLower(format), account, preview, fiber.Map{"statement": reconciliation})
+}
+
+// Preview OFX/QFX Import. FITID dipakai sebagai import_hash sehingga
+// file yang sama bisa di-upload ulang tanpa transaksi dobel.
+func PreviewOFXImport(c *fiber.Ctx) error {
+	return previewStatementImport(c, "OFX", utils.ParseOFX)
+}
+
+// Preview QIF Import. QIF tidak punya ID transaksi, import_hash dihitung dari
+// tanggal + amount + description. date_format DD/MM/YYYY (default) atau MM/DD/YYYY.
+func PreviewQIFImport(c *fiber.Ctx) error {
+	dateFormat := strings.ToUpper(strings.TrimSpace(c.FormValue("date_format", "DD/MM/YYYY")))
+	if dateFormat != "DD/MM/YYYY" && dateFormat != "MM/DD/YYYY" {
+		return c.Status(400).JSON(fiber.Map{"error": "date_format must be DD/MM/YYYY or MM/DD/YYYY"})
+	}
+	dayFirst := dateFormat == "DD/MM/YYYY"
+	return previewStatementImport(c, "QIF", func(data []byte) ([]utils.Statement, error) {
+		return utils.ParseQIF(data, dayFirst)
+	})
+}
+
+// Preview MT940 Import. Referensi :61: dipakai sebagai import_hash, saldo :60F:/:62F:
+// dicocokkan dengan saldo rekening.
+func PreviewMT940Import(c *fiber.Ctx) error {
+	return previewStatementImport(c, "MT940", utils.ParseMT940)
+}
+
+// Preview CAMT.053 Import. AcctSvcrRef/NtryRef dipakai sebagai import_hash, saldo
+// OPBD/CLBD dicocokkan dengan saldo rekening.
+func PreviewCAMTImport(c *fiber.Ctx) error {
+	return previewStatementImport(c, "CAMT.053", utils.ParseCAMT053)
+}
+
+// Preview history e-wallet/marketplace (gopay, ovo, dana, shopeepay, tokopedia; CSV atau XLSX).
+// account_id adalah account e-wallet-nya, transfer_account_id (optional) rekening sumber top up
+// dan tujuan tarik saldo. Nomor referensi provider dipakai sebagai import_hash.
+func PreviewEwalletImport(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	name, ok := utils.EwalletProvider(c.Params("provider"))
+	if !ok {
+		return c.Status(400).JSON(fiber.Map{"error": "Provider must be gopay, ovo, dana, shopeepay or tokopedia"})
+	}
+
+	account, status, msg := importAccount(c, userID)
+	if status != 0 {
+		return c.Status(status).JSON(fiber.Map{"error": msg})
+	}
+
+	var transferAccountID *uint
+	if value := c.FormValue("transfer_account_id"); value != "" && value != "0" {
+		transferAccount, err := findUserAccount(userID, value)
+		if err != nil {
+			return c.Status(404).JSON(fiber.Map{"error": "Transfer account not found"})
+		}
+		if account != nil && transferAccount.ID == account.ID {
+			return c.Status(400).JSON(fiber.Map{"error": "Transfer account must be different from the imported account"})
+		}
+		transferAccountID = &transferAccount.ID
+	}
+
+	data, status, msg := readImportFile(c)
+	if status != 0 {
+		return c.Status(status).JSON(fiber.Map{"error": msg})
+	}
+
+	rows, err := utils.ParseEwallet(c.Params("provider"), data)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid " + name + " file: " + err.Error()})
+	}
+	if len(rows) > maxImportRows {
+		return c.Status(400).JSON(fiber.Map{"error": "Too many rows, the limit is " + strconv.Itoa(maxImportRows) + " per import"})
+	}
+
+	preview, err := previewStatementRows(userID, account, rows)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to prepare import preview"})
+	}
+	for i := range preview {
+		if preview[i].Transfer {
+			preview[i].TransferAccountID = transferAccountID
+		}
+	}
+
+	return importResponse(c, userID, strings.ToLower(c.Params("provider")), account, preview, fiber.Map{"provider": name})
+}
+
+// Validasi semua baris yang akan di-commit dari batch staging.
+// failed = ada baris yang tidak valid, detailnya di results.
+func buildImportItems(userID uint, rows []importCommitRow) ([]importCommitItem, []BulkItemResult, bool, error) {
+	// import_hash yang sudah pernah di-import
+	var hashes []string
+	for _, row := range rows {
+		if row.ImportHash != "" {
+			hashes = append(hashes, row.ImportHash)
+		}
+	}
+	imported := make(map[string]bool, len(hashes))
+	if len(hashes) > 0 {
+		var existing []string
+		if err := config.DB.Model(&models.Transaction{}).Where("user_id = ? AND import_hash IN ?", userID, hashes).Pluck("import_hash", &existing).Error; err != nil {
+			return nil, nil, false, err
+		}
+		for _, hash := range existing {
+			imported[hash] = true
+		}
+	}
+
+	results := make([]BulkItemResult, 0, len(rows))
+	items := make([]importCommitItem, 0, len(rows))
+	failed := false
+	seen := make(map[string]bool, len(hashes))
+	for i := range rows {
+		row := &rows[i]
+
+		status, msg := 0, ""
+		switch {
+		case row.ImportHash != "" && len(row.ImportHash) > 64:
+			status, msg = 400, "Invalid import_hash"
+		case row.ImportHash != "" && seen[row.ImportHash]:
+			status, msg = 400, "Row appears more than once in this import"
+		case row.ImportHash != "" && imported[row.ImportHash] && !row.AllowDuplicate:
+			status, msg = 409, "Row was already imported, set allow_duplicate to import it again"
+		}
+		seen[row.ImportHash] = row.ImportHash != ""
+
+		// Top up / tarik saldo disimpan sebagai transfer antar account
+		if row.TransferAccountID != 0 {
+			var item importCommitItem
+			if status == 0 {
+				item, status, msg = buildImportTransfer(userID, row)
+			}
+			if status != 0 {
+				failed = true
+				results = append(results, BulkItemResult{Operation: "transfer", Index: i, Status: status, Error: msg})
+				continue
+			}
+			items = append(items, item)
+			results = append(results, BulkItemResult{Operation: "transfer", Index: i, Status: 201})
+			continue
+		}
+
+		var transaction *models.Transaction
+		var payee *models.Payee
+		if status == 0 {
+			transaction, payee, status, msg = buildTransaction(userID, &row.TransactionRequest)
+		}
+		if status == 0 && row.Kind != "" {
+			category, err := findUserCategory(userID, *transaction.CategoryID)
+			if err != nil || category.Type != row.Kind {
+				status, msg = 400, "Category type does not match the row ("+row.Kind+")"
+			}
+		}
+		if status != 0 {
+			failed = true
+			results = append(results, BulkItemResult{Operation: "create", Index: i, Status: status, Error: msg})
+			continue
+		}
+
+		transaction.ImportHash = row.ImportHash
+		items = append(items, importCommitItem{create: &bulkCreate{transaction: transaction, payee: payee, tags: row.Tags}})
+		results = append(results, BulkItemResult{Operation: "create", Index: i, Status: 201})
+	}
+	return items, results, failed, nil
+}
+
+// Simpan item yang sudah divalidasi buildImportItems di dalam DB transaction. batchID diisi
+// kalau item berasal dari batch staging, supaya batch bisa di-undo.
+func saveImportItems(tx *gorm.DB, userID uint, batchID *uint, items []importCommitItem) error {
+	newPayees := make(map[string]*models.Payee)
+	for i := range items {
+		item := &items[i]
+		if item.create == nil {
+			item.transfer.ImportBatchID = batchID
+			if err := createImportTransfer(tx, item); err != nil {
+				return err
+			}
+			continue
+		}
+		item.create.transaction.ImportBatchID = batchID
+		if err := createTransaction(tx, userID, item.create.transaction, bulkPayee(newPayees, item.create.payee), item.create.tags); err != nil {
+			return err
+		}
+		item.id = item.create.transaction.ID
+	}
+	return nil
+}
+
+// Baris commit yang sudah valid: transaksi biasa (create) atau transfer top up / tarik saldo
+type importCommitItem struct {
+	create     *bulkCreate
+	transfer   *models.Transfer
+	from, to   *models.Account
+	accountID  uint // account yang di-import, leg transfer-nya menyimpan import_hash
+	importHash string
+	id         uint // ID transaksi yang dibuat (untuk transfer: leg di account yang di-import)
+}
+
+// Validasi baris transfer. Amount dalam currency account yang di-import, jadi kedua account
+// harus satu currency.
+func buildImportTransfer(userID uint, row *importCommitRow) (importCommitItem, int, string) {
+	if row.AccountID == 0 {
+		return importCommitItem{}, 400, "account_id is required for transfer rows"
+	}
+	if row.Kind != "income" && row.Kind != "expense" {
+		return importCommitItem{}, 400, "kind must be income or expense for transfer rows"
+	}
+	if row.Amount <= 0 || row.Date == "" {
+		return importCommitItem{}, 400, "Amount and date are required"
+	}
+	date, err := time.Parse("2006-01-02", row.Date)
+	if err != nil {
+		return importCommitItem{}, 400, "Invalid date format. Use YYYY-MM-DD"
+	}
+
+	// income: uang masuk ke account yang di-import (top up), expense: keluar (tarik saldo)
+	fromID, toID := row.TransferAccountID, row.AccountID
+	if row.Kind == "expense" {
+		fromID, toID = row.AccountID, row.TransferAccountID
+	}
+	from, to, status, msg := validateTransferAccounts(userID, fromID, toID)
+	if status != 0 {
+		return importCommitItem{}, status, msg
+	}
+	if from.Currency != to.Currency {
+		return importCommitItem{}, 422, "Transfer accounts use different currencies, create this transfer manually"
+	}
+
+	transfer := &models.Transfer{
+		UserID:        userID,
+		FromAccountID: fromID,
+		ToAccountID:   toID,
+		Amount:        row.Amount,
+		Description:   row.Description,
+		Date:          date,
+	}
+	if status, msg := resolveTransferToAmount(userID, transfer, 0, from, to); status != 0 {
+		return importCommitItem{}, status, msg
+	}
+	return importCommitItem{transfer: transfer, from: from, to: to, accountID: row.AccountID, importHash: row.ImportHash}, 0, ""
+}
+
+func createImportTransfer(tx *gorm.DB, item *importCommitItem) error {
+	if err := tx.Create(item.transfer).Error; err != nil {
+		return err
+	}
+	legs := transferLegs(item.transfer, item.from, item.to)
+	for i := range legs {
+		legs[i].ImportBatchID = item.transfer.ImportBatchID
+		if *legs[i].AccountID == item.accountID {
+			legs[i].ImportHash = item.importHash
+		}
+	}
+	if err := tx.Create(&legs).Error; err != nil {
+		return err
+	}
+	for _, leg := range legs {
+		if *leg.AccountID == item.accountID {
+			item.id = leg.ID
+		}
+	}
+	return nil
+}
@@ -0,0 +1,736 @@
+package controllers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"finance-tracker-backend/config"
+	"finance-tracker-backend/models"
+	"finance-tracker-backend/utils"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// Draft sudah di-accept/di-reject oleh request lain di antara pengecekan dan DB transaction
+var errDraftChanged = errors.New("draft status changed")
+
+type NotificationTemplateRequest struct {
+	Bank       string `json:"bank"`
+	Name       string `json:"name"`
+	Pattern    string `json:"pattern"`     // regex dengan named group amount (wajib), merchant, date, direction, ...
+	Kind       string `json:"kind"`        // income atau expense (default expense)
+	DateFormat string `json:"date_format"` // optional, misalnya "DD/MM/YY"
+	Active     *bool  `json:"active"`      // default true
+}
+
+type TestNotificationTemplateRequest struct {
+	NotificationTemplateRequest
+	TemplateID uint   `json:"template_id"` // test template tersimpan; kalau kosong pakai pattern di request
+	Text       string `json:"text"`
+}
+
+// Satu SMS/email yang di-paste atau dikirim dari aplikasi forwarder
+type NotificationMessage struct {
+	Text       string `json:"text"`
+	Sender     string `json:"sender"`      // nomor/nama pengirim, dipakai menebak bank
+	ReceivedAt string `json:"received_at"` // RFC3339, default sekarang
+	Source     string `json:"source"`      // sms (default) atau email
+}
+
+type IngestNotificationRequest struct {
+	AccountID uint `json:"account_id"` // optional, account untuk semua draft
+	NotificationMessage
+	Messages []NotificationMessage `json:"messages"` // untuk kirim banyak pesan sekaligus
+}
+
+// Ubah draft sebelum di-accept; field kosong tidak diubah
+type DraftRequest struct {
+	TransactionRequest
+	Kind string `json:"kind"`
+}
+
+// Hasil ingest per pesan
+type IngestResult struct {
+	Index   int    `json:"index"`
+	Status  string `json:"status"` // created, duplicate atau unmatched
+	DraftID uint   `json:"draft_id,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// Batas jumlah pesan per request ingest
+const maxIngestMessages = 500
+
+// Validasi dan isi template dari request
+func applyNotificationTemplate(template *models.NotificationTemplate, req *NotificationTemplateRequest) (int, string) {
+	template.Bank = strings.TrimSpace(req.Bank)
+	template.Name = strings.TrimSpace(req.Name)
+	template.Pattern = strings.TrimSpace(req.Pattern)
+	template.Kind = req.Kind
+	template.DateFormat = strings.TrimSpace(req.DateFormat)
+	template.Active = req.Active == nil || *req.Active
+	if template.Kind == "" {
+		template.Kind = "expense"
+	}
+
+	if template.Bank == "" || len(template.Bank) > 50 {
+		return 400, "Bank is required (max 50 characters)"
+	}
+	if template.Name == "" || len(template.Name) > 100 {
+		return 400, "Name is required (max 100 characters)"
+	}
+	if template.Kind != "income" && template.Kind != "expense" {
+		return 400, "Kind must be 'income' or 'expense'"
+	}
+	if _, err := utils.CompileNotificationPattern(template.Pattern); err != nil {
+		return 400, err.Error()
+	}
+	return 0, ""
+}
+
+// Template aktif yang dicoba untuk user: template user lebih dulu, lalu template sistem.
+// Kalau bank pengirim dikenal, template bank itu didahulukan.
+func notificationTemplates(userID uint, bank string) ([]models.NotificationTemplate, error) {
+	var templates []models.NotificationTemplate
+	if err := config.DB.Where("(user_id = ? OR user_id = 0) AND active = ?", userID, true).
+		Order("user_id DESC, id ASC").Find(&templates).Error; err != nil {
+		return nil, err
+	}
+	if bank != "" {
+		sort.SliceStable(templates, func(i, j int) bool {
+			return strings.EqualFold(templates[i].Bank, bank) && !strings.EqualFold(templates[j].Bank, bank)
+		})
+	}
+	return templates, nil
+}
+
+// Sidik pesan: teks yang sudah dirapikan + tanggal dan amount hasil parsing, supaya upload ulang
+// mbox/SMS yang sama tidak jadi draft dobel. Waktu terima tidak ikut, karena bisa berbeda per kiriman.
+func notificationHash(userID uint, text string, match *utils.NotificationMatch) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%d|%s|%s|%s", userID, match.Date.Format("2006-01-02"), match.Amount.String(), utils.NormalizeNotificationText(text))))
+	return hex.EncodeToString(sum[:])
+}
+
+// Pesan yang sudah dibaca dan siap dijadikan draft
+type parsedNotification struct {
+	index    int
+	message  NotificationMessage
+	received time.Time
+	bank     string
+	match    *utils.NotificationMatch
+	hash     string
+}
+
+// Baca semua pesan, buat draft untuk yang cocok dengan template. Kategori dan payee ditebak
+// dengan cara yang sama seperti preview import.
+func ingestNotifications(userID uint, account *models.Account, messages []NotificationMessage) ([]IngestResult, []models.TransactionDraft, error) {
+	results := make([]IngestResult, len(messages))
+	templatesByBank := make(map[string][]models.NotificationTemplate)
+	var parsed []parsedNotification
+	hashes := make([]string, 0, len(messages))
+	for i, message := range messages {
+		results[i] = IngestResult{Index: i}
+		if strings.TrimSpace(message.Text) == "" {
+			results[i].Status, results[i].Error = "unmatched", "Message is empty"
+			continue
+		}
+
+		// received_at sudah divalidasi di handler
+		received := time.Now()
+		if message.ReceivedAt != "" {
+			received, _ = time.Parse(time.RFC3339, message.ReceivedAt)
+		}
+
+		bank := utils.NotificationBank(message.Sender)
+		templates, ok := templatesByBank[bank]
+		if !ok {
+			var err error
+			if templates, err = notificationTemplates(userID, bank); err != nil {
+				return nil, nil, err
+			}
+			templatesByBank[bank] = templates
+		}
+		match, err := utils.MatchNotification(message.Text, received, templates)
+		if err != nil {
+			results[i].Status, results[i].Error = "unmatched", err.Error()
+			continue
+		}
+		if bank == "" {
+			bank = match.Template.Bank
+		}
+
+		hash := notificationHash(userID, message.Text, match)
+		hashes = append(hashes, hash)
+		parsed = append(parsed, parsedNotification{index: i, message: message, received: received, bank: bank, match: match, hash: hash})
+	}
+	if len(parsed) == 0 {
+		return results, nil, nil
+	}
+
+	// Pesan yang sudah pernah jadi draft (termasuk yang sudah di-accept/reject)
+	existing := make(map[string]bool)
+	var existingHashes []string
+	if err := config.DB.Model(&models.TransactionDraft{}).Where("user_id = ? AND message_hash IN ?", userID, hashes).
+		Pluck("message_hash", &existingHashes).Error; err != nil {
+		return nil, nil, err
+	}
+	for _, hash := range existingHashes {
+		existing[hash] = true
+	}
+
+	rows := make([]utils.StatementRow, 0, len(parsed))
+	fresh := make([]parsedNotification, 0, len(parsed))
+	for _, item := range parsed {
+		if existing[item.hash] {
+			results[item.index].Status = "duplicate"
+			continue
+		}
+		existing[item.hash] = true
+		row := utils.StatementRow{
+			Line:        item.index + 1,
+			Date:        item.match.Date,
+			Amount:      item.match.Amount,
+			Kind:        item.match.Kind,
+			Description: item.match.Description,
+			Payee:       item.match.Merchant,
+			Reference:   item.match.Reference,
+		}
+		if row.Kind == "expense" {
+			row.Category = utils.KeywordCategory(row.Description)
+		}
+		rows = append(rows, row)
+		fresh = append(fresh, item)
+	}
+	if len(rows) == 0 {
+		return results, nil, nil
+	}
+
+	preview, err := previewStatementRows(userID, account, rows)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	drafts := make([]models.TransactionDraft, len(fresh))
+	for i, item := range fresh {
+		source := item.message.Source
+		if source != "email" {
+			source = "sms"
+		}
+		drafts[i] = models.TransactionDraft{
+			UserID:      userID,
+			Source:      source,
+			Bank:        item.bank,
+			TemplateID:  &item.match.Template.ID,
+			Sender:      item.message.Sender,
+			RawText:     item.message.Text,
+			ReceivedAt:  item.received,
+			MessageHash: item.hash,
+			CategoryID:  preview[i].CategoryID,
+			PayeeID:     preview[i].PayeeID,
+			Amount:      preview[i].Amount,
+			Currency:    preview[i].Currency,
+			Kind:        preview[i].Kind,
+			Description: preview[i].Description,
+			Merchant:    item.match.Merchant,
+			Reference:   item.match.Reference,
+			Balance:     item.match.Balance,
+			Date:        preview[i].Date,
+			DuplicateOf: preview[i].DuplicateOf,
+			Status:      "pending",
+		}
+		if account != nil {
+			drafts[i].AccountID = &account.ID
+		}
+	}
+	if err := config.DB.Create(&drafts).Error; err != nil {
+		return nil, nil, err
+	}
+	for i, item := range fresh {
+		results[item.index].Status, results[item.index].DraftID = "created", drafts[i].ID
+	}
+	return results, drafts, nil
+}
+
+// Ubah field draft dari request (field kosong tidak diubah), category harus sesuai kind
+func applyDraftUpdate(userID uint, draft *models.TransactionDraft, req *DraftRequest) (int, string) {
+	if req.Kind != "" {
+		if req.Kind != "income" && req.Kind != "expense" {
+			return 400, "Kind must be 'income' or 'expense'"
+		}
+		draft.Kind = req.Kind
+	}
+	if req.AccountID != 0 {
+		account, err := findUserAccount(userID, req.AccountID)
+		if err != nil {
+			return 404, "Account not found"
+		}
+		if account.Archived {
+			return 400, "Account is archived"
+		}
+		draft.AccountID = &account.ID
+		draft.Currency = account.Currency
+	}
+	if req.CategoryID != 0 {
+		category, err := findUserCategory(userID, req.CategoryID)
+		if err != nil {
+			return 404, "Category not found"
+		}
+		draft.CategoryID = &category.ID
+	}
+	if draft.CategoryID != nil {
+		category, err := findUserCategory(userID, *draft.CategoryID)
+		if err != nil || category.Type != draft.Kind {
+			return 400, "Category type does not match the draft (" + draft.Kind + ")"
+		}
+	}
+	if req.Amount < 0 {
+		return 400, "Amount must be positive"
+	}
+	if req.Amount != 0 {
+		draft.Amount = req.Amount
+	}
+	if req.Currency != "" {
+		code, ok := utils.NormalizeCurrency(req.Currency)
+		if !ok {
+			return 400, "Currency must be a 3-letter ISO code"
+		}
+		draft.Currency = code
+	}
+	if req.Description != "" {
+		draft.Description = req.Description
+	}
+	if req.PayeeID != 0 {
+		payee, err := findUserPayee(userID, req.PayeeID)
+		if err != nil {
+			return 404, "Payee not found"
+		}
+		draft.PayeeID, draft.Merchant = &payee.ID, payee.Name
+	} else if name := strings.TrimSpace(req.Payee); name != "" {
+		draft.PayeeID, draft.Merchant = nil, name
+	}
+	if req.Date != "" {
+		date, err := time.Parse("2006-01-02", req.Date)
+		if err != nil {
+			return 400, "Invalid date format. Use YYYY-MM-DD"
+		}
+		draft.Date = date
+	}
+	draft.Amount = draft.Amount.Round(draft.Currency)
+	return 0, ""
+}
+
+func findPendingDraft(userID uint, id string) (*models.TransactionDraft, int, string) {
+	var draft models.TransactionDraft
+	if err := config.DB.Where("id = ? AND user_id = ?", id, userID).First(&draft).Error; err != nil {
+		return nil, 404, "Draft not found"
+	}
+	if draft.Status != "pending" {
+		return nil, 409, "Draft is already " + draft.Status
+	}
+	return &draft, 0, ""
+}
+
+// Get Notification Templates (template sistem + milik user)
+func GetNotificationTemplates(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	var templates []models.NotificationTemplate
+	query := config.DB.Where("user_id = ? OR user_id = 0", userID)
+	if bank := c.Query("bank"); bank != "" {
+		query = query.Where("bank = ?", bank)
+	}
+	if err := query.Order("bank ASC, user_id DESC, id ASC").Find(&templates).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch notification templates"})
+	}
+
+	return c.JSON(fiber.Map{
+		"templates": templates,
+	})
+}
+
+// Create Notification Template
+func CreateNotificationTemplate(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	req := new(NotificationTemplateRequest)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request"})
+	}
+
+	template := models.NotificationTemplate{UserID: userID}
+	if status, msg := applyNotificationTemplate(&template, req); status != 0 {
+		return c.Status(status).JSON(fiber.Map{"error": msg})
+	}
+
+	if err := config.DB.Create(&template).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to create notification template"})
+	}
+
+	return c.Status(201).JSON(fiber.Map{
+		"message":  "Notification template created successfully",
+		"template": template,
+	})
+}
+
+// Update Notification Template (template sistem tidak bisa diubah)
+func UpdateNotificationTemplate(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+	id := c.Params("id")
+
+	var template models.NotificationTemplate
+	if err := config.DB.Where("id = ? AND user_id = ?", id, userID).First(&template).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Notification template not found"})
+	}
+
+	req := new(NotificationTemplateRequest)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request"})
+	}
+	if status, msg := applyNotificationTemplate(&template, req); status != 0 {
+		return c.Status(status).JSON(fiber.Map{"error": msg})
+	}
+
+	if err := config.DB.Save(&template).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to update notification template"})
+	}
+	utils.ForgetNotificationPattern(template.ID)
+
+	return c.JSON(fiber.Map{
+		"message":  "Notification template updated successfully",
+		"template": template,
+	})
+}
+
+// Delete Notification Template
+func DeleteNotificationTemplate(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+	id := c.Params("id")
+
+	var template models.NotificationTemplate
+	if err := config.DB.Where("id = ? AND user_id = ?", id, userID).First(&template).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Notification template not found"})
+	}
+	if err := config.DB.Delete(&template).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to delete notification template"})
+	}
+	utils.ForgetNotificationPattern(template.ID)
+
+	return c.JSON(fiber.Map{
+		"message": "Notification template deleted successfully",
+	})
+}
+
+// Test Notification Template: coba pattern (atau template tersimpan) ke contoh pesan tanpa menyimpan apapun
+func TestNotificationTemplate(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	req := new(TestNotificationTemplateRequest)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request"})
+	}
+	if strings.TrimSpace(req.Text) == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "Text is required"})
+	}
+
+	var template models.NotificationTemplate
+	if req.TemplateID != 0 {
+		if err := config.DB.Where("id = ? AND (user_id = ? OR user_id = 0)", req.TemplateID, userID).First(&template).Error; err != nil {
+			return c.Status(404).JSON(fiber.Map{"error": "Notification template not found"})
+		}
+	} else {
+		if req.Bank == "" {
+			req.Bank = "Test"
+		}
+		if req.Name == "" {
+			req.Name = "Test"
+		}
+		if status, msg := applyNotificationTemplate(&template, &req.NotificationTemplateRequest); status != 0 {
+			return c.Status(status).JSON(fiber.Map{"error": msg})
+		}
+	}
+
+	match, err := utils.MatchNotification(req.Text, time.Now(), []models.NotificationTemplate{template})
+	if err != nil {
+		return c.JSON(fiber.Map{"matched": false})
+	}
+
+	return c.JSON(fiber.Map{
+		"matched": true,
+		"result":  match,
+	})
+}
+
+// Ingest Notifications: JSON berisi satu pesan (text) atau banyak (messages), atau multipart
+// dengan file .eml/mbox. Pesan yang cocok dengan template jadi draft transaksi untuk di-review.
+func IngestNotifications(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	var messages []NotificationMessage
+	var account *models.Account
+	if strings.HasPrefix(c.Get(fiber.HeaderContentType), fiber.MIMEMultipartForm) {
+		var status int
+		var msg string
+		if account, status, msg = importAccount(c, userID); status != 0 {
+			return c.Status(status).JSON(fiber.Map{"error": msg})
+		}
+
+		data, status, msg := readImportFile(c)
+		if status != 0 {
+			return c.Status(status).JSON(fiber.Map{"error": msg})
+		}
+		emails, err := utils.ParseEmails(data)
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "Invalid email file: " + err.Error()})
+		}
+		for _, email := range emails {
+			message := NotificationMessage{Text: email.Subject + "\n" + email.Body, Sender: email.From, Source: "email"}
+			if !email.Date.IsZero() {
+				message.ReceivedAt = email.Date.Format(time.RFC3339)
+			}
+			messages = append(messages, message)
+		}
+	} else {
+		req := new(IngestNotificationRequest)
+		if err := c.BodyParser(req); err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "Invalid request"})
+		}
+		messages = req.Messages
+		if strings.TrimSpace(req.Text) != "" {
+			messages = append([]NotificationMessage{req.NotificationMessage}, messages...)
+		}
+		if req.AccountID != 0 {
+			found, err := findUserAccount(userID, req.AccountID)
+			if err != nil {
+				return c.Status(404).JSON(fiber.Map{"error": "Account not found"})
+			}
+			if found.Archived {
+				return c.Status(400).JSON(fiber.Map{"error": "Account is archived"})
+			}
+			account = found
+		}
+	}
+
+	if len(messages) == 0 {
+		return c.Status(400).JSON(fiber.Map{"error": "At least one message is required"})
+	}
+	if len(messages) > maxIngestMessages {
+		return c.Status(400).JSON(fiber.Map{"error": fmt.Sprintf("Too many messages, the limit is %d per request", maxIngestMessages)})
+	}
+	for i, message := range messages {
+		if message.ReceivedAt == "" {
+			continue
+		}
+		if _, err := time.Parse(time.RFC3339, message.ReceivedAt); err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": fmt.Sprintf("Invalid received_at in message %d. Use RFC3339", i+1)})
+		}
+	}
+
+	results, drafts, err := ingestNotifications(userID, account, messages)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to process messages"})
+	}
+
+	created, duplicates, unmatched := 0, 0, 0
+	for _, result := range results {
+		switch result.Status {
+		case "created":
+			created++
+		case "duplicate":
+			duplicates++
+		default:
+			unmatched++
+		}
+	}
+
+	return c.Status(201).JSON(fiber.Map{
+		"message": "Messages processed",
+		"drafts":  drafts,
+		"results": results,
+		"summary": fiber.Map{
+			"total":      len(results),
+			"created":    created,
+			"duplicates": duplicates,
+			"unmatched":  unmatched,
+		},
+	})
+}
+
+// Get Transaction Drafts (default status pending)
+func GetTransactionDrafts(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	status := c.Query("status", "pending")
+	if status != "pending" && status != "accepted" && status != "rejected" && status != "all" {
+		return c.Status(400).JSON(fiber.Map{"error": "Status must be pending, accepted, rejected or all"})
+	}
+
+	var drafts []models.TransactionDraft
+	query := config.DB.Where("user_id = ?", userID)
+	if status != "all" {
+		query = query.Where("status = ?", status)
+	}
+	if err := query.Preload("Account").Preload("Category").Preload("Payee").
+		Order("date DESC, id DESC").Find(&drafts).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch drafts"})
+	}
+
+	return c.JSON(fiber.Map{
+		"drafts": drafts,
+	})
+}
+
+// Update Transaction Draft (hanya draft pending)
+func UpdateTransactionDraft(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	draft, status, msg := findPendingDraft(userID, c.Params("id"))
+	if status != 0 {
+		return c.Status(status).JSON(fiber.Map{"error": msg})
+	}
+
+	req := new(DraftRequest)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request"})
+	}
+	if status, msg := applyDraftUpdate(userID, draft, req); status != 0 {
+		return c.Status(status).JSON(fiber.Map{"error": msg})
+	}
+
+	if err := config.DB.Save(draft).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to update draft"})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Draft updated successfully",
+		"draft":   draft,
+	})
+}
+
+// Accept Transaction Draft: buat transaksi dari draft (body optional, sama seperti update
+// plus tags). Validasi sama dengan CreateTransaction.
+func AcceptTransactionDraft(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	draft, status, msg := findPendingDraft(userID, c.Params("id"))
+	if status != 0 {
+		return c.Status(status).JSON(fiber.Map{"error": msg})
+	}
+
+	req := new(DraftRequest)
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(req); err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "Invalid request"})
+		}
+	}
+	if status, msg := applyDraftUpdate(userID, draft, req); status != 0 {
+		return c.Status(status).JSON(fiber.Map{"error": msg})
+	}
+
+	transactionReq := TransactionRequest{
+		Amount:      draft.Amount,
+		Currency:    draft.Currency,
+		Description: draft.Description,
+		Date:        draft.Date.Format("2006-01-02"),
+		Payee:       draft.Merchant,
+		Tags:        req.Tags,
+	}
+	if draft.AccountID != nil {
+		transactionReq.AccountID = *draft.AccountID
+	}
+	if draft.CategoryID != nil {
+		transactionReq.CategoryID = *draft.CategoryID
+	}
+	if draft.PayeeID != nil {
+		transactionReq.PayeeID = *draft.PayeeID
+	}
+
+	transaction, payee, status, msg := buildTransaction(userID, &transactionReq)
+	if status != 0 {
+		return c.Status(status).JSON(fiber.Map{"error": msg})
+	}
+	// Category bisa berasal dari default payee, type-nya tetap harus sesuai draft
+	if category, err := findUserCategory(userID, *transaction.CategoryID); err != nil || category.Type != draft.Kind {
+		return c.Status(400).JSON(fiber.Map{"error": "Category type does not match the draft (" + draft.Kind + ")"})
+	}
+	transaction.ImportHash = draft.MessageHash
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		// Status diubah lebih dulu dengan syarat masih pending: accept kedua yang berjalan
+		// bersamaan (misalnya double tap) menunggu lock baris draft lalu mendapat 0 rows affected
+		result := tx.Model(&models.TransactionDraft{}).Where("id = ? AND status = ?", draft.ID, "pending").
+			Updates(map[string]interface{}{"status": "accepted"})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errDraftChanged
+		}
+		if err := createTransaction(tx, userID, transaction, payee, req.Tags); err != nil {
+			return err
+		}
+		draft.Status = "accepted"
+		draft.TransactionID = &transaction.ID
+		draft.CategoryID = transaction.CategoryID
+		draft.PayeeID = transaction.PayeeID
+		return tx.Save(draft).Error
+	})
+	if errors.Is(err, errDraftChanged) {
+		return c.Status(409).JSON(fiber.Map{"error": "Draft is no longer pending"})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to create transaction"})
+	}
+
+	config.DB.Preload("Category").Preload("Account").Preload("Payee").Preload("Tags").First(transaction, transaction.ID)
+
+	return c.Status(201).JSON(fiber.Map{
+		"message":     "Draft accepted successfully",
+		"transaction": transaction,
+		"draft":       draft,
+	})
+}
+
+// Reject Transaction Draft: draft tetap disimpan supaya pesan yang sama tidak masuk lagi
+func RejectTransactionDraft(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	draft, status, msg := findPendingDraft(userID, c.Params("id"))
+	if status != 0 {
+		return c.Status(status).JSON(fiber.Map{"error": msg})
+	}
+
+	result := config.DB.Model(&models.TransactionDraft{}).Where("id = ? AND status = ?", draft.ID, "pending").
+		Update("status", "rejected")
+	if result.Error != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to reject draft"})
+	}
+	if result.RowsAffected == 0 {
+		return c.Status(409).JSON(fiber.Map{"error": "Draft is no longer pending"})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Draft rejected successfully",
+	})
+}
+
+// Delete Transaction Draft (transaksi yang sudah dibuat dari draft tidak ikut terhapus)
+func DeleteTransactionDraft(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+	id := c.Params("id")
+
+	result := config.DB.Where("id = ? AND user_id = ?", id, userID).Delete(&models.TransactionDraft{})
+	if result.Error != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to delete draft"})
+	}
+	if result.RowsAffected == 0 {
+		return c.Status(404).JSON(fiber.Map{"error": "Draft not found"})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Draft deleted successfully",
+	})
+}
@@ -0,0 +1,592 @@
+package controllers
+
+import (
+	"finance-tracker-backend/config"
+	"finance-tracker-backend/models"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+type PayeeRequest struct {
+	Name              string   `json:"name"`
+	DefaultCategoryID *uint    `json:"default_category_id"` // 0 = hapus default category
+	Aliases           []string `json:"aliases"`             // saat update, array kosong menghapus semua alias
+}
+
+type MergePayeeRequest struct {
+	TargetID uint `json:"target_id"` // payee tujuan, payee di URL dihapus setelah digabung
+}
+
+type PayeeSpending struct {
+	PayeeID      uint         `json:"payee_id"`
+	Name         string       `json:"name"`
+	TotalExpense models.Money `json:"total_expense"`
+	TotalIncome  models.Money `json:"total_income"`
+	Count        int64        `json:"transaction_count"`
+}
+
+type PayeeMonth struct {
+	Month   string       `json:"month"` // Format: "2024-01"
+	Expense models.Money `json:"expense"`
+	Income  models.Money `json:"income"`
+}
+
+// Alias disimpan lowercase dengan spasi dirapikan supaya pencocokan tidak peka huruf besar/kecil
+func normalizePayeeAlias(alias string) string {
+	return strings.Join(strings.Fields(strings.ToLower(alias)), " ")
+}
+
+// Cari payee milik user
+func findUserPayee(userID uint, id interface{}) (*models.Payee, error) {
+	var payee models.Payee
+	if err := config.DB.Where("id = ? AND user_id = ?", id, userID).First(&payee).Error; err != nil {
+		return nil, err
+	}
+	return &payee, nil
+}
+
+// Tebak payee dari description: nama payee atau alias terpanjang yang muncul di description
+func matchPayee(userID uint, description string) *models.Payee {
+	text := normalizePayeeAlias(description)
+	if text == "" {
+		return nil
+	}
+
+	var payees []models.Payee
+	if err := config.DB.Where("user_id = ?", userID).Preload("Aliases").Find(&payees).Error; err != nil {
+		return nil
+	}
+	return bestPayeeMatch(payees, text)
+}
+
+// Payee dengan nama/alias terpanjang yang muncul di text (sudah dinormalisasi).
+// Dipisah dari matchPayee supaya import bisa memakai daftar payee yang sama untuk banyak baris.
+func bestPayeeMatch(payees []models.Payee, text string) *models.Payee {
+	var best *models.Payee
+	bestLen := 0
+	for i := range payees {
+		patterns := []string{normalizePayeeAlias(payees[i].Name)}
+		for _, alias := range payees[i].Aliases {
+			patterns = append(patterns, alias.Pattern)
+		}
+		for _, pattern := range patterns {
+			if len(pattern) > bestLen && strings.Contains(text, pattern) {
+				best, bestLen = &payees[i], len(pattern)
+			}
+		}
+	}
+	return best
+}
+
+// Cari payee berdasarkan nama atau alias persis (nil kalau belum ada)
+func findPayeeByName(userID uint, name string) *models.Payee {
+	name = strings.TrimSpace(name)
+
+	var payee models.Payee
+	err := config.DB.Where("user_id = ? AND (name = ? OR id IN (SELECT payee_id FROM payee_aliases WHERE user_id = ? AND pattern = ?))",
+		userID, name, userID, normalizePayeeAlias(name)).First(&payee).Error
+	if err != nil {
+		return nil
+	}
+	return &payee
+}
+
+// Sama seperti findPayeeByName, tapi mencari di payee (beserta Aliases) yang sudah di-load
+func matchPayeeName(payees []models.Payee, name string) *models.Payee {
+	name = strings.TrimSpace(name)
+	pattern := normalizePayeeAlias(name)
+	for i := range payees {
+		if strings.EqualFold(payees[i].Name, name) {
+			return &payees[i]
+		}
+		for _, alias := range payees[i].Aliases {
+			if alias.Pattern == pattern {
+				return &payees[i]
+			}
+		}
+	}
+	return nil
+}
+
+// Payee dari request transaksi: payee_id, nama payee (dibuat kalau belum ada, lihat createPayeeIfNew),
+// atau ditebak dari description
+func requestPayee(userID uint, payeeID uint, name, description string) (*models.Payee, int, string) {
+	if payeeID != 0 {
+		payee, err := findUserPayee(userID, payeeID)
+		if err != nil {
+			return nil, 404, "Payee not found"
+		}
+		return payee, 0, ""
+	}
+	if name = strings.TrimSpace(name); name != "" {
+		if len(name) > 100 {
+			return nil, 400, "Payee name must be at most 100 characters"
+		}
+		if payee := findPayeeByName(userID, name); payee != nil {
+			return payee, 0, ""
+		}
+		return &models.Payee{UserID: userID, Name: name}, 0, ""
+	}
+	if description != "" {
+		return matchPayee(userID, description), 0, ""
+	}
+	return nil, 0, ""
+}
+
+// Simpan payee baru dari requestPayee (ID masih 0)
+func createPayeeIfNew(db *gorm.DB, payee *models.Payee) error {
+	if payee == nil || payee.ID != 0 {
+		return nil
+	}
+	return db.Create(payee).Error
+}
+
+// Validasi daftar alias: tidak kosong dan belum dipakai payee lain
+func buildPayeeAliases(userID, payeeID uint, aliases []string) ([]models.PayeeAlias, int, string) {
+	result := make([]models.PayeeAlias, 0, len(aliases))
+	seen := make(map[string]bool, len(aliases))
+	for _, alias := range aliases {
+		pattern := normalizePayeeAlias(alias)
+		if pattern == "" || seen[pattern] {
+			continue
+		}
+		if len(pattern) > 100 {
+			return nil, 400, "Alias must be at most 100 characters"
+		}
+		seen[pattern] = true
+
+		var count int64
+		config.DB.Model(&models.PayeeAlias{}).Where("user_id = ? AND pattern = ? AND payee_id <> ?", userID, pattern, payeeID).Count(&count)
+		if count > 0 {
+			return nil, 409, "Alias '" + pattern + "' already belongs to another payee"
+		}
+		result = append(result, models.PayeeAlias{UserID: userID, PayeeID: payeeID, Pattern: pattern})
+	}
+	return result, 0, ""
+}
+
+// Get All Payees
+func GetPayees(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	var payees []models.Payee
+	query := config.DB.Where("user_id = ?", userID)
+
+	// Optional filter by nama
+	if name := c.Query("name"); name != "" {
+		query = query.Where("name LIKE ?", "%"+name+"%")
+	}
+
+	if err := query.Preload("Aliases").Preload("DefaultCategory").Order("name ASC").Find(&payees).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch payees"})
+	}
+
+	return c.JSON(fiber.Map{
+		"payees": payees,
+	})
+}
+
+// Get Single Payee
+func GetPayee(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	var payee models.Payee
+	if err := config.DB.Where("id = ? AND user_id = ?", c.Params("id"), userID).Preload("Aliases").Preload("DefaultCategory").First(&payee).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Payee not found"})
+	}
+
+	return c.JSON(fiber.Map{
+		"payee": payee,
+	})
+}
+
+// Create Payee
+func CreatePayee(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	req := new(PayeeRequest)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request"})
+	}
+
+	// Validasi
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "Name is required"})
+	}
+
+	var count int64
+	config.DB.Model(&models.Payee{}).Where("user_id = ? AND name = ?", userID, name).Count(&count)
+	if count > 0 {
+		return c.Status(409).JSON(fiber.Map{"error": "Payee already exists"})
+	}
+
+	payee := models.Payee{
+		UserID: userID,
+		Name:   name,
+	}
+	if req.DefaultCategoryID != nil && *req.DefaultCategoryID != 0 {
+		category, err := findUserCategory(userID, *req.DefaultCategoryID)
+		if err != nil {
+			return c.Status(404).JSON(fiber.Map{"error": "Category not found"})
+		}
+		payee.DefaultCategoryID = &category.ID
+	}
+
+	aliases, status, msg := buildPayeeAliases(userID, 0, req.Aliases)
+	if status != 0 {
+		return c.Status(status).JSON(fiber.Map{"error": msg})
+	}
+	payee.Aliases = aliases
+
+	// Alias ikut dibuat dalam DB transaction yang sama
+	if err := config.DB.Create(&payee).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to create payee"})
+	}
+
+	config.DB.Preload("Aliases").Preload("DefaultCategory").First(&payee, payee.ID)
+
+	return c.Status(201).JSON(fiber.Map{
+		"message": "Payee created successfully",
+		"payee":   payee,
+	})
+}
+
+// Update Payee
+func UpdatePayee(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	payee, err := findUserPayee(userID, c.Params("id"))
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Payee not found"})
+	}
+
+	req := new(PayeeRequest)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request"})
+	}
+
+	// Update fields
+	if name := strings.TrimSpace(req.Name); name != "" && name != payee.Name {
+		var count int64
+		config.DB.Model(&models.Payee{}).Where("user_id = ? AND name = ? AND id <> ?", userID, name, payee.ID).Count(&count)
+		if count > 0 {
+			return c.Status(409).JSON(fiber.Map{"error": "Another payee already has this name, merge them instead"})
+		}
+		payee.Name = name
+	}
+	if req.DefaultCategoryID != nil {
+		if *req.DefaultCategoryID == 0 {
+			payee.DefaultCategoryID = nil
+		} else {
+			category, err := findUserCategory(userID, *req.DefaultCategoryID)
+			if err != nil {
+				return c.Status(404).JSON(fiber.Map{"error": "Category not found"})
+			}
+			payee.DefaultCategoryID = &category.ID
+		}
+	}
+
+	var aliases []models.PayeeAlias
+	if req.Aliases != nil {
+		var status int
+		var msg string
+		aliases, status, msg = buildPayeeAliases(userID, payee.ID, req.Aliases)
+		if status != 0 {
+			return c.Status(status).JSON(fiber.Map{"error": msg})
+		}
+	}
+
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("DefaultCategory", "Aliases").Save(payee).Error; err != nil {
+			return err
+		}
+		if req.Aliases == nil {
+			return nil
+		}
+		if err := tx.Where("payee_id = ?", payee.ID).Delete(&models.PayeeAlias{}).Error; err != nil {
+			return err
+		}
+		if len(aliases) == 0 {
+			return nil
+		}
+		return tx.Create(&aliases).Error
+	})
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to update payee"})
+	}
+
+	config.DB.Preload("Aliases").Preload("DefaultCategory").First(payee, payee.ID)
+
+	return c.JSON(fiber.Map{
+		"message": "Payee updated successfully",
+		"payee":   payee,
+	})
+}
+
+// Delete Payee (transaksinya tetap ada, hanya payee-nya yang dilepas)
+func DeletePayee(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	payee, err := findUserPayee(userID, c.Params("id"))
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Payee not found"})
+	}
+
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Model(&models.Transaction{}).Where("payee_id = ?", payee.ID).Update("payee_id", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("payee_id = ?", payee.ID).Delete(&models.PayeeAlias{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(payee).Error
+	})
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to delete payee"})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Payee deleted successfully",
+	})
+}
+
+// Merge Payee: transaksi dan alias payee :id dipindah ke target_id, nama payee :id jadi alias target
+func MergePayee(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	source, err := findUserPayee(userID, c.Params("id"))
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Payee not found"})
+	}
+
+	req := new(MergePayeeRequest)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request"})
+	}
+	if req.TargetID == 0 || req.TargetID == source.ID {
+		return c.Status(400).JSON(fiber.Map{"error": "A different target payee is required"})
+	}
+
+	target, err := findUserPayee(userID, req.TargetID)
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Target payee not found"})
+	}
+
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Model(&models.Transaction{}).Where("payee_id = ?", source.ID).Update("payee_id", target.ID).Error; err != nil {
+			return err
+		}
+
+		var existing []string
+		if err := tx.Model(&models.PayeeAlias{}).Where("payee_id = ?", target.ID).Pluck("pattern", &existing).Error; err != nil {
+			return err
+		}
+		known := map[string]bool{normalizePayeeAlias(target.Name): true}
+		for _, pattern := range existing {
+			known[pattern] = true
+		}
+
+		var moved []models.PayeeAlias
+		if err := tx.Where("payee_id = ?", source.ID).Find(&moved).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("payee_id = ?", source.ID).Delete(&models.PayeeAlias{}).Error; err != nil {
+			return err
+		}
+
+		var aliases []models.PayeeAlias
+		patterns := []string{normalizePayeeAlias(source.Name)}
+		for _, alias := range moved {
+			patterns = append(patterns, alias.Pattern)
+		}
+		for _, pattern := range patterns {
+			if pattern == "" || known[pattern] {
+				continue
+			}
+			known[pattern] = true
+			aliases = append(aliases, models.PayeeAlias{UserID: userID, PayeeID: target.ID, Pattern: pattern})
+		}
+		if len(aliases) > 0 {
+			if err := tx.Create(&aliases).Error; err != nil {
+				return err
+			}
+		}
+
+		if target.DefaultCategoryID == nil && source.DefaultCategoryID != nil {
+			if err := tx.Model(target).Update("default_category_id", *source.DefaultCategoryID).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Delete(source).Error
+	})
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to merge payees"})
+	}
+
+	config.DB.Preload("Aliases").Preload("DefaultCategory").First(target, target.ID)
+
+	return c.JSON(fiber.Map{
+		"message": "Payees merged successfully",
+		"payee":   target,
+	})
+}
+
+// Jumlah transaksi per payee, jenis (income/expense), currency dan hari.
+// Transaksi split dihitung per baris split seperti di balanceRows.
+type payeeRow struct {
+	PayeeID  uint
+	Kind     string
+	Currency string
+	Day      time.Time
+	Amount   models.Money
+	Count    int64
+}
+
+func payeeRows(userID uint, c *fiber.Ctx, payeeID uint) ([]payeeRow, error) {
+	var rows []payeeRow
+	query := config.DB.Table("transactions").
+		Select("transactions.payee_id, COALESCE(split_categories.type, categories.type, '') AS kind, "+
+			"transactions.currency, DATE(transactions.date) AS day, SUM(COALESCE(transaction_splits.amount, transactions.amount)) AS amount, "+
+			"COUNT(DISTINCT transactions.id) AS count").
+		Joins("LEFT JOIN transaction_splits ON transaction_splits.transaction_id = transactions.id").
+		Joins("LEFT JOIN categories ON transactions.category_id = categories.id").
+		Joins("LEFT JOIN categories AS split_categories ON transaction_splits.category_id = split_categories.id").
+		Where("transactions.user_id = ? AND transactions.deleted_at IS NULL AND transactions.payee_id IS NOT NULL AND transactions.transfer_id IS NULL", userID)
+
+	if payeeID != 0 {
+		query = query.Where("transactions.payee_id = ?", payeeID)
+	}
+	if startDate := c.Query("start_date"); startDate != "" {
+		query = query.Where("transactions.date >= ?", startDate)
+	}
+	if endDate := c.Query("end_date"); endDate != "" {
+		query = query.Where("transactions.date <= ?", endDate)
+	}
+
+	err := query.Group("transactions.payee_id, kind, transactions.currency, day").Scan(&rows).Error
+	return rows, err
+}
+
+// Get Payee Spending Summary: total income/expense per payee dalam base currency,
+// diurutkan dari pengeluaran terbesar (?start_date=&end_date=)
+func GetPayeeSummary(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	baseCurrency := userBaseCurrency(userID)
+	rates, err := loadRateTable(userID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to load exchange rates"})
+	}
+
+	rows, err := payeeRows(userID, c, 0)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to calculate payee summary"})
+	}
+
+	var payees []models.Payee
+	if err := config.DB.Where("user_id = ?", userID).Find(&payees).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch payees"})
+	}
+	summaries := make(map[uint]*PayeeSpending, len(payees))
+	for _, payee := range payees {
+		summaries[payee.ID] = &PayeeSpending{PayeeID: payee.ID, Name: payee.Name}
+	}
+
+	for _, row := range rows {
+		summary, ok := summaries[row.PayeeID]
+		if !ok {
+			continue
+		}
+		converted, err := rates.Convert(row.Amount, row.Currency, baseCurrency, row.Day)
+		if err != nil {
+			return c.Status(422).JSON(fiber.Map{"error": err.Error()})
+		}
+		switch row.Kind {
+		case "income":
+			summary.TotalIncome += converted
+		case "expense":
+			summary.TotalExpense += converted
+		}
+		summary.Count += row.Count
+	}
+
+	result := make([]PayeeSpending, 0, len(summaries))
+	for _, summary := range summaries {
+		if summary.Count > 0 {
+			result = append(result, *summary)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].TotalExpense != result[j].TotalExpense {
+			return result[i].TotalExpense > result[j].TotalExpense
+		}
+		return result[i].Name < result[j].Name
+	})
+
+	return c.JSON(fiber.Map{
+		"payees":        result,
+		"base_currency": baseCurrency,
+	})
+}
+
+// Get Spending Summary of a Payee: total dan rincian per bulan dalam base currency
+func GetPayeeSpending(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	payee, err := findUserPayee(userID, c.Params("id"))
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Payee not found"})
+	}
+
+	baseCurrency := userBaseCurrency(userID)
+	rates, err := loadRateTable(userID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to load exchange rates"})
+	}
+
+	rows, err := payeeRows(userID, c, payee.ID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to calculate payee summary"})
+	}
+
+	summary := PayeeSpending{PayeeID: payee.ID, Name: payee.Name}
+	months := map[string]*PayeeMonth{}
+	for _, row := range rows {
+		converted, err := rates.Convert(row.Amount, row.Currency, baseCurrency, row.Day)
+		if err != nil {
+			return c.Status(422).JSON(fiber.Map{"error": err.Error()})
+		}
+		key := row.Day.Format("2006-01")
+		month, ok := months[key]
+		if !ok {
+			month = &PayeeMonth{Month: key}
+			months[key] = month
+		}
+		switch row.Kind {
+		case "income":
+			summary.TotalIncome += converted
+			month.Income += converted
+		case "expense":
+			summary.TotalExpense += converted
+			month.Expense += converted
+		}
+		summary.Count += row.Count
+	}
+
+	monthly := make([]PayeeMonth, 0, len(months))
+	for _, month := range months {
+		monthly = append(monthly, *month)
+	}
+	sort.Slice(monthly, func(i, j int) bool { return monthly[i].Month < monthly[j].Month })
+
+	return c.JSON(fiber.Map{
+		"summary":       summary,
+		"monthly":       monthly,
+		"base_currency": baseCurrency,
+	})
+}
@@ -0,0 +1,373 @@
+package controllers
+
+import (
+	"errors"
+	"finance-tracker-backend/config"
+	"finance-tracker-backend/models"
+	"finance-tracker-backend/scheduler"
+	"finance-tracker-backend/utils"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type RecurringRequest struct {
+	AccountID   uint         `json:"account_id"`
+	CategoryID  uint         `json:"category_id"`
+	Amount      models.Money `json:"amount"`
+	Currency    string       `json:"currency"`
+	Description string       `json:"description"`
+	Frequency   string       `json:"frequency"` // daily, weekly, monthly, yearly
+	Interval    int          `json:"interval"`
+	Weekdays    []string     `json:"weekdays"`  // ["MO", "FR"]
+	MonthDay    int          `json:"month_day"` // -1 = akhir bulan
+	SetPos      int          `json:"set_pos"`   // ke-n weekday (1..5, -1 = terakhir)
+	StartDate   string       `json:"start_date"`
+	EndDate     string       `json:"end_date"`
+	Count       int          `json:"count"`
+	RRule       string       `json:"rrule"` // alternatif: "FREQ=MONTHLY;BYDAY=-1FR"
+	Active      *bool        `json:"active"`
+}
+
+// Maksimal occurrence yang bisa di-preview sekaligus
+const maxRecurringPreview = 100
+
+func todayUTC() time.Time {
+	now := time.Now()
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// Terapkan field aturan pengulangan dari request ke template, lalu validasi.
+// Mengembalikan true kalau aturan berubah.
+func applyRecurrence(template *models.RecurringTransaction, req *RecurringRequest) (bool, error) {
+	changed := false
+
+	if req.StartDate != "" {
+		start, err := time.Parse("2006-01-02", req.StartDate)
+		if err != nil {
+			return false, errors.New("Invalid start date format. Use YYYY-MM-DD")
+		}
+		template.StartDate = start
+		changed = true
+	}
+	if req.EndDate != "" {
+		end, err := time.Parse("2006-01-02", req.EndDate)
+		if err != nil {
+			return false, errors.New("Invalid end date format. Use YYYY-MM-DD")
+		}
+		template.EndDate = &end
+		changed = true
+	}
+
+	if req.RRule != "" {
+		rule, err := utils.ParseRRule(req.RRule, template.StartDate)
+		if err != nil {
+			return false, errors.New("Invalid rrule: " + err.Error())
+		}
+		template.Frequency = rule.Frequency
+		template.Interval = rule.Interval
+		template.Weekdays = utils.FormatWeekdays(rule.Weekdays)
+		template.MonthDay = rule.MonthDay
+		template.SetPos = rule.SetPos
+		// COUNT dan UNTIL selalu diambil dari rrule, supaya rule tanpa keduanya menghapus batas lama.
+		// end_date di request yang sama tetap dipakai kalau rrule tidak punya UNTIL.
+		template.Count = rule.Count
+		if rule.Until != nil || req.EndDate == "" {
+			template.EndDate = rule.Until
+		}
+		changed = true
+	} else {
+		if req.Frequency != "" {
+			template.Frequency = req.Frequency
+			changed = true
+		}
+		if req.Interval != 0 {
+			template.Interval = req.Interval
+			changed = true
+		}
+		if req.Weekdays != nil {
+			weekdays, err := utils.ParseWeekdays(req.Weekdays)
+			if err != nil {
+				return false, err
+			}
+			template.Weekdays = utils.FormatWeekdays(weekdays)
+			changed = true
+		}
+		if req.MonthDay != 0 {
+			template.MonthDay = req.MonthDay
+			changed = true
+		}
+		if req.SetPos != 0 {
+			template.SetPos = req.SetPos
+			changed = true
+		}
+		if req.Count != 0 {
+			template.Count = req.Count
+			changed = true
+		}
+	}
+
+	rule, err := utils.RecurrenceOf(template)
+	if err != nil {
+		return false, err
+	}
+	template.Interval = rule.Interval
+	template.RRule = rule.RRule()
+	return changed, nil
+}
+
+// Get All Recurring Transactions
+func GetRecurringTransactions(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	var templates []models.RecurringTransaction
+	query := config.DB.Where("user_id = ?", userID)
+
+	if active := c.Query("active"); active != "" {
+		query = query.Where("active = ?", active == "true")
+	}
+
+	if err := query.Preload("Category").Preload("Account").Order("next_date ASC").Find(&templates).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch recurring transactions"})
+	}
+
+	return c.JSON(fiber.Map{
+		"recurring": templates,
+	})
+}
+
+// Get Single Recurring Transaction
+func GetRecurringTransaction(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+	id := c.Params("id")
+
+	var template models.RecurringTransaction
+	if err := config.DB.Where("id = ? AND user_id = ?", id, userID).Preload("Category").Preload("Account").First(&template).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Recurring transaction not found"})
+	}
+
+	return c.JSON(fiber.Map{
+		"recurring": template,
+	})
+}
+
+// Create Recurring Transaction
+func CreateRecurringTransaction(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	req := new(RecurringRequest)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request"})
+	}
+
+	// Validasi
+	if req.CategoryID == 0 || req.Amount == 0 || req.StartDate == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "Category, amount, and start date are required"})
+	}
+	if req.Frequency == "" && req.RRule == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "Frequency or rrule is required"})
+	}
+
+	category, err := findUserCategory(userID, req.CategoryID)
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Category not found"})
+	}
+
+	template := models.RecurringTransaction{
+		UserID:      userID,
+		CategoryID:  category.ID,
+		Description: req.Description,
+		Active:      true,
+	}
+	if req.Active != nil {
+		template.Active = *req.Active
+	}
+
+	// Currency default mengikuti account, kalau tidak ada pakai base currency user
+	if req.AccountID != 0 {
+		account, err := findUserAccount(userID, req.AccountID)
+		if err != nil {
+			return c.Status(404).JSON(fiber.Map{"error": "Account not found"})
+		}
+		template.AccountID = &account.ID
+		template.Currency = account.Currency
+	}
+	if req.Currency != "" {
+		code, ok := utils.NormalizeCurrency(req.Currency)
+		if !ok {
+			return c.Status(400).JSON(fiber.Map{"error": "Currency must be a 3-letter ISO code"})
+		}
+		template.Currency = code
+	}
+	if template.Currency == "" {
+		template.Currency = userBaseCurrency(userID)
+	}
+	template.Amount = req.Amount.Round(template.Currency)
+
+	if _, err := applyRecurrence(&template, req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	// Occurrence pertama dihitung dari start date, termasuk yang sudah lewat
+	rule, _ := utils.RecurrenceOf(&template)
+	template.NextDate = scheduler.NextOccurrence(rule, template.StartDate)
+
+	if err := config.DB.Create(&template).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to create recurring transaction"})
+	}
+
+	// Posting occurrence yang sudah jatuh tempo tanpa menunggu scheduler berikutnya
+	posted, _ := scheduler.MaterializeRecurring(template.ID, todayUTC())
+
+	config.DB.Preload("Category").Preload("Account").First(&template, template.ID)
+
+	return c.Status(201).JSON(fiber.Map{
+		"message":   "Recurring transaction created successfully",
+		"recurring": template,
+		"posted":    posted,
+	})
+}
+
+// Update Recurring Transaction
+func UpdateRecurringTransaction(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+	id := c.Params("id")
+
+	var template models.RecurringTransaction
+	if err := config.DB.Where("id = ? AND user_id = ?", id, userID).First(&template).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Recurring transaction not found"})
+	}
+
+	req := new(RecurringRequest)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request"})
+	}
+
+	// Update fields
+	if req.CategoryID != 0 {
+		category, err := findUserCategory(userID, req.CategoryID)
+		if err != nil {
+			return c.Status(404).JSON(fiber.Map{"error": "Category not found"})
+		}
+		template.CategoryID = category.ID
+	}
+	if req.AccountID != 0 {
+		account, err := findUserAccount(userID, req.AccountID)
+		if err != nil {
+			return c.Status(404).JSON(fiber.Map{"error": "Account not found"})
+		}
+		template.AccountID = &account.ID
+	}
+	if req.Currency != "" {
+		code, ok := utils.NormalizeCurrency(req.Currency)
+		if !ok {
+			return c.Status(400).JSON(fiber.Map{"error": "Currency must be a 3-letter ISO code"})
+		}
+		template.Currency = code
+	}
+	if req.Amount != 0 {
+		template.Amount = req.Amount
+	}
+	template.Amount = template.Amount.Round(template.Currency)
+	if req.Description != "" {
+		template.Description = req.Description
+	}
+	resumed := false
+	if req.Active != nil {
+		resumed = *req.Active && !template.Active
+		template.Active = *req.Active
+	}
+
+	changed, err := applyRecurrence(&template, req)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	// Aturan berubah atau template diaktifkan lagi: jadwal berlaku mulai hari ini,
+	// occurrence lama (atau selama di-pause) tidak diposting
+	if changed || resumed {
+		from := todayUTC()
+		if template.StartDate.After(from) {
+			from = template.StartDate
+		}
+		rule, _ := utils.RecurrenceOf(&template)
+		template.NextDate = scheduler.NextOccurrence(rule, from)
+	}
+
+	if err := config.DB.Omit("Category", "Account").Save(&template).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to update recurring transaction"})
+	}
+
+	posted, _ := scheduler.MaterializeRecurring(template.ID, todayUTC())
+
+	config.DB.Preload("Category").Preload("Account").First(&template, template.ID)
+
+	return c.JSON(fiber.Map{
+		"message":   "Recurring transaction updated successfully",
+		"recurring": template,
+		"posted":    posted,
+	})
+}
+
+// Delete Recurring Transaction (transaksi yang sudah diposting tetap ada)
+func DeleteRecurringTransaction(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+	id := c.Params("id")
+
+	var template models.RecurringTransaction
+	if err := config.DB.Where("id = ? AND user_id = ?", id, userID).First(&template).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Recurring transaction not found"})
+	}
+
+	if err := config.DB.Delete(&template).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to delete recurring transaction"})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Recurring transaction deleted successfully",
+	})
+}
+
+// Preview N occurrence berikutnya dari template (?count=N, default 5)
+func PreviewRecurringTransaction(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+	id := c.Params("id")
+
+	var template models.RecurringTransaction
+	if err := config.DB.Where("id = ? AND user_id = ?", id, userID).First(&template).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Recurring transaction not found"})
+	}
+
+	count, _ := strconv.Atoi(c.Query("count", "5"))
+	if count < 1 {
+		count = 5
+	}
+	if count > maxRecurringPreview {
+		count = maxRecurringPreview
+	}
+
+	rule, err := utils.RecurrenceOf(&template)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	// Mulai dari occurrence yang belum diposting
+	from := todayUTC()
+	if template.NextDate != nil {
+		from = *template.NextDate
+	}
+
+	occurrences := make([]string, 0, count)
+	for _, day := range rule.Next(from, count) {
+		occurrences = append(occurrences, day.Format("2006-01-02"))
+	}
+
+	return c.JSON(fiber.Map{
+		"recurring_id": template.ID,
+		"rrule":        template.RRule,
+		"amount":       template.Amount,
+		"currency":     template.Currency,
+		"occurrences":  occurrences,
+	})
+}
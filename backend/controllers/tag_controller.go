@@ -0,0 +1,283 @@
+package controllers
+
+import (
+	"errors"
+	"finance-tracker-backend/config"
+	"finance-tracker-backend/models"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+type TagRequest struct {
+	Name string `json:"name"`
+}
+
+type MergeTagRequest struct {
+	TargetID uint `json:"target_id"` // tag tujuan, tag di URL dihapus setelah digabung
+}
+
+type TagWithUsage struct {
+	models.Tag
+	TransactionCount int64 `json:"transaction_count"`
+}
+
+// Nama tag disimpan lowercase tanpa spasi di ujung supaya "Trip-Bali" dan "trip-bali" dianggap sama
+func normalizeTagName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// Panjang maksimal nama tag (kolom varchar(50))
+const maxTagNameLength = 50
+
+// Validasi nama-nama tag dari request sebelum ada query ke DB
+func validateTagNames(names []string) (int, string) {
+	for _, name := range names {
+		if utf8.RuneCountInString(normalizeTagName(name)) > maxTagNameLength {
+			return 400, fmt.Sprintf("Tag name must be at most %d characters", maxTagNameLength)
+		}
+	}
+	return 0, ""
+}
+
+// Validasi nama tag untuk create/rename
+func validateTagName(name string) (int, string) {
+	if name == "" {
+		return 400, "Name is required"
+	}
+	if strings.Contains(name, ",") {
+		return 400, "Tag name must not contain commas"
+	}
+	return validateTagNames([]string{name})
+}
+
+// Pecah query "a,b,c" jadi daftar nama tag yang sudah dinormalisasi
+func splitTagList(value string) []string {
+	var names []string
+	for _, name := range strings.Split(value, ",") {
+		if name = normalizeTagName(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// Cari tag milik user berdasarkan nama, tag yang belum ada dibuat otomatis
+func resolveTags(db *gorm.DB, userID uint, names []string) ([]models.Tag, error) {
+	if _, msg := validateTagNames(names); msg != "" {
+		return nil, errors.New(msg)
+	}
+	tags := make([]models.Tag, 0, len(names))
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		name = normalizeTagName(name)
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		tag := models.Tag{UserID: userID, Name: name}
+		if err := db.Where("user_id = ? AND name = ?", userID, name).FirstOrCreate(&tag).Error; err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+	return tags, nil
+}
+
+// Filter transaksi berdasarkan tag:
+// tags=a,b (harus punya semua), any_tag=a,b (minimal salah satu), exclude_tag=a,b (tidak punya sama sekali)
+func tagFilterScope(userID uint, c *fiber.Ctx) func(*gorm.DB) *gorm.DB {
+	const tagged = "SELECT transaction_tags.transaction_id FROM transaction_tags JOIN tags ON tags.id = transaction_tags.tag_id WHERE tags.user_id = ? AND "
+
+	all := splitTagList(c.Query("tags"))
+	anyOf := splitTagList(c.Query("any_tag"))
+	exclude := splitTagList(c.Query("exclude_tag"))
+
+	return func(db *gorm.DB) *gorm.DB {
+		for _, name := range all {
+			db = db.Where("transactions.id IN ("+tagged+"tags.name = ?)", userID, name)
+		}
+		if len(anyOf) > 0 {
+			db = db.Where("transactions.id IN ("+tagged+"tags.name IN ?)", userID, anyOf)
+		}
+		if len(exclude) > 0 {
+			db = db.Where("transactions.id NOT IN ("+tagged+"tags.name IN ?)", userID, exclude)
+		}
+		return db
+	}
+}
+
+func hasTagFilter(c *fiber.Ctx) bool {
+	return c.Query("tags") != "" || c.Query("any_tag") != "" || c.Query("exclude_tag") != ""
+}
+
+// Get All Tags (beserta jumlah transaksi yang memakainya)
+func GetTags(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	var tags []TagWithUsage
+	err := config.DB.Table("tags").
+		Select("tags.*, COUNT(transactions.id) AS transaction_count").
+		Joins("LEFT JOIN transaction_tags ON transaction_tags.tag_id = tags.id").
+		Joins("LEFT JOIN transactions ON transactions.id = transaction_tags.transaction_id AND transactions.deleted_at IS NULL").
+		Where("tags.user_id = ?", userID).
+		Group("tags.id").
+		Order("tags.name ASC").
+		Scan(&tags).Error
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch tags"})
+	}
+
+	return c.JSON(fiber.Map{
+		"tags": tags,
+	})
+}
+
+// Create Tag
+func CreateTag(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	req := new(TagRequest)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request"})
+	}
+
+	// Validasi
+	name := normalizeTagName(req.Name)
+	if status, msg := validateTagName(name); status != 0 {
+		return c.Status(status).JSON(fiber.Map{"error": msg})
+	}
+
+	var count int64
+	config.DB.Model(&models.Tag{}).Where("user_id = ? AND name = ?", userID, name).Count(&count)
+	if count > 0 {
+		return c.Status(409).JSON(fiber.Map{"error": "Tag already exists"})
+	}
+
+	tag := models.Tag{
+		UserID: userID,
+		Name:   name,
+	}
+
+	if err := config.DB.Create(&tag).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to create tag"})
+	}
+
+	return c.Status(201).JSON(fiber.Map{
+		"message": "Tag created successfully",
+		"tag":     tag,
+	})
+}
+
+// Update Tag (rename)
+func UpdateTag(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+	id := c.Params("id")
+
+	var tag models.Tag
+	if err := config.DB.Where("id = ? AND user_id = ?", id, userID).First(&tag).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Tag not found"})
+	}
+
+	req := new(TagRequest)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request"})
+	}
+
+	name := normalizeTagName(req.Name)
+	if status, msg := validateTagName(name); status != 0 {
+		return c.Status(status).JSON(fiber.Map{"error": msg})
+	}
+
+	// Nama yang sudah dipakai tag lain harus digabung lewat endpoint merge
+	var count int64
+	config.DB.Model(&models.Tag{}).Where("user_id = ? AND name = ? AND id <> ?", userID, name, tag.ID).Count(&count)
+	if count > 0 {
+		return c.Status(409).JSON(fiber.Map{"error": "Another tag already has this name, merge them instead"})
+	}
+
+	tag.Name = name
+	if err := config.DB.Save(&tag).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to update tag"})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Tag updated successfully",
+		"tag":     tag,
+	})
+}
+
+// Delete Tag (transaksinya tetap ada, hanya tag-nya yang dilepas)
+func DeleteTag(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+	id := c.Params("id")
+
+	var tag models.Tag
+	if err := config.DB.Where("id = ? AND user_id = ?", id, userID).First(&tag).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Tag not found"})
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM transaction_tags WHERE tag_id = ?", tag.ID).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&tag).Error
+	})
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to delete tag"})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Tag deleted successfully",
+	})
+}
+
+// Merge Tag: semua transaksi dengan tag :id dipindah ke target_id, lalu tag :id dihapus
+func MergeTag(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+	id := c.Params("id")
+
+	var source models.Tag
+	if err := config.DB.Where("id = ? AND user_id = ?", id, userID).First(&source).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Tag not found"})
+	}
+
+	req := new(MergeTagRequest)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request"})
+	}
+	if req.TargetID == 0 || req.TargetID == source.ID {
+		return c.Status(400).JSON(fiber.Map{"error": "A different target tag is required"})
+	}
+
+	var target models.Tag
+	if err := config.DB.Where("id = ? AND user_id = ?", req.TargetID, userID).First(&target).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Target tag not found"})
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		// Transaksi yang sudah punya kedua tag tidak perlu ditambah lagi
+		if err := tx.Exec("INSERT INTO transaction_tags (transaction_id, tag_id) "+
+			"SELECT transaction_id, ? FROM transaction_tags WHERE tag_id = ? "+
+			"AND transaction_id NOT IN (SELECT transaction_id FROM transaction_tags WHERE tag_id = ?)",
+			target.ID, source.ID, target.ID).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM transaction_tags WHERE tag_id = ?", source.ID).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&source).Error
+	})
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to merge tags"})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Tags merged successfully",
+		"tag":     target,
+	})
+}
@@ -0,0 +1,206 @@
+package controllers
+
+import (
+	"finance-tracker-backend/config"
+	"finance-tracker-backend/models"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// Batas jumlah item (create + update + delete) per request bulk
+const maxBulkItems = 500
+
+type BulkTransactionRequest struct {
+	Create []TransactionRequest    `json:"create"`
+	Update []BulkTransactionUpdate `json:"update"` // partial update, aturannya sama dengan PUT /transactions/:id
+	Delete []uint                  `json:"delete"` // ID transaksi
+}
+
+type BulkTransactionUpdate struct {
+	ID uint `json:"id"`
+	TransactionRequest
+}
+
+// Hasil per item, urutannya sama dengan array di request
+type BulkItemResult struct {
+	Operation string `json:"operation"` // create, update, delete
+	Index     int    `json:"index"`
+	ID        uint   `json:"id,omitempty"`
+	Status    int    `json:"status"`
+	Error     string `json:"error,omitempty"`
+}
+
+type bulkCreate struct {
+	transaction *models.Transaction
+	payee       *models.Payee
+	tags        []string
+}
+
+type bulkUpdate struct {
+	transaction *models.Transaction
+	req         *TransactionRequest
+	payee       *models.Payee
+	splits      []models.TransactionSplit
+}
+
+// Payee baru yang namanya sama di beberapa item cukup dibuat sekali
+func bulkPayee(created map[string]*models.Payee, payee *models.Payee) *models.Payee {
+	if payee == nil || payee.ID != 0 {
+		return payee
+	}
+	key := strings.ToLower(payee.Name)
+	if existing, ok := created[key]; ok {
+		return existing
+	}
+	created[key] = payee
+	return payee
+}
+
+// Bulk Transactions: semua item divalidasi dulu, kalau ada satu yang gagal tidak ada yang disimpan.
+// Kalau semua valid, create/update/delete dijalankan dalam satu DB transaction.
+func BulkTransactions(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	req := new(BulkTransactionRequest)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request"})
+	}
+
+	total := len(req.Create) + len(req.Update) + len(req.Delete)
+	if total == 0 {
+		return c.Status(400).JSON(fiber.Map{"error": "At least one create, update or delete item is required"})
+	}
+	if total > maxBulkItems {
+		return c.Status(400).JSON(fiber.Map{"error": "Too many items, the limit is 500 per request"})
+	}
+
+	results := make([]BulkItemResult, 0, total)
+	failed := false
+	fail := func(operation string, index int, id uint, status int, msg string) {
+		failed = true
+		results = append(results, BulkItemResult{Operation: operation, Index: index, ID: id, Status: status, Error: msg})
+	}
+
+	// Validasi create
+	creates := make([]bulkCreate, 0, len(req.Create))
+	for i := range req.Create {
+		item := &req.Create[i]
+		transaction, payee, status, msg := buildTransaction(userID, item)
+		if status != 0 {
+			fail("create", i, 0, status, msg)
+			continue
+		}
+		creates = append(creates, bulkCreate{transaction: transaction, payee: payee, tags: item.Tags})
+		results = append(results, BulkItemResult{Operation: "create", Index: i, Status: 201})
+	}
+
+	// Transaksi yang di-update/delete diambil sekaligus
+	ids := make([]uint, 0, len(req.Update)+len(req.Delete))
+	for _, item := range req.Update {
+		ids = append(ids, item.ID)
+	}
+	ids = append(ids, req.Delete...)
+	existing := make(map[uint]models.Transaction, len(ids))
+	if len(ids) > 0 {
+		var transactions []models.Transaction
+		if err := config.DB.Where("id IN ? AND user_id = ?", ids, userID).Find(&transactions).Error; err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch transactions"})
+		}
+		for _, transaction := range transactions {
+			existing[transaction.ID] = transaction
+		}
+	}
+
+	// Satu transaksi hanya boleh muncul sekali di update + delete
+	seen := make(map[uint]bool, len(ids))
+	checkTarget := func(operation string, index int, id uint) bool {
+		transaction, ok := existing[id]
+		duplicate := seen[id]
+		seen[id] = true
+		switch {
+		case !ok:
+			fail(operation, index, id, 404, "Transaction not found")
+		case duplicate:
+			fail(operation, index, id, 400, "Transaction appears more than once in this request")
+		case transaction.TransferID != nil:
+			fail(operation, index, id, 400, "Transaction is part of a transfer, use the transfers endpoint")
+		default:
+			return true
+		}
+		return false
+	}
+
+	// Validasi update
+	updates := make([]bulkUpdate, 0, len(req.Update))
+	for i := range req.Update {
+		item := &req.Update[i]
+		if !checkTarget("update", i, item.ID) {
+			continue
+		}
+		transaction := existing[item.ID]
+		payee, splits, status, msg := applyTransactionUpdate(userID, &transaction, &item.TransactionRequest)
+		if status != 0 {
+			fail("update", i, item.ID, status, msg)
+			continue
+		}
+		updates = append(updates, bulkUpdate{transaction: &transaction, req: &item.TransactionRequest, payee: payee, splits: splits})
+		results = append(results, BulkItemResult{Operation: "update", Index: i, ID: item.ID, Status: 200})
+	}
+
+	// Validasi delete
+	deletes := make([]uint, 0, len(req.Delete))
+	for i, id := range req.Delete {
+		if !checkTarget("delete", i, id) {
+			continue
+		}
+		deletes = append(deletes, id)
+		results = append(results, BulkItemResult{Operation: "delete", Index: i, ID: id, Status: 200})
+	}
+
+	if failed {
+		return c.Status(422).JSON(fiber.Map{
+			"error":   "Some items are invalid, nothing was saved",
+			"results": results,
+		})
+	}
+
+	var attachments []models.Attachment
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		newPayees := make(map[string]*models.Payee)
+		for i := range creates {
+			item := &creates[i]
+			if err := createTransaction(tx, userID, item.transaction, bulkPayee(newPayees, item.payee), item.tags); err != nil {
+				return err
+			}
+		}
+		for _, item := range updates {
+			if err := saveTransactionUpdate(tx, userID, item.transaction, item.req, bulkPayee(newPayees, item.payee), item.splits); err != nil {
+				return err
+			}
+		}
+		var err error
+		attachments, err = deleteTransactions(tx, deletes)
+		return err
+	})
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to save transactions"})
+	}
+
+	// File attachment baru dihapus setelah commit
+	removeAttachmentFiles(attachments)
+
+	// ID transaksi baru diketahui setelah disimpan
+	for i, item := range creates {
+		results[i].ID = item.transaction.ID
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Transactions saved successfully",
+		"created": len(creates),
+		"updated": len(updates),
+		"deleted": len(deletes),
+		"results": results,
+	})
+}
@@ -3,44 +3,153 @@ package controllers
 import (
 	"finance-tracker-backend/config"
 	"finance-tracker-backend/models"
+	"finance-tracker-backend/utils"
 	"math"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type TransactionRequest struct {
-	CategoryID  uint    `json:"category_id"`
-	Amount      float64 `json:"amount"`
-	Description string  `json:"description"`
-	Date        string  `json:"date"` // Format: "2024-01-15"
+	AccountID   uint         `json:"account_id"`
+	CategoryID  uint         `json:"category_id"`
+	Amount      models.Money `json:"amount"`
+	Currency    string       `json:"currency"` // kosong = currency account atau base currency user
+	Description string       `json:"description"`
+	Date        string       `json:"date"` // Format: "2024-01-15"
+
+	// Optional: payee_id, atau nama payee (dibuat kalau belum ada).
+	// Kalau dua-duanya kosong saat create, payee ditebak dari description lewat alias.
+	PayeeID uint   `json:"payee_id"`
+	Payee   string `json:"payee"`
+
+	// Optional: pecah transaksi ke beberapa category, total harus sama dengan amount.
+	// Saat update, array kosong menghapus semua split.
+	Splits []SplitRequest `json:"splits"`
+
+	// Optional: nama tag, tag yang belum ada dibuat otomatis.
+	// Saat update, array kosong melepas semua tag.
+	Tags []string `json:"tags"`
 }
 
-// Get All Transactions (dengan filter)
-func GetTransactions(c *fiber.Ctx) error {
-	userID := c.Locals("userID").(uint)
+type SplitRequest struct {
+	CategoryID uint         `json:"category_id"`
+	Amount     models.Money `json:"amount"`
+	Memo       string       `json:"memo"`
+}
 
-	var transactions []models.Transaction
-	query := config.DB.Where("user_id = ?", userID)
+// Validasi baris split: category harus ada, amount positif, dan totalnya sama dengan amount transaksi
+func buildSplits(userID uint, reqs []SplitRequest, amount models.Money, currency string) ([]models.TransactionSplit, int, string) {
+	splits := make([]models.TransactionSplit, 0, len(reqs))
+	var total models.Money
+	for _, line := range reqs {
+		if line.CategoryID == 0 || line.Amount <= 0 {
+			return nil, 400, "Each split needs a category and a positive amount"
+		}
+		category, err := findUserCategory(userID, line.CategoryID)
+		if err != nil {
+			return nil, 404, "Split category not found"
+		}
+		split := models.TransactionSplit{
+			CategoryID: category.ID,
+			Amount:     line.Amount.Round(currency),
+			Memo:       line.Memo,
+		}
+		total += split.Amount
+		splits = append(splits, split)
+	}
+	if total != amount {
+		return nil, 400, "Split amounts must sum to the transaction amount"
+	}
+	return splits, 0, ""
+}
 
-	// Filter by category
-	categoryID := c.Query("category_id")
-	if categoryID != "" {
-		query = query.Where("category_id = ?", categoryID)
+// Filter full-text search: description, nama category (termasuk category di split), atau nama payee.
+// Kata yang terlalu pendek untuk index FULLTEXT dicari di description dengan LIKE.
+func transactionSearchScope(search utils.SearchQuery) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if boolean := search.BooleanQuery(); boolean != "" {
+			db = db.Where("(MATCH(transactions.description) AGAINST (? IN BOOLEAN MODE) "+
+				"OR transactions.category_id IN (SELECT id FROM categories WHERE MATCH(name) AGAINST (? IN BOOLEAN MODE)) "+
+				"OR transactions.id IN (SELECT transaction_splits.transaction_id FROM transaction_splits "+
+				"JOIN categories ON categories.id = transaction_splits.category_id WHERE MATCH(categories.name) AGAINST (? IN BOOLEAN MODE)) "+
+				"OR transactions.payee_id IN (SELECT id FROM payees WHERE MATCH(name) AGAINST (? IN BOOLEAN MODE)))",
+				boolean, boolean, boolean, boolean)
+		}
+		for _, word := range search.ShortWords() {
+			db = db.Where("transactions.description LIKE ?", "%"+word+"%")
+		}
+		return db
+	}
+}
+
+// Filter list transaksi dari query string (category_id + include_descendants, account_id,
+// payee_id, tag, q, start_date/end_date). Dipakai GetTransactions dan ExportTransactions.
+func transactionFilterScope(c *fiber.Ctx, userID uint) (func(*gorm.DB) *gorm.DB, utils.SearchQuery, int, string) {
+	// Filter by category (include_descendants=true ikut sub-category)
+	categoryIDs, status, msg := categoryFilterIDs(c)
+	if status != 0 {
+		return nil, utils.SearchQuery{}, status, msg
 	}
 
-	// Filter by date range
+	accountID := c.Query("account_id")
+	payeeID := c.Query("payee_id")
+	tagScope := tagFilterScope(userID, c)
+	search := utils.ParseSearchQuery(c.Query("q"))
 	startDate := c.Query("start_date") // Format: 2024-01-01
 	endDate := c.Query("end_date")
 
-	if startDate != "" {
-		query = query.Where("date >= ?", startDate)
-	}
-	if endDate != "" {
-		query = query.Where("date <= ?", endDate)
+	return func(query *gorm.DB) *gorm.DB {
+		if categoryIDs != nil {
+			// Transaksi split ikut kalau salah satu barisnya memakai category ini
+			query = query.Where("(category_id IN ? OR id IN (SELECT transaction_id FROM transaction_splits WHERE category_id IN ?))", categoryIDs, categoryIDs)
+		}
+
+		// Filter by account
+		if accountID != "" {
+			query = query.Where("account_id = ?", accountID)
+		}
+
+		// Filter by payee
+		if payeeID != "" {
+			query = query.Where("payee_id = ?", payeeID)
+		}
+
+		// Filter by tag (tags, any_tag, exclude_tag)
+		query = query.Scopes(tagScope)
+
+		// Full-text search (q=grab "jl sudirman")
+		if !search.IsEmpty() {
+			query = query.Scopes(transactionSearchScope(search))
+		}
+
+		// Filter by date range
+		if startDate != "" {
+			query = query.Where("date >= ?", startDate)
+		}
+		if endDate != "" {
+			query = query.Where("date <= ?", endDate)
+		}
+		return query
+	}, search, 0, ""
+}
+
+// Get All Transactions (dengan filter)
+func GetTransactions(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	filter, search, status, msg := transactionFilterScope(c, userID)
+	if status != 0 {
+		return c.Status(status).JSON(fiber.Map{"error": msg})
 	}
 
+	var transactions []models.Transaction
+	query := config.DB.Where("user_id = ?", userID).Scopes(filter)
+
 	// Pagination
 	page, _ := strconv.Atoi(c.Query("page", "1"))
 	limit, _ := strconv.Atoi(c.Query("limit", "10"))
@@ -55,20 +164,45 @@ func GetTransactions(c *fiber.Ctx) error {
 	var total int64
 	query.Model(&models.Transaction{}).Count(&total)
 
+	// Hasil search diurutkan berdasarkan relevansi description, lalu tanggal
+	if boolean := search.BooleanQuery(); boolean != "" {
+		query = query.Order(clause.OrderBy{Expression: clause.Expr{
+			SQL:  "MATCH(transactions.description) AGAINST (? IN BOOLEAN MODE) DESC",
+			Vars: []interface{}{boolean},
+		}})
+	}
+
 	// Preload relations and Apply Pagination
-	if err := query.Preload("Category").Order("date DESC").Limit(limit).Offset(offset).Find(&transactions).Error; err != nil {
+	if err := query.Preload("Category").Preload("Account").Preload("Payee").Preload("Splits.Category").Preload("Tags").Order("date DESC").Limit(limit).Offset(offset).Find(&transactions).Error; err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch transactions"})
 	}
 
+	// Tambahkan nilai dalam base currency (pakai rate di tanggal transaksi)
+	baseCurrency := userBaseCurrency(userID)
+	if rates, err := loadRateTable(userID); err == nil {
+		for i := range transactions {
+			if converted, err := rates.Convert(transactions[i].Amount, transactions[i].Currency, baseCurrency, transactions[i].Date); err == nil {
+				transactions[i].BaseAmount = &converted
+			}
+		}
+	}
+
+	if !search.IsEmpty() {
+		for i := range transactions {
+			transactions[i].Highlight = utils.Highlight(transactions[i].Description, search, 160)
+		}
+	}
+
 	lastPage := math.Ceil(float64(total) / float64(limit))
 
 	return c.JSON(fiber.Map{
 		"data": transactions,
 		"meta": fiber.Map{
-			"total":      total,
-			"page":       page,
-			"last_page":  lastPage,
-			"limit":      limit,
+			"total":         total,
+			"page":          page,
+			"last_page":     lastPage,
+			"limit":         limit,
+			"base_currency": baseCurrency,
 		},
 	})
 }
@@ -79,7 +213,7 @@ func GetTransaction(c *fiber.Ctx) error {
 	id := c.Params("id")
 
 	var transaction models.Transaction
-	if err := config.DB.Where("id = ? AND user_id = ?", id, userID).Preload("Category").First(&transaction).Error; err != nil {
+	if err := config.DB.Where("id = ? AND user_id = ?", id, userID).Preload("Category").Preload("Account").Preload("Payee").Preload("Splits.Category").Preload("Tags").Preload("Attachments").First(&transaction).Error; err != nil {
 		return c.Status(404).JSON(fiber.Map{"error": "Transaction not found"})
 	}
 
@@ -88,96 +222,293 @@ func GetTransaction(c *fiber.Ctx) error {
 	})
 }
 
-// Create Transaction
-func CreateTransaction(c *fiber.Ctx) error {
-	userID := c.Locals("userID").(uint)
-	
-	req := new(TransactionRequest)
-	if err := c.BodyParser(req); err != nil {
-		return c.Status(400).JSON(fiber.Map{"error": "Invalid request"})
+// Validasi request transaksi baru. Payee baru (ID 0) dan tag baru disimpan di createTransaction.
+func buildTransaction(userID uint, req *TransactionRequest) (*models.Transaction, *models.Payee, int, string) {
+	if status, msg := validateTagNames(req.Tags); status != 0 {
+		return nil, nil, status, msg
+	}
+
+	// Transaksi split: category induk diambil dari split pertama
+	if len(req.Splits) > 0 && req.CategoryID == 0 {
+		req.CategoryID = req.Splits[0].CategoryID
+	}
+
+	// Payee, kalau category kosong pakai default category payee
+	payee, status, msg := requestPayee(userID, req.PayeeID, req.Payee, req.Description)
+	if status != 0 {
+		return nil, nil, status, msg
+	}
+	if req.CategoryID == 0 && payee != nil && payee.DefaultCategoryID != nil {
+		req.CategoryID = *payee.DefaultCategoryID
 	}
 
 	// Validasi
 	if req.CategoryID == 0 || req.Amount == 0 || req.Date == "" {
-		return c.Status(400).JSON(fiber.Map{"error": "Category, amount, and date are required"})
+		return nil, nil, 400, "Category, amount, and date are required"
 	}
 
 	// Parse date
 	date, err := time.Parse("2006-01-02", req.Date)
 	if err != nil {
-		return c.Status(400).JSON(fiber.Map{"error": "Invalid date format. Use YYYY-MM-DD"})
+		return nil, nil, 400, "Invalid date format. Use YYYY-MM-DD"
+	}
+
+	// Cek apakah category exists dan milik user
+	category, err := findUserCategory(userID, req.CategoryID)
+	if err != nil {
+		return nil, nil, 404, "Category not found"
+	}
+
+	// Account opsional, tapi kalau diisi harus milik user dan belum di-archive
+	var accountID *uint
+	currency := ""
+	if req.AccountID != 0 {
+		account, err := findUserAccount(userID, req.AccountID)
+		if err != nil {
+			return nil, nil, 404, "Account not found"
+		}
+		if account.Archived {
+			return nil, nil, 400, "Account is archived"
+		}
+		accountID = &account.ID
+		currency = account.Currency
 	}
 
-	// Cek apakah category exists
-	var category models.Category
-	if err := config.DB.First(&category, req.CategoryID).Error; err != nil {
-		return c.Status(404).JSON(fiber.Map{"error": "Category not found"})
+	// Currency default mengikuti account, kalau tidak ada pakai base currency user
+	if req.Currency != "" {
+		code, ok := utils.NormalizeCurrency(req.Currency)
+		if !ok {
+			return nil, nil, 400, "Currency must be a 3-letter ISO code"
+		}
+		currency = code
+	}
+	if currency == "" {
+		currency = userBaseCurrency(userID)
 	}
 
 	transaction := models.Transaction{
 		UserID:      userID,
-		CategoryID:  req.CategoryID,
-		Amount:      req.Amount,
+		AccountID:   accountID,
+		CategoryID:  &category.ID,
+		Amount:      req.Amount.Round(currency),
+		Currency:    currency,
 		Description: req.Description,
 		Date:        date,
 	}
 
-	if err := config.DB.Create(&transaction).Error; err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": "Failed to create transaction"})
+	if len(req.Splits) > 0 {
+		splits, status, msg := buildSplits(userID, req.Splits, transaction.Amount, currency)
+		if status != 0 {
+			return nil, nil, status, msg
+		}
+		transaction.Splits = splits
+		transaction.CategoryID = &splits[0].CategoryID
 	}
 
-	// Load category relation
-	config.DB.Preload("Category").First(&transaction, transaction.ID)
+	return &transaction, payee, 0, ""
+}
 
-	return c.Status(201).JSON(fiber.Map{
-		"message":     "Transaction created successfully",
-		"transaction": transaction,
-	})
+// Simpan transaksi hasil buildTransaction beserta payee baru, split dan tag-nya
+func createTransaction(tx *gorm.DB, userID uint, transaction *models.Transaction, payee *models.Payee, tagNames []string) error {
+	if err := createPayeeIfNew(tx, payee); err != nil {
+		return err
+	}
+	if payee != nil {
+		transaction.PayeeID = &payee.ID
+	}
+	if len(tagNames) > 0 {
+		tags, err := resolveTags(tx, userID, tagNames)
+		if err != nil {
+			return err
+		}
+		transaction.Tags = tags
+	}
+	return tx.Create(transaction).Error
 }
 
-// Update Transaction
-func UpdateTransaction(c *fiber.Ctx) error {
+// Create Transaction
+func CreateTransaction(c *fiber.Ctx) error {
 	userID := c.Locals("userID").(uint)
-	id := c.Params("id")
-
-	var transaction models.Transaction
-	if err := config.DB.Where("id = ? AND user_id = ?", id, userID).First(&transaction).Error; err != nil {
-		return c.Status(404).JSON(fiber.Map{"error": "Transaction not found"})
-	}
 
 	req := new(TransactionRequest)
 	if err := c.BodyParser(req); err != nil {
 		return c.Status(400).JSON(fiber.Map{"error": "Invalid request"})
 	}
 
+	transaction, payee, status, msg := buildTransaction(userID, req)
+	if status != 0 {
+		return c.Status(status).JSON(fiber.Map{"error": msg})
+	}
+
+	// Payee baru, split & tag ikut dibuat dalam DB transaction yang sama
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		return createTransaction(tx, userID, transaction, payee, req.Tags)
+	})
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to create transaction"})
+	}
+
+	// Load category & account relation
+	config.DB.Preload("Category").Preload("Account").Preload("Payee").Preload("Splits.Category").Preload("Tags").First(transaction, transaction.ID)
+
+	return c.Status(201).JSON(fiber.Map{
+		"message":     "Transaction created successfully",
+		"transaction": transaction,
+	})
+}
+
+// Terapkan request update ke transaksi (belum disimpan). Field kosong tidak diubah,
+// splits/tags nil tidak diubah dan array kosong menghapus semuanya.
+func applyTransactionUpdate(userID uint, transaction *models.Transaction, req *TransactionRequest) (*models.Payee, []models.TransactionSplit, int, string) {
+	var payee *models.Payee
+	var splits []models.TransactionSplit
+	var status int
+	var msg string
+	if status, msg = validateTagNames(req.Tags); status != 0 {
+		return nil, nil, status, msg
+	}
+	var existingSplits int64
+	config.DB.Model(&models.TransactionSplit{}).Where("transaction_id = ?", transaction.ID).Count(&existingSplits)
+
 	// Update fields
+	if req.CategoryID != 0 && existingSplits > 0 && req.Splits == nil {
+		return nil, nil, 400, "Transaction is split, update its splits instead"
+	}
 	if req.CategoryID != 0 {
-		var category models.Category
-		if err := config.DB.First(&category, req.CategoryID).Error; err != nil {
-			return c.Status(404).JSON(fiber.Map{"error": "Category not found"})
+		category, err := findUserCategory(userID, req.CategoryID)
+		if err != nil {
+			return nil, nil, 404, "Category not found"
+		}
+		transaction.CategoryID = &category.ID
+	}
+	if req.AccountID != 0 {
+		account, err := findUserAccount(userID, req.AccountID)
+		if err != nil {
+			return nil, nil, 404, "Account not found"
+		}
+		if account.Archived {
+			return nil, nil, 400, "Account is archived"
 		}
-		transaction.CategoryID = req.CategoryID
+		transaction.AccountID = &account.ID
 	}
 	if req.Amount != 0 {
 		transaction.Amount = req.Amount
 	}
+	if req.Currency != "" {
+		code, ok := utils.NormalizeCurrency(req.Currency)
+		if !ok {
+			return nil, nil, 400, "Currency must be a 3-letter ISO code"
+		}
+		transaction.Currency = code
+	}
 	if req.Description != "" {
 		transaction.Description = req.Description
 	}
+	if req.PayeeID != 0 || strings.TrimSpace(req.Payee) != "" {
+		if payee, status, msg = requestPayee(userID, req.PayeeID, req.Payee, ""); status != 0 {
+			return nil, nil, status, msg
+		}
+	}
 	if req.Date != "" {
 		date, err := time.Parse("2006-01-02", req.Date)
 		if err != nil {
-			return c.Status(400).JSON(fiber.Map{"error": "Invalid date format. Use YYYY-MM-DD"})
+			return nil, nil, 400, "Invalid date format. Use YYYY-MM-DD"
 		}
 		transaction.Date = date
 	}
 
-	if err := config.DB.Save(&transaction).Error; err != nil {
+	// Bulatkan sesuai currency (misalnya rupiah tanpa sen)
+	transaction.Amount = transaction.Amount.Round(transaction.Currency)
+
+	// Split diganti kalau dikirim, kalau tidak split lama harus tetap cocok dengan amount baru
+	if req.Splits != nil {
+		if len(req.Splits) > 0 {
+			splits, status, msg = buildSplits(userID, req.Splits, transaction.Amount, transaction.Currency)
+			if status != 0 {
+				return nil, nil, status, msg
+			}
+			transaction.CategoryID = &splits[0].CategoryID
+		}
+	} else if existingSplits > 0 {
+		var total models.Money
+		config.DB.Model(&models.TransactionSplit{}).Where("transaction_id = ?", transaction.ID).Select("COALESCE(SUM(amount), 0)").Scan(&total)
+		if total != transaction.Amount {
+			return nil, nil, 400, "Split amounts must sum to the transaction amount"
+		}
+	}
+
+	return payee, splits, 0, ""
+}
+
+// Simpan transaksi hasil applyTransactionUpdate beserta payee baru, tag dan split-nya
+func saveTransactionUpdate(tx *gorm.DB, userID uint, transaction *models.Transaction, req *TransactionRequest, payee *models.Payee, splits []models.TransactionSplit) error {
+	if payee != nil {
+		if err := createPayeeIfNew(tx, payee); err != nil {
+			return err
+		}
+		transaction.PayeeID = &payee.ID
+	}
+	if err := tx.Save(transaction).Error; err != nil {
+		return err
+	}
+	if req.Tags != nil {
+		tags, err := resolveTags(tx, userID, req.Tags)
+		if err != nil {
+			return err
+		}
+		if err := tx.Model(transaction).Association("Tags").Replace(tags); err != nil {
+			return err
+		}
+	}
+	if req.Splits == nil {
+		return nil
+	}
+	if err := tx.Where("transaction_id = ?", transaction.ID).Delete(&models.TransactionSplit{}).Error; err != nil {
+		return err
+	}
+	for i := range splits {
+		splits[i].TransactionID = transaction.ID
+	}
+	if len(splits) == 0 {
+		return nil
+	}
+	return tx.Create(&splits).Error
+}
+
+// Update Transaction
+func UpdateTransaction(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+	id := c.Params("id")
+
+	var transaction models.Transaction
+	if err := config.DB.Where("id = ? AND user_id = ?", id, userID).First(&transaction).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Transaction not found"})
+	}
+
+	// Leg transfer hanya boleh diubah lewat /api/transfers supaya pasangannya tetap sinkron
+	if transaction.TransferID != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Transaction is part of a transfer, use the transfers endpoint"})
+	}
+
+	req := new(TransactionRequest)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request"})
+	}
+
+	payee, splits, status, msg := applyTransactionUpdate(userID, &transaction, req)
+	if status != 0 {
+		return c.Status(status).JSON(fiber.Map{"error": msg})
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		return saveTransactionUpdate(tx, userID, &transaction, req, payee, splits)
+	})
+	if err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": "Failed to update transaction"})
 	}
 
-	// Load category relation
-	config.DB.Preload("Category").First(&transaction, transaction.ID)
+	// Load category & account relation
+	config.DB.Preload("Category").Preload("Account").Preload("Payee").Preload("Splits.Category").Preload("Tags").First(&transaction, transaction.ID)
 
 	return c.JSON(fiber.Map{
 		"message":     "Transaction updated successfully",
@@ -185,6 +516,37 @@ func UpdateTransaction(c *fiber.Ctx) error {
 	})
 }
 
+// Hapus data yang menunjuk ke transaksi (contribution goal, pembayaran debt),
+// dipanggil di dalam DB transaction yang sama dengan penghapusan transaksinya
+func deleteTransactionLinks(tx *gorm.DB, transactionIDs []uint) error {
+	if len(transactionIDs) == 0 {
+		return nil
+	}
+	if err := tx.Where("transaction_id IN ?", transactionIDs).Delete(&models.GoalContribution{}).Error; err != nil {
+		return err
+	}
+	return tx.Where("transaction_id IN ?", transactionIDs).Delete(&models.DebtPayment{}).Error
+}
+
+// Soft-delete transaksi beserta split, link dan attachment-nya di dalam DB transaction.
+// Mengembalikan attachment yang file-nya dihapus setelah commit (removeAttachmentFiles).
+func deleteTransactions(tx *gorm.DB, transactionIDs []uint) ([]models.Attachment, error) {
+	if len(transactionIDs) == 0 {
+		return nil, nil
+	}
+	if err := tx.Where("transaction_id IN ?", transactionIDs).Delete(&models.TransactionSplit{}).Error; err != nil {
+		return nil, err
+	}
+	if err := deleteTransactionLinks(tx, transactionIDs); err != nil {
+		return nil, err
+	}
+	attachments, err := deleteTransactionAttachments(tx, transactionIDs)
+	if err != nil {
+		return nil, err
+	}
+	return attachments, tx.Where("id IN ?", transactionIDs).Delete(&models.Transaction{}).Error
+}
+
 // Delete Transaction
 func DeleteTransaction(c *fiber.Ctx) error {
 	userID := c.Locals("userID").(uint)
@@ -195,40 +557,196 @@ func DeleteTransaction(c *fiber.Ctx) error {
 		return c.Status(404).JSON(fiber.Map{"error": "Transaction not found"})
 	}
 
-	if err := config.DB.Delete(&transaction).Error; err != nil {
+	if transaction.TransferID != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Transaction is part of a transfer, use the transfers endpoint"})
+	}
+
+	var attachments []models.Attachment
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		attachments, err = deleteTransactions(tx, []uint{transaction.ID})
+		return err
+	})
+	if err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": "Failed to delete transaction"})
 	}
 
+	// File attachment baru dihapus setelah commit
+	removeAttachmentFiles(attachments)
+
 	return c.JSON(fiber.Map{
 		"message": "Transaction deleted successfully",
 	})
 }
 
-// Get Balance (Total Income - Total Expense)
+// Get Balance (Total Income - Total Expense) + saldo per account
+// Semua total dikonversi ke base currency user memakai rate di tanggal transaksi
 func GetBalance(c *fiber.Ctx) error {
 	userID := c.Locals("userID").(uint)
 
-	var totalIncome, totalExpense float64
+	// Optional filter by account
+	var filterAccountID uint
+	if accountID := c.Query("account_id"); accountID != "" {
+		id, err := strconv.ParseUint(accountID, 10, 64)
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "Invalid account_id"})
+		}
+		filterAccountID = uint(id)
+	}
 
-	// Sum income
-	config.DB.Table("transactions").
-		Select("COALESCE(SUM(amount), 0)").
-		Joins("JOIN categories ON transactions.category_id = categories.id").
-		Where("transactions.user_id = ? AND categories.type = ?", userID, "income").
-		Scan(&totalIncome)
+	baseCurrency := userBaseCurrency(userID)
+	rates, err := loadRateTable(userID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to load exchange rates"})
+	}
 
-	// Sum expense
-	config.DB.Table("transactions").
-		Select("COALESCE(SUM(amount), 0)").
-		Joins("JOIN categories ON transactions.category_id = categories.id").
-		Where("transactions.user_id = ? AND categories.type = ?", userID, "expense").
-		Scan(&totalExpense)
+	rows, err := balanceRows(userID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to calculate balance"})
+	}
+
+	// Filter tag & category hanya berlaku untuk total income/expense, saldo account tetap saldo penuh
+	categoryIDs, status, msg := categoryFilterIDs(c)
+	if status != 0 {
+		return c.Status(status).JSON(fiber.Map{"error": msg})
+	}
+	totalRows := rows
+	if hasTagFilter(c) || categoryIDs != nil {
+		scopes := []func(*gorm.DB) *gorm.DB{tagFilterScope(userID, c)}
+		if categoryIDs != nil {
+			// Per baris split, supaya split di category lain tidak ikut terhitung
+			scopes = append(scopes, func(db *gorm.DB) *gorm.DB {
+				return db.Where("COALESCE(transaction_splits.category_id, transactions.category_id) IN ?", categoryIDs)
+			})
+		}
+		if totalRows, err = balanceRows(userID, scopes...); err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": "Failed to calculate balance"})
+		}
+	}
+
+	var totalIncome, totalExpense models.Money
+	for _, row := range totalRows {
+		if row.Kind != "income" && row.Kind != "expense" {
+			continue
+		}
+		if filterAccountID != 0 && (row.AccountID == nil || *row.AccountID != filterAccountID) {
+			continue
+		}
+		converted, err := rates.Convert(row.Amount, row.Currency, baseCurrency, row.Day)
+		if err != nil {
+			return c.Status(422).JSON(fiber.Map{"error": err.Error()})
+		}
+		if row.Kind == "income" {
+			totalIncome += converted
+		} else {
+			totalExpense += converted
+		}
+	}
 
 	balance := totalIncome - totalExpense
 
+	accounts, err := accountBalances(userID, rows, rates, baseCurrency)
+	if err != nil {
+		return c.Status(422).JSON(fiber.Map{"error": err.Error()})
+	}
+
 	return c.JSON(fiber.Map{
 		"total_income":  totalIncome,
 		"total_expense": totalExpense,
 		"balance":       balance,
+		"base_currency": baseCurrency,
+		"accounts":      accounts,
 	})
-}
\ No newline at end of file
+}
+
+type AccountBalance struct {
+	AccountID      uint          `json:"account_id"`
+	Name           string        `json:"name"`
+	Kind           string        `json:"kind"`
+	Currency       string        `json:"currency"`
+	Archived       bool          `json:"archived"`
+	OpeningBalance models.Money  `json:"opening_balance"`
+	Balance        models.Money  `json:"balance"`                // dalam currency account
+	BaseBalance    *models.Money `json:"base_balance,omitempty"` // dalam base currency, rate hari ini
+}
+
+// Jumlah transaksi per account, jenis (income/expense/transfer), currency dan hari.
+// Transaksi split dihitung per baris split memakai type category masing-masing.
+type balanceRow struct {
+	AccountID *uint
+	Kind      string
+	Currency  string
+	Day       time.Time
+	Amount    models.Money
+}
+
+func balanceRows(userID uint, scopes ...func(*gorm.DB) *gorm.DB) ([]balanceRow, error) {
+	var rows []balanceRow
+	err := config.DB.Table("transactions").
+		Select("transactions.account_id, "+
+			"CASE WHEN transactions.transfer_id IS NOT NULL THEN 'transfer' ELSE COALESCE(split_categories.type, categories.type, '') END AS kind, "+
+			"transactions.currency, DATE(transactions.date) AS day, SUM(COALESCE(transaction_splits.amount, transactions.amount)) AS amount").
+		Joins("LEFT JOIN transaction_splits ON transaction_splits.transaction_id = transactions.id").
+		Joins("LEFT JOIN categories ON transactions.category_id = categories.id").
+		Joins("LEFT JOIN categories AS split_categories ON transaction_splits.category_id = split_categories.id").
+		Where("transactions.user_id = ? AND transactions.deleted_at IS NULL", userID).
+		Scopes(scopes...).
+		Group("transactions.account_id, kind, transactions.currency, day").
+		Scan(&rows).Error
+	return rows, err
+}
+
+// Saldo per account = opening balance + income - expense + transfer masuk/keluar,
+// dalam currency account. Leg transfer tidak punya category dan amount-nya sudah bertanda.
+func accountBalances(userID uint, rows []balanceRow, rates *utils.RateTable, baseCurrency string) ([]AccountBalance, error) {
+	var accounts []models.Account
+	if err := config.DB.Where("user_id = ?", userID).Order("name ASC").Find(&accounts).Error; err != nil {
+		return nil, err
+	}
+
+	currencies := make(map[uint]string, len(accounts))
+	for _, account := range accounts {
+		currencies[account.ID] = account.Currency
+	}
+
+	net := make(map[uint]models.Money, len(accounts))
+	for _, row := range rows {
+		if row.AccountID == nil {
+			continue
+		}
+		currency, ok := currencies[*row.AccountID]
+		if !ok {
+			continue
+		}
+		converted, err := rates.Convert(row.Amount, row.Currency, currency, row.Day)
+		if err != nil {
+			return nil, err
+		}
+		switch row.Kind {
+		case "income", "transfer":
+			net[*row.AccountID] += converted
+		default:
+			net[*row.AccountID] -= converted
+		}
+	}
+
+	today := time.Now()
+	balances := make([]AccountBalance, 0, len(accounts))
+	for _, account := range accounts {
+		item := AccountBalance{
+			AccountID:      account.ID,
+			Name:           account.Name,
+			Kind:           account.Kind,
+			Currency:       account.Currency,
+			Archived:       account.Archived,
+			OpeningBalance: account.OpeningBalance,
+			Balance:        account.OpeningBalance + net[account.ID],
+		}
+		if converted, err := rates.Convert(item.Balance, account.Currency, baseCurrency, today); err == nil {
+			item.BaseBalance = &converted
+		}
+		balances = append(balances, item)
+	}
+
+	return balances, nil
+}
@@ -0,0 +1,342 @@
+package controllers
+
+import (
+	"finance-tracker-backend/config"
+	"finance-tracker-backend/models"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+type TransferRequest struct {
+	FromAccountID uint         `json:"from_account_id"`
+	ToAccountID   uint         `json:"to_account_id"`
+	Amount        models.Money `json:"amount"`
+	ToAmount      models.Money `json:"to_amount"` // wajib kalau currency kedua account berbeda dan tidak ada rate
+	Description   string       `json:"description"`
+	Date          string       `json:"date"` // Format: "2024-01-15"
+}
+
+// Bangun ulang dua leg transfer (keluar & masuk) dari data transfer
+func transferLegs(transfer *models.Transfer, from, to *models.Account) []models.Transaction {
+	return []models.Transaction{
+		{
+			UserID:      transfer.UserID,
+			AccountID:   &transfer.FromAccountID,
+			TransferID:  &transfer.ID,
+			Amount:      -transfer.Amount,
+			Currency:    from.Currency,
+			Description: transfer.Description,
+			Date:        transfer.Date,
+		},
+		{
+			UserID:      transfer.UserID,
+			AccountID:   &transfer.ToAccountID,
+			TransferID:  &transfer.ID,
+			Amount:      transfer.ToAmount,
+			Currency:    to.Currency,
+			Description: transfer.Description,
+			Date:        transfer.Date,
+		},
+	}
+}
+
+// Validasi kedua account transfer milik user, berbeda, dan tidak di-archive
+func validateTransferAccounts(userID uint, fromID, toID uint) (*models.Account, *models.Account, int, string) {
+	if fromID == toID {
+		return nil, nil, 400, "Source and destination accounts must be different"
+	}
+	accounts := make([]*models.Account, 0, 2)
+	for _, id := range []uint{fromID, toID} {
+		account, err := findUserAccount(userID, id)
+		if err != nil {
+			return nil, nil, 404, "Account not found"
+		}
+		if account.Archived {
+			return nil, nil, 400, "Account is archived"
+		}
+		accounts = append(accounts, account)
+	}
+	return accounts[0], accounts[1], 0, ""
+}
+
+// Isi ToAmount: sama dengan Amount kalau currency sama, selain itu konversi pakai rate di tanggal transfer
+func resolveTransferToAmount(userID uint, transfer *models.Transfer, requested models.Money, from, to *models.Account) (int, string) {
+	if requested < 0 {
+		return 400, "To amount must be positive"
+	}
+	// Amount keluar dibulatkan ke currency account asal
+	transfer.Amount = transfer.Amount.Round(from.Currency)
+	if requested > 0 {
+		transfer.ToAmount = requested.Round(to.Currency)
+		return 0, ""
+	}
+	if from.Currency == to.Currency {
+		transfer.ToAmount = transfer.Amount
+		return 0, ""
+	}
+
+	rates, err := loadRateTable(userID)
+	if err != nil {
+		return 500, "Failed to load exchange rates"
+	}
+	converted, err := rates.Convert(transfer.Amount, from.Currency, to.Currency, transfer.Date)
+	if err != nil {
+		return 422, "Accounts use different currencies: provide to_amount or add an exchange rate"
+	}
+	transfer.ToAmount = converted
+	return 0, ""
+}
+
+// Get All Transfers
+func GetTransfers(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	var transfers []models.Transfer
+	query := config.DB.Where("user_id = ?", userID)
+
+	// Filter by account (asal atau tujuan)
+	accountID := c.Query("account_id")
+	if accountID != "" {
+		query = query.Where("from_account_id = ? OR to_account_id = ?", accountID, accountID)
+	}
+
+	if err := query.Preload("FromAccount").Preload("ToAccount").Order("date DESC").Find(&transfers).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch transfers"})
+	}
+
+	return c.JSON(fiber.Map{
+		"transfers": transfers,
+	})
+}
+
+// Get Single Transfer
+func GetTransfer(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+	id := c.Params("id")
+
+	var transfer models.Transfer
+	if err := config.DB.Where("id = ? AND user_id = ?", id, userID).
+		Preload("FromAccount").Preload("ToAccount").Preload("Transactions").
+		First(&transfer).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Transfer not found"})
+	}
+
+	return c.JSON(fiber.Map{
+		"transfer": transfer,
+	})
+}
+
+// Create Transfer
+func CreateTransfer(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	req := new(TransferRequest)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request"})
+	}
+
+	// Validasi
+	if req.FromAccountID == 0 || req.ToAccountID == 0 || req.Amount == 0 || req.Date == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "Source account, destination account, amount, and date are required"})
+	}
+	if req.Amount < 0 {
+		return c.Status(400).JSON(fiber.Map{"error": "Amount must be positive"})
+	}
+
+	date, err := time.Parse("2006-01-02", req.Date)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid date format. Use YYYY-MM-DD"})
+	}
+
+	from, to, status, msg := validateTransferAccounts(userID, req.FromAccountID, req.ToAccountID)
+	if status != 0 {
+		return c.Status(status).JSON(fiber.Map{"error": msg})
+	}
+
+	transfer := models.Transfer{
+		UserID:        userID,
+		FromAccountID: req.FromAccountID,
+		ToAccountID:   req.ToAccountID,
+		Amount:        req.Amount,
+		Description:   req.Description,
+		Date:          date,
+	}
+	if status, msg := resolveTransferToAmount(userID, &transfer, req.ToAmount, from, to); status != 0 {
+		return c.Status(status).JSON(fiber.Map{"error": msg})
+	}
+
+	// Transfer dan kedua leg-nya dibuat dalam satu DB transaction
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&transfer).Error; err != nil {
+			return err
+		}
+		legs := transferLegs(&transfer, from, to)
+		return tx.Create(&legs).Error
+	})
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to create transfer"})
+	}
+
+	config.DB.Preload("FromAccount").Preload("ToAccount").Preload("Transactions").First(&transfer, transfer.ID)
+
+	return c.Status(201).JSON(fiber.Map{
+		"message":  "Transfer created successfully",
+		"transfer": transfer,
+	})
+}
+
+// Update Transfer
+func UpdateTransfer(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+	id := c.Params("id")
+
+	var transfer models.Transfer
+	if err := config.DB.Where("id = ? AND user_id = ?", id, userID).First(&transfer).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Transfer not found"})
+	}
+
+	req := new(TransferRequest)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request"})
+	}
+
+	// Leg lama, untuk tahu pasangan currency sebelum diedit
+	var legs []models.Transaction
+	if err := config.DB.Where("transfer_id = ?", transfer.ID).Order("id ASC").Find(&legs).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch transfer"})
+	}
+	original := transfer
+
+	// Update fields
+	if req.FromAccountID != 0 {
+		transfer.FromAccountID = req.FromAccountID
+	}
+	if req.ToAccountID != 0 {
+		transfer.ToAccountID = req.ToAccountID
+	}
+	if req.Amount != 0 {
+		if req.Amount < 0 {
+			return c.Status(400).JSON(fiber.Map{"error": "Amount must be positive"})
+		}
+		transfer.Amount = req.Amount
+	}
+	if req.Description != "" {
+		transfer.Description = req.Description
+	}
+	if req.Date != "" {
+		date, err := time.Parse("2006-01-02", req.Date)
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "Invalid date format. Use YYYY-MM-DD"})
+		}
+		transfer.Date = date
+	}
+
+	from, to, status, msg := validateTransferAccounts(userID, transfer.FromAccountID, transfer.ToAccountID)
+	if status != 0 {
+		return c.Status(status).JSON(fiber.Map{"error": msg})
+	}
+
+	// ToAmount yang tersimpan (bisa diketik user untuk transfer beda currency) dipertahankan
+	// kalau hanya description/date yang diubah. Dihitung ulang kalau amount, account atau
+	// pasangan currency berubah, atau kalau to_amount diisi eksplisit.
+	currencyChanged := false
+	for _, leg := range legs {
+		if leg.Amount < 0 && leg.Currency != from.Currency || leg.Amount >= 0 && leg.Currency != to.Currency {
+			currencyChanged = true
+		}
+	}
+	if req.ToAmount != 0 || currencyChanged || transfer.Amount != original.Amount ||
+		transfer.FromAccountID != original.FromAccountID || transfer.ToAccountID != original.ToAccountID {
+		if status, msg := resolveTransferToAmount(userID, &transfer, req.ToAmount, from, to); status != 0 {
+			return c.Status(status).JSON(fiber.Map{"error": msg})
+		}
+	}
+
+	// Update transfer dan kedua leg-nya di tempat (bukan hapus & buat ulang), supaya
+	// attachment, goal contribution, debt payment dan import_hash di leg tidak hilang
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&transfer).Error; err != nil {
+			return err
+		}
+		current := make([]*models.Transaction, 2) // [outgoing, incoming]
+		for i := range legs {
+			slot := 1
+			if legs[i].Amount < 0 {
+				slot = 0
+			}
+			if current[slot] == nil {
+				current[slot] = &legs[i]
+			}
+		}
+		for i, leg := range transferLegs(&transfer, from, to) {
+			if current[i] == nil {
+				// Leg hilang (data lama), buat ulang
+				if err := tx.Create(&leg).Error; err != nil {
+					return err
+				}
+				continue
+			}
+			if err := tx.Model(current[i]).Updates(map[string]interface{}{
+				"account_id":  leg.AccountID,
+				"amount":      leg.Amount,
+				"currency":    leg.Currency,
+				"description": leg.Description,
+				"date":        leg.Date,
+			}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to update transfer"})
+	}
+
+	config.DB.Preload("FromAccount").Preload("ToAccount").Preload("Transactions").First(&transfer, transfer.ID)
+
+	return c.JSON(fiber.Map{
+		"message":  "Transfer updated successfully",
+		"transfer": transfer,
+	})
+}
+
+// Delete Transfer
+func DeleteTransfer(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+	id := c.Params("id")
+
+	var transfer models.Transfer
+	if err := config.DB.Where("id = ? AND user_id = ?", id, userID).First(&transfer).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Transfer not found"})
+	}
+
+	// Hapus kedua leg beserta transfer-nya dalam satu DB transaction
+	var attachments []models.Attachment
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		var legIDs []uint
+		if err := tx.Model(&models.Transaction{}).Where("transfer_id = ?", transfer.ID).Pluck("id", &legIDs).Error; err != nil {
+			return err
+		}
+		if err := deleteTransactionLinks(tx, legIDs); err != nil {
+			return err
+		}
+		var err error
+		if attachments, err = deleteTransactionAttachments(tx, legIDs); err != nil {
+			return err
+		}
+		if err := tx.Where("transfer_id = ?", transfer.ID).Delete(&models.Transaction{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&transfer).Error
+	})
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to delete transfer"})
+	}
+	removeAttachmentFiles(attachments)
+
+	return c.JSON(fiber.Map{
+		"message": "Transfer deleted successfully",
+	})
+}
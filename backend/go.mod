@@ -8,6 +8,7 @@ require (
 	github.com/google/generative-ai-go v0.20.1
 	github.com/joho/godotenv v1.5.1
 	golang.org/x/crypto v0.46.0
+	golang.org/x/image v0.25.0
 	google.golang.org/api v0.258.0
 	gorm.io/driver/mysql v1.6.0
 	gorm.io/gorm v1.31.1
@@ -2,10 +2,15 @@ package main
 
 import (
 	"finance-tracker-backend/config"
+	"finance-tracker-backend/controllers"
 	"finance-tracker-backend/models"
 	"finance-tracker-backend/routes"
+	"finance-tracker-backend/scheduler"
+	"finance-tracker-backend/storage"
+	"finance-tracker-backend/utils"
 	"log"
 	"os"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
@@ -22,21 +27,70 @@ func main() {
 	// Connect to database
 	config.ConnectDB()
 
+	// Lebarkan kolom uang lama decimal(15,2) -> decimal(19,4)
+	if err := config.MigrateMoneyColumns(); err != nil {
+		log.Fatal("Failed to migrate money columns:", err)
+	}
+
 	// Auto migrate database tables
 	if err := config.DB.AutoMigrate(
 		&models.User{},
 		&models.Category{},
+		&models.Account{},
+		&models.Tag{},
+		&models.Payee{},
+		&models.PayeeAlias{},
+		&models.Transfer{},
 		&models.Transaction{},
+		&models.TransactionSplit{},
+		&models.Attachment{},
+		&models.ExchangeRate{},
+		&models.RecurringTransaction{},
+		&models.Budget{},
+		&models.Envelope{},
+		&models.EnvelopeAssignment{},
+		&models.Goal{},
+		&models.GoalContribution{},
+		&models.Debt{},
+		&models.DebtPayment{},
+		&models.ImportMapping{},
+		&models.ImportBatch{},
+		&models.ImportRow{},
+		&models.NotificationTemplate{},
+		&models.TransactionDraft{},
 	); err != nil {
 		log.Fatal("Failed to migrate database:", err)
 	}
 	log.Println("Database migrated successfully!")
 
+	// Category global lama -> salinan per user
+	if err := config.MigrateCategoryOwnership(); err != nil {
+		log.Fatal("Failed to migrate category ownership:", err)
+	}
+
 	// Seed default categories (optional)
 	seedCategories()
+	seedNotificationTemplates()
+
+	// Load global exchange rates dari file lokal (optional)
+	loadExchangeRatesFile()
+
+	// Storage untuk attachment (local filesystem atau S3-compatible)
+	if err := storage.Setup(); err != nil {
+		log.Fatal("Failed to set up attachment storage:", err)
+	}
+
+	// Start recurring transaction scheduler
+	recurringInterval, err := time.ParseDuration(os.Getenv("RECURRING_INTERVAL"))
+	if err != nil || recurringInterval <= 0 {
+		recurringInterval = time.Hour
+	}
+	scheduler.StartRecurring(recurringInterval)
 
 	// Initialize Fiber app
 	app := fiber.New(fiber.Config{
+		// Cukup untuk upload attachment dan file import (maks 10 MB) plus overhead multipart
+		BodyLimit: 12 << 20,
 		ErrorHandler: func(c *fiber.Ctx, err error) error {
 			return c.Status(500).JSON(fiber.Map{
 				"error": err.Error(),
@@ -75,9 +129,10 @@ func main() {
 }
 
 // Seed default categories (hanya jalan sekali)
+// Category default sistem (user_id = 0), disalin ke setiap user baru saat Register
 func seedCategories() {
 	var count int64
-	config.DB.Model(&models.Category{}).Count(&count)
+	config.DB.Model(&models.Category{}).Where("user_id = ?", 0).Count(&count)
 
 	if count == 0 {
 		defaultCategories := []models.Category{
@@ -95,3 +150,49 @@ func seedCategories() {
 		log.Println("Default categories seeded successfully!")
 	}
 }
+
+// Template notifikasi bank sistem (user_id = 0). Dicocokkan per bank + nama, jadi template
+// bawaan yang baru ditambahkan ikut ter-seed dan pattern yang diperbaiki ikut ter-update.
+func seedNotificationTemplates() {
+	for _, template := range utils.DefaultNotificationTemplates() {
+		var existing models.NotificationTemplate
+		err := config.DB.Where("user_id = 0 AND bank = ? AND name = ?", template.Bank, template.Name).First(&existing).Error
+		if err == nil {
+			if existing.Pattern != template.Pattern || existing.Kind != template.Kind {
+				config.DB.Model(&existing).Updates(map[string]interface{}{"pattern": template.Pattern, "kind": template.Kind})
+			}
+			continue
+		}
+		template.Active = true
+		if err := config.DB.Create(&template).Error; err != nil {
+			log.Println("Warning: failed to seed notification template:", err)
+		}
+	}
+}
+
+// Load exchange rate global dari file CSV / XML ECB yang ditunjuk EXCHANGE_RATES_FILE
+func loadExchangeRatesFile() {
+	path := os.Getenv("EXCHANGE_RATES_FILE")
+	if path == "" {
+		return
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		log.Println("Warning: failed to read exchange rates file:", err)
+		return
+	}
+
+	rates, err := utils.ParseRatesFile(data)
+	if err != nil {
+		log.Println("Warning: failed to parse exchange rates file:", err)
+		return
+	}
+
+	// UserID 0 = rate global
+	if err := controllers.UpsertExchangeRates(config.DB, rates); err != nil {
+		log.Println("Warning: failed to save exchange rates:", err)
+		return
+	}
+	log.Printf("Loaded %d exchange rates from %s", len(rates), path)
+}
@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type Account struct {
+	ID             uint           `gorm:"primaryKey" json:"id"`
+	UserID         uint           `gorm:"not null;index" json:"user_id"`
+	Name           string         `gorm:"type:varchar(100);not null" json:"name"`
+	Kind           string         `gorm:"type:enum('bank','cash','credit_card','ewallet');not null" json:"kind"` // bank, cash, credit_card atau ewallet
+	OpeningBalance Money          `gorm:"type:decimal(19,4);not null;default:0" json:"opening_balance"`
+	Currency       string         `gorm:"type:varchar(3);not null;default:'IDR'" json:"currency"`
+	Archived       bool           `gorm:"not null;default:false" json:"archived"`
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+	DeletedAt      gorm.DeletedAt `gorm:"index" json:"-"`
+
+	// Relations
+	Transactions []Transaction `gorm:"foreignKey:AccountID" json:"transactions,omitempty"`
+}
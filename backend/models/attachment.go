@@ -0,0 +1,20 @@
+package models
+
+import (
+	"time"
+)
+
+// Attachment adalah file (foto struk, invoice PDF) yang ditempel ke transaksi.
+// Isi file ada di storage (lokal atau S3), tabel ini hanya menyimpan metadata-nya.
+type Attachment struct {
+	ID            uint      `gorm:"primaryKey" json:"id"`
+	UserID        uint      `gorm:"not null;index" json:"user_id"`
+	TransactionID uint      `gorm:"not null;index" json:"transaction_id"`
+	FileName      string    `gorm:"type:varchar(255);not null" json:"file_name"`
+	ContentType   string    `gorm:"type:varchar(100);not null" json:"content_type"`
+	Size          int64     `gorm:"not null" json:"size"`
+	StorageKey    string    `gorm:"type:varchar(255);not null" json:"-"`
+	ThumbnailKey  string    `gorm:"type:varchar(255)" json:"-"`
+	HasThumbnail  bool      `gorm:"default:false" json:"has_thumbnail"`
+	CreatedAt     time.Time `json:"created_at"`
+}
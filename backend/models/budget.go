@@ -0,0 +1,24 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Budget adalah batas pengeluaran satu category expense per periode,
+// misalnya "Makanan: 2.000.000 per bulan"
+type Budget struct {
+	ID         uint           `gorm:"primaryKey" json:"id"`
+	UserID     uint           `gorm:"not null;index" json:"user_id"`
+	CategoryID uint           `gorm:"not null;index" json:"category_id"`
+	Period     string         `gorm:"type:enum('weekly','monthly','yearly');not null;default:'monthly'" json:"period"`
+	Amount     Money          `gorm:"type:decimal(19,4);not null" json:"amount"`
+	Currency   string         `gorm:"type:varchar(3);not null;default:'IDR'" json:"currency"`
+	CreatedAt  time.Time      `json:"created_at"`
+	UpdatedAt  time.Time      `json:"updated_at"`
+	DeletedAt  gorm.DeletedAt `gorm:"index" json:"-"`
+
+	// Relations
+	Category *Category `gorm:"foreignKey:CategoryID" json:"category,omitempty"`
+}
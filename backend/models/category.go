@@ -6,14 +6,19 @@ import (
 	"gorm.io/gorm"
 )
 
+// Category milik satu user. Category dengan UserID 0 adalah default sistem
+// (dari seedCategories) yang tidak tampil langsung, tapi disalin saat Register.
 type Category struct {
 	ID        uint           `gorm:"primaryKey" json:"id"`
-	Name      string         `gorm:"type:varchar(50);not null" json:"name"`
-	Type      string         `gorm:"type:enum('income','expense');not null" json:"type"` // income atau expense
+	UserID    uint           `gorm:"not null;default:0;index" json:"user_id"` // 0 = default sistem, disalin ke setiap user baru
+	Name      string         `gorm:"type:varchar(50);not null;index:idx_categories_name_fulltext,class:FULLTEXT" json:"name"`
+	Type      string         `gorm:"type:enum('income','expense');not null" json:"type"` // income atau expense, sub-category ikut type parent
+	ParentID  *uint          `gorm:"index" json:"parent_id"`
 	CreatedAt time.Time      `json:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at"`
 	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
-	
+
 	// Relations
 	Transactions []Transaction `gorm:"foreignKey:CategoryID" json:"transactions,omitempty"`
-}
\ No newline at end of file
+	Children     []Category    `gorm:"foreignKey:ParentID" json:"children,omitempty"`
+}
@@ -0,0 +1,49 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Debt adalah pinjaman atau cicilan (KTA, paylater, cicilan kartu kredit).
+// Jadwal cicilan dihitung dari Principal, InterestRate, TermMonths dan Method,
+// pembayaran aktual dicatat sebagai DebtPayment.
+type Debt struct {
+	ID           uint           `gorm:"primaryKey" json:"id"`
+	UserID       uint           `gorm:"not null;index" json:"user_id"`
+	Name         string         `gorm:"type:varchar(100);not null" json:"name"`
+	Kind         string         `gorm:"type:enum('loan','installment','paylater','credit_card');not null;default:'loan'" json:"kind"`
+	Lender       string         `gorm:"type:varchar(100)" json:"lender"`
+	Principal    Money          `gorm:"type:decimal(19,4);not null" json:"principal"`
+	Currency     string         `gorm:"type:varchar(3);not null;default:'IDR'" json:"currency"`
+	InterestRate Rate           `gorm:"type:decimal(20,10);not null;default:0" json:"interest_rate"` // dalam persen, misalnya 1.75
+	RatePeriod   string         `gorm:"type:enum('yearly','monthly');not null;default:'yearly'" json:"rate_period"`
+	Method       string         `gorm:"type:enum('flat','effective','annuity');not null;default:'flat'" json:"method"`
+	TermMonths   int            `gorm:"not null" json:"term_months"`
+	StartDate    time.Time      `gorm:"type:date;not null" json:"start_date"` // jatuh tempo cicilan pertama
+	AccountID    *uint          `gorm:"index" json:"account_id"`              // account yang dipakai membayar (optional)
+	CreatedAt    time.Time      `json:"created_at"`
+	UpdatedAt    time.Time      `json:"updated_at"`
+	DeletedAt    gorm.DeletedAt `gorm:"index" json:"-"`
+
+	// Relations
+	Account  *Account      `gorm:"foreignKey:AccountID" json:"account,omitempty"`
+	Payments []DebtPayment `gorm:"foreignKey:DebtID" json:"payments,omitempty"`
+}
+
+// DebtPayment adalah pembayaran cicilan dalam currency debt, biasanya terhubung ke transaksi.
+// Pembagian pokok/bunga dihitung dari jadwal, bukan disimpan.
+type DebtPayment struct {
+	ID            uint      `gorm:"primaryKey" json:"id"`
+	UserID        uint      `gorm:"not null;index" json:"user_id"`
+	DebtID        uint      `gorm:"not null;index" json:"debt_id"`
+	TransactionID *uint     `gorm:"uniqueIndex" json:"transaction_id"` // satu transaksi hanya bisa jadi pembayaran satu debt
+	Amount        Money     `gorm:"type:decimal(19,4);not null" json:"amount"`
+	Date          time.Time `gorm:"type:date;not null" json:"date"`
+	Note          string    `gorm:"type:varchar(255)" json:"note"`
+	CreatedAt     time.Time `json:"created_at"`
+
+	// Relations
+	Transaction *Transaction `gorm:"foreignKey:TransactionID" json:"transaction,omitempty"`
+}
@@ -0,0 +1,34 @@
+package models
+
+import (
+	"time"
+)
+
+// Envelope adalah amplop budget untuk satu category expense (zero-based budgeting).
+// Uang dari income di-assign ke amplop per bulan, pengeluaran di category tersebut
+// mengurangi isi amplop, dan sisa/kekurangan dibawa ke bulan berikutnya sesuai RolloverMode:
+//   - full: sisa maupun overspending dibawa ke bulan berikutnya
+//   - positive: hanya sisa yang dibawa, overspending mengurangi ready to assign bulan berikutnya
+//   - none: amplop mulai dari nol tiap bulan, sisa kembali ke ready to assign dan overspending menguranginya
+type Envelope struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	UserID       uint      `gorm:"not null;uniqueIndex:idx_user_envelope" json:"user_id"`
+	CategoryID   uint      `gorm:"not null;uniqueIndex:idx_user_envelope" json:"category_id"`
+	RolloverMode string    `gorm:"type:enum('full','positive','none');not null;default:'full'" json:"rollover_mode"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+
+	// Relations
+	Category *Category `gorm:"foreignKey:CategoryID" json:"category,omitempty"`
+}
+
+// EnvelopeAssignment adalah jumlah yang di-assign ke amplop untuk satu bulan (dalam base currency user)
+type EnvelopeAssignment struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	UserID     uint      `gorm:"not null;index" json:"user_id"`
+	EnvelopeID uint      `gorm:"not null;uniqueIndex:idx_envelope_month" json:"envelope_id"`
+	Month      time.Time `gorm:"type:date;not null;uniqueIndex:idx_envelope_month" json:"month"` // tanggal 1 bulan tersebut
+	Amount     Money     `gorm:"type:decimal(19,4);not null" json:"amount"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
@@ -0,0 +1,20 @@
+package models
+
+import (
+	"time"
+)
+
+// ExchangeRate: 1 FromCurrency = Rate ToCurrency pada tanggal Date.
+// UserID 0 berarti rate global (misalnya hasil load file ECB saat startup),
+// rate milik user selalu didahulukan dibanding rate global.
+type ExchangeRate struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	UserID       uint      `gorm:"not null;default:0;uniqueIndex:idx_exchange_rate" json:"user_id"`
+	FromCurrency string    `gorm:"type:varchar(3);not null;uniqueIndex:idx_exchange_rate" json:"from_currency"`
+	ToCurrency   string    `gorm:"type:varchar(3);not null;uniqueIndex:idx_exchange_rate" json:"to_currency"`
+	Date         time.Time `gorm:"type:date;not null;uniqueIndex:idx_exchange_rate" json:"date"`
+	Rate         Rate      `gorm:"type:decimal(20,10);not null" json:"rate"`
+	Source       string    `gorm:"type:varchar(20);not null;default:'manual'" json:"source"` // manual, csv atau ecb
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
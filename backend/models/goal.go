@@ -0,0 +1,45 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Goal adalah target tabungan (motor, mudik Lebaran). Bisa dihubungkan ke account
+// tabungan atau category, dan progress-nya dihitung dari contribution.
+type Goal struct {
+	ID           uint           `gorm:"primaryKey" json:"id"`
+	UserID       uint           `gorm:"not null;index" json:"user_id"`
+	Name         string         `gorm:"type:varchar(100);not null" json:"name"`
+	TargetAmount Money          `gorm:"type:decimal(19,4);not null" json:"target_amount"`
+	Currency     string         `gorm:"type:varchar(3);not null;default:'IDR'" json:"currency"`
+	TargetDate   *time.Time     `gorm:"type:date" json:"target_date"`
+	AccountID    *uint          `gorm:"index" json:"account_id"`  // hanya transaksi di account ini yang bisa jadi contribution
+	CategoryID   *uint          `gorm:"index" json:"category_id"` // hanya transaksi di category ini yang bisa jadi contribution
+	Archived     bool           `gorm:"default:false" json:"archived"`
+	CreatedAt    time.Time      `json:"created_at"`
+	UpdatedAt    time.Time      `json:"updated_at"`
+	DeletedAt    gorm.DeletedAt `gorm:"index" json:"-"`
+
+	// Relations
+	Account       *Account           `gorm:"foreignKey:AccountID" json:"account,omitempty"`
+	Category      *Category          `gorm:"foreignKey:CategoryID" json:"category,omitempty"`
+	Contributions []GoalContribution `gorm:"foreignKey:GoalID" json:"contributions,omitempty"`
+}
+
+// GoalContribution adalah setoran (atau penarikan, kalau negatif) ke goal dalam currency goal.
+// Biasanya terhubung ke satu transaksi, tapi boleh juga input manual tanpa transaksi.
+type GoalContribution struct {
+	ID            uint      `gorm:"primaryKey" json:"id"`
+	UserID        uint      `gorm:"not null;index" json:"user_id"`
+	GoalID        uint      `gorm:"not null;uniqueIndex:idx_goal_transaction" json:"goal_id"`
+	TransactionID *uint     `gorm:"uniqueIndex:idx_goal_transaction" json:"transaction_id"`
+	Amount        Money     `gorm:"type:decimal(19,4);not null" json:"amount"`
+	Date          time.Time `gorm:"type:date;not null" json:"date"`
+	Note          string    `gorm:"type:varchar(255)" json:"note"`
+	CreatedAt     time.Time `json:"created_at"`
+
+	// Relations
+	Transaction *Transaction `gorm:"foreignKey:TransactionID" json:"transaction,omitempty"`
+}
@@ -0,0 +1,62 @@
+package models
+
+import (
+	"time"
+)
+
+// ImportBatch adalah satu kali upload file import (CSV, OFX, QIF, MT940, CAMT.053, e-wallet).
+// Baris hasil parsing disimpan di ImportRow untuk di-review dan baru masuk ke transactions
+// saat batch di-commit. Batch yang sudah di-commit bisa di-undo: semua transaksi dan
+// transfer yang dibuat batch itu dihapus dan batch kembali ke status staged.
+type ImportBatch struct {
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	UserID      uint       `gorm:"not null;index" json:"user_id"`
+	Source      string     `gorm:"type:varchar(30);not null" json:"source"` // csv, ofx, qif, mt940, camt.053, gopay, ...
+	FileName    string     `gorm:"type:varchar(255)" json:"file_name"`
+	AccountID   *uint      `gorm:"index" json:"account_id"`
+	Status      string     `gorm:"type:enum('staged','committed');not null;default:'staged';index" json:"status"`
+	CommittedAt *time.Time `json:"committed_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+
+	// Jumlah baris per status, diisi saat listing (tidak disimpan)
+	Counts map[string]int `gorm:"-" json:"counts,omitempty"`
+
+	// Relations
+	Account *Account    `gorm:"foreignKey:AccountID" json:"account,omitempty"`
+	Rows    []ImportRow `gorm:"foreignKey:BatchID" json:"rows,omitempty"`
+}
+
+// ImportRow adalah satu baris staging. Status awal new, duplicate (import_hash sudah pernah
+// di-import atau sedang di-stage di batch lain) atau conflict (ada transaksi dengan tanggal &
+// amount sama); user mengubahnya ke accepted/rejected. Hanya baris accepted yang di-commit.
+type ImportRow struct {
+	ID                uint      `gorm:"primaryKey" json:"id"`
+	BatchID           uint      `gorm:"not null;index" json:"batch_id"`
+	UserID            uint      `gorm:"not null;index" json:"user_id"`
+	Line              int       `json:"line"` // nomor baris/transaksi di file
+	Status            string    `gorm:"type:enum('new','duplicate','conflict','accepted','rejected');not null;default:'new';index" json:"status"`
+	Duplicate         string    `gorm:"type:varchar(10)" json:"duplicate,omitempty"` // exact, possible atau staged, hasil deteksi saat upload
+	DuplicateOf       *uint     `json:"duplicate_of,omitempty"`
+	ImportHash        string    `gorm:"type:varchar(64);index" json:"import_hash"`
+	AccountID         *uint     `json:"account_id"`
+	CategoryID        *uint     `json:"category_id"`
+	PayeeID           *uint     `json:"payee_id"`
+	TransferAccountID *uint     `json:"transfer_account_id"`                       // diisi = disimpan sebagai transfer, tanpa category
+	Transfer          bool      `gorm:"not null;default:false" json:"transfer"`    // file menandai baris ini top up / tarik saldo
+	Amount            Money     `gorm:"type:decimal(19,4);not null" json:"amount"` // selalu positif, arah di Kind
+	Currency          string    `gorm:"type:varchar(3);not null;default:'IDR'" json:"currency"`
+	Kind              string    `gorm:"type:enum('income','expense');not null" json:"kind"`
+	Description       string    `gorm:"type:text" json:"description"`
+	Payee             string    `gorm:"type:varchar(255)" json:"payee"`
+	Reference         string    `gorm:"type:varchar(100)" json:"reference,omitempty"`
+	Date              time.Time `gorm:"not null" json:"date"`
+	TransactionID     *uint     `json:"transaction_id"` // setelah commit; untuk transfer leg di account yang di-import
+	TransferID        *uint     `json:"transfer_id"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+
+	// Relations
+	Account  *Account  `gorm:"foreignKey:AccountID" json:"account,omitempty"`
+	Category *Category `gorm:"foreignKey:CategoryID" json:"category,omitempty"`
+}
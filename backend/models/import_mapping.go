@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+)
+
+// ImportMapping adalah pengaturan kolom CSV statement bank yang disimpan per user
+// (misalnya "BCA KlikBCA" atau "Mandiri Livin"), supaya upload berikutnya tidak perlu diatur ulang.
+// Kolom ditulis sebagai nama header (tidak peka huruf besar/kecil) atau nomor kolom mulai dari 1.
+type ImportMapping struct {
+	ID               uint   `gorm:"primaryKey" json:"id"`
+	UserID           uint   `gorm:"not null;uniqueIndex:idx_user_import_mapping" json:"user_id"`
+	Name             string `gorm:"type:varchar(100);not null;uniqueIndex:idx_user_import_mapping" json:"name"`
+	Delimiter        string `gorm:"type:varchar(1);not null;default:','" json:"delimiter"`
+	SkipRows         int    `gorm:"not null;default:0" json:"skip_rows"` // baris pembuka sebelum header (info rekening dsb)
+	HasHeader        bool   `gorm:"not null;default:true" json:"has_header"`
+	DateColumn       string `gorm:"type:varchar(100);not null" json:"date_column"`
+	DateFormat       string `gorm:"type:varchar(30);not null;default:'DD/MM/YYYY'" json:"date_format"` // token DD, D, MM, M, MMM, YYYY, YY
+	DecimalSeparator string `gorm:"type:varchar(1);not null;default:'.'" json:"decimal_separator"`
+
+	// signed: satu kolom amount, negatif = pengeluaran (invert_sign membalik, misalnya untuk kartu kredit)
+	// debit_credit: kolom debit (pengeluaran) dan credit (pemasukan) terpisah
+	// indicator: kolom amount positif + kolom penanda, pengeluaran kalau isinya debit_indicator
+	// (kolom penanda boleh sama dengan kolom amount, misalnya BCA "150,000.00 DB")
+	AmountMode      string `gorm:"type:enum('signed','debit_credit','indicator');not null;default:'signed'" json:"amount_mode"`
+	AmountColumn    string `gorm:"type:varchar(100)" json:"amount_column"`
+	InvertSign      bool   `gorm:"not null;default:false" json:"invert_sign"`
+	DebitColumn     string `gorm:"type:varchar(100)" json:"debit_column"`
+	CreditColumn    string `gorm:"type:varchar(100)" json:"credit_column"`
+	IndicatorColumn string `gorm:"type:varchar(100)" json:"indicator_column"`
+	DebitIndicator  string `gorm:"type:varchar(20)" json:"debit_indicator"`
+
+	DescriptionColumn string `gorm:"type:varchar(100)" json:"description_column"`
+	CategoryColumn    string `gorm:"type:varchar(100)" json:"category_column"` // dicocokkan dengan nama category user
+	PayeeColumn       string `gorm:"type:varchar(100)" json:"payee_column"`
+	CurrencyColumn    string `gorm:"type:varchar(100)" json:"currency_column"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
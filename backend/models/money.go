@@ -0,0 +1,283 @@
+package models
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Money menyimpan nominal uang secara exact sebagai integer dalam satuan
+// 1/10000 (4 desimal), sehingga 0.1 + 0.2 tetap 0.3 dan total IDR yang besar
+// tidak kehilangan presisi. Di database disimpan sebagai decimal(19,4),
+// di JSON ditulis sebagai angka desimal apa adanya (tanpa lewat float64).
+type Money int64
+
+// Rate menyimpan exchange rate secara exact dengan 10 desimal (decimal(20,10))
+type Rate int64
+
+const (
+	moneyDecimals = 4
+	rateDecimals  = 10
+
+	// MoneyScale: 1 unit mata uang = MoneyScale Money
+	MoneyScale = 10000
+)
+
+var errFixedOverflow = errors.New("value out of range")
+
+// Format angka yang diterima: desimal biasa dengan titik. Pecahan ("1/3") tidak diterima;
+// eksponen ("6.1e-05") hanya untuk rate karena sumber kurs kadang menulis rate kecil seperti itu.
+var (
+	moneyPattern = regexp.MustCompile(`^-?\d+(\.\d+)?$`)
+	ratePattern  = regexp.MustCompile(`^-?\d+(\.\d+)?([eE][-+]?\d+)?$`)
+)
+
+// Jumlah digit desimal per currency (ISO 4217). Rupiah dibulatkan ke rupiah
+// penuh karena pecahan sen sudah tidak beredar.
+var currencyDecimals = map[string]int{
+	"IDR": 0, "JPY": 0, "KRW": 0, "VND": 0, "CLP": 0, "ISK": 0, "UGX": 0, "XAF": 0, "XOF": 0,
+	"BHD": 3, "JOD": 3, "KWD": 3, "OMR": 3, "TND": 3, "LYD": 3, "IQD": 3,
+}
+
+// Jumlah digit desimal yang dipakai untuk pembulatan currency (default 2)
+func CurrencyDecimals(currency string) int {
+	if digits, ok := currencyDecimals[strings.ToUpper(currency)]; ok {
+		return digits
+	}
+	return 2
+}
+
+// ParseMoney membaca string desimal ("150000", "-12.345") secara exact,
+// dibulatkan ke 4 desimal
+func ParseMoney(s string) (Money, error) {
+	v, err := parseFixed(s, moneyDecimals, moneyPattern)
+	return Money(v), err
+}
+
+// MoneyFromRat membulatkan bilangan rasional ke Money (half away from zero)
+func MoneyFromRat(r *big.Rat) (Money, error) {
+	v, err := ratToFixed(r, moneyDecimals)
+	return Money(v), err
+}
+
+// Rat mengembalikan nilai Money sebagai bilangan rasional exact
+func (m Money) Rat() *big.Rat {
+	return big.NewRat(int64(m), MoneyScale)
+}
+
+// Round membulatkan Money ke jumlah desimal currency (half away from zero)
+func (m Money) Round(currency string) Money {
+	return m.RoundTo(CurrencyDecimals(currency))
+}
+
+// RoundTo membulatkan Money ke sejumlah digit desimal (0-4)
+func (m Money) RoundTo(decimals int) Money {
+	if decimals >= moneyDecimals || decimals < 0 {
+		return m
+	}
+	unit := int64(1)
+	for i := decimals; i < moneyDecimals; i++ {
+		unit *= 10
+	}
+	v := int64(m)
+	q, r := v/unit, v%unit
+	if r < 0 {
+		r = -r
+	}
+	if 2*r >= unit {
+		if v < 0 {
+			q--
+		} else {
+			q++
+		}
+	}
+	return Money(q * unit)
+}
+
+// Mul mengalikan Money dengan bilangan rasional (misalnya exchange rate)
+func (m Money) Mul(r *big.Rat) (Money, error) {
+	return MoneyFromRat(new(big.Rat).Mul(m.Rat(), r))
+}
+
+func (m Money) IsZero() bool {
+	return m == 0
+}
+
+// String menulis Money tanpa trailing zero, misalnya "150000" atau "-12.5"
+func (m Money) String() string {
+	return formatFixed(int64(m), moneyDecimals, -1)
+}
+
+// StringFixed menulis Money dengan jumlah desimal tetap, misalnya "12.50"
+func (m Money) StringFixed(decimals int) string {
+	return formatFixed(int64(m.RoundTo(decimals)), moneyDecimals, decimals)
+}
+
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// Terima angka JSON maupun string ("12.50"), tanpa konversi lewat float64
+func (m *Money) UnmarshalJSON(data []byte) error {
+	s := strings.TrimSpace(string(data))
+	if s == "null" {
+		return nil
+	}
+	if unquoted, err := strconv.Unquote(s); err == nil {
+		s = unquoted
+	}
+	v, err := ParseMoney(s)
+	if err != nil {
+		return fmt.Errorf("invalid money value %q", s)
+	}
+	*m = v
+	return nil
+}
+
+func (m *Money) Scan(value interface{}) error {
+	v, err := scanFixed(value, moneyDecimals, moneyPattern)
+	if err != nil {
+		return err
+	}
+	*m = Money(v)
+	return nil
+}
+
+func (m Money) Value() (driver.Value, error) {
+	return formatFixed(int64(m), moneyDecimals, moneyDecimals), nil
+}
+
+// ParseRate membaca exchange rate secara exact (10 desimal)
+func ParseRate(s string) (Rate, error) {
+	v, err := parseFixed(s, rateDecimals, ratePattern)
+	return Rate(v), err
+}
+
+// Rat mengembalikan exchange rate sebagai bilangan rasional exact
+func (r Rate) Rat() *big.Rat {
+	return new(big.Rat).SetFrac(big.NewInt(int64(r)), pow10(rateDecimals))
+}
+
+func (r Rate) String() string {
+	return formatFixed(int64(r), rateDecimals, -1)
+}
+
+func (r Rate) MarshalJSON() ([]byte, error) {
+	return []byte(r.String()), nil
+}
+
+func (r *Rate) UnmarshalJSON(data []byte) error {
+	s := strings.TrimSpace(string(data))
+	if s == "null" {
+		return nil
+	}
+	if unquoted, err := strconv.Unquote(s); err == nil {
+		s = unquoted
+	}
+	v, err := ParseRate(s)
+	if err != nil {
+		return fmt.Errorf("invalid rate value %q", s)
+	}
+	*r = v
+	return nil
+}
+
+func (r *Rate) Scan(value interface{}) error {
+	v, err := scanFixed(value, rateDecimals, ratePattern)
+	if err != nil {
+		return err
+	}
+	*r = Rate(v)
+	return nil
+}
+
+func (r Rate) Value() (driver.Value, error) {
+	return formatFixed(int64(r), rateDecimals, rateDecimals), nil
+}
+
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
+
+func parseFixed(s string, decimals int, pattern *regexp.Regexp) (int64, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, errors.New("empty value")
+	}
+	if !pattern.MatchString(s) {
+		return 0, fmt.Errorf("invalid decimal %q", s)
+	}
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return 0, fmt.Errorf("invalid decimal %q", s)
+	}
+	return ratToFixed(r, decimals)
+}
+
+// Bulatkan r ke sejumlah desimal (half away from zero) dan kembalikan sebagai integer berskala
+func ratToFixed(r *big.Rat, decimals int) (int64, error) {
+	scaled := new(big.Rat).Mul(r, new(big.Rat).SetInt(pow10(decimals)))
+	num, den := scaled.Num(), scaled.Denom()
+
+	q, rem := new(big.Int).QuoRem(num, den, new(big.Int))
+	rem.Abs(rem).Mul(rem, big.NewInt(2))
+	if rem.Cmp(den) >= 0 {
+		if num.Sign() < 0 {
+			q.Sub(q, big.NewInt(1))
+		} else {
+			q.Add(q, big.NewInt(1))
+		}
+	}
+
+	if !q.IsInt64() {
+		return 0, errFixedOverflow
+	}
+	return q.Int64(), nil
+}
+
+// Tulis integer berskala sebagai desimal. fixed < 0 berarti buang trailing zero.
+func formatFixed(v int64, decimals int, fixed int) string {
+	neg := v < 0
+	digits := new(big.Int).Abs(big.NewInt(v)).String()
+	if len(digits) <= decimals {
+		digits = strings.Repeat("0", decimals-len(digits)+1) + digits
+	}
+
+	intPart := digits[:len(digits)-decimals]
+	fracPart := digits[len(digits)-decimals:]
+	if fixed < 0 {
+		fracPart = strings.TrimRight(fracPart, "0")
+	} else if fixed < len(fracPart) {
+		fracPart = fracPart[:fixed]
+	}
+
+	s := intPart
+	if fracPart != "" {
+		s += "." + fracPart
+	}
+	if neg && strings.Trim(s, "0.") != "" {
+		s = "-" + s
+	}
+	return s
+}
+
+func scanFixed(value interface{}, decimals int, pattern *regexp.Regexp) (int64, error) {
+	switch v := value.(type) {
+	case nil:
+		return 0, nil
+	case []byte:
+		return parseFixed(string(v), decimals, pattern)
+	case string:
+		return parseFixed(v, decimals, pattern)
+	case int64:
+		return ratToFixed(new(big.Rat).SetInt64(v), decimals)
+	case float64:
+		// Fallback untuk driver yang mengembalikan float, dibaca lewat representasi desimal terpendek
+		return parseFixed(strconv.FormatFloat(v, 'f', -1, 64), decimals, pattern)
+	}
+	return 0, fmt.Errorf("cannot scan %T into fixed-point value", value)
+}
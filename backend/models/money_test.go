@@ -0,0 +1,128 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseMoney(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    Money
+		wantErr bool
+	}{
+		{input: "150000", want: 150000 * MoneyScale},
+		{input: "-12.345", want: -123450},
+		{input: " 0.1 ", want: 1000},
+		{input: "1.23456", want: 12346}, // dibulatkan ke 4 desimal
+		{input: "-1.23455", want: -12346},
+		{input: "", wantErr: true},
+		{input: "1e3", wantErr: true},
+		{input: "1/3", wantErr: true},
+		{input: "1,500", wantErr: true},
+		{input: ".5", wantErr: true},
+		{input: "99999999999999999999", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseMoney(tt.input)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseMoney(%q) = %v, want error", tt.input, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseMoney(%q) error: %v", tt.input, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseMoney(%q) = %d, want %d", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestMoneyRound(t *testing.T) {
+	tests := []struct {
+		amount   string
+		currency string
+		want     string
+	}{
+		{"1500.5", "IDR", "1501"},
+		{"1500.4999", "IDR", "1500"},
+		{"-1500.5", "IDR", "-1501"},
+		{"12.345", "USD", "12.35"},
+		{"12.3449", "USD", "12.34"},
+		{"-12.345", "usd", "-12.35"},
+		{"1.2345", "KWD", "1.235"},
+		{"0.0049", "EUR", "0"},
+		{"-0.0049", "EUR", "0"},
+	}
+
+	for _, tt := range tests {
+		m, err := ParseMoney(tt.amount)
+		if err != nil {
+			t.Fatalf("ParseMoney(%q) error: %v", tt.amount, err)
+		}
+		if got := m.Round(tt.currency).String(); got != tt.want {
+			t.Errorf("%s %s rounded = %s, want %s", tt.amount, tt.currency, got, tt.want)
+		}
+	}
+}
+
+func TestMoneyJSON(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    Money
+		wantErr bool
+	}{
+		{input: `12.5`, want: 125000},
+		{input: `"12.50"`, want: 125000},
+		{input: `null`, want: 0},
+		{input: `1e3`, wantErr: true},
+		{input: `"abc"`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		var m Money
+		err := json.Unmarshal([]byte(tt.input), &m)
+		if tt.wantErr != (err != nil) {
+			t.Errorf("Unmarshal(%s) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && m != tt.want {
+			t.Errorf("Unmarshal(%s) = %d, want %d", tt.input, m, tt.want)
+		}
+	}
+
+	out, err := json.Marshal(Money(-125000))
+	if err != nil || string(out) != "-12.5" {
+		t.Errorf("Marshal(-12.5) = %s, %v", out, err)
+	}
+	if got := Money(125000).StringFixed(2); got != "12.50" {
+		t.Errorf("StringFixed(2) = %s, want 12.50", got)
+	}
+}
+
+func TestParseRate(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{input: "15750.25", want: "15750.25"},
+		{input: "6.1e-05", want: "0.000061"},
+		{input: "1/3", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseRate(tt.input)
+		if tt.wantErr != (err != nil) {
+			t.Errorf("ParseRate(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && got.String() != tt.want {
+			t.Errorf("ParseRate(%q) = %s, want %s", tt.input, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package models
+
+import (
+	"time"
+)
+
+// NotificationTemplate adalah regex untuk membaca SMS/email notifikasi transaksi dari bank.
+// Template sistem (user_id = 0) di-seed untuk BCA, Mandiri, BNI, BRI dan Jenius; user bisa
+// menambah template sendiri yang dicoba lebih dulu.
+//
+// Pattern memakai named group: amount (wajib), merchant, description, date, direction
+// (kata seperti "masuk"/"keluar" atau "CR"/"DB"), reference dan balance.
+type NotificationTemplate struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	UserID     uint      `gorm:"not null;default:0;index" json:"user_id"` // 0 = template sistem
+	Bank       string    `gorm:"type:varchar(50);not null;index" json:"bank"`
+	Name       string    `gorm:"type:varchar(100);not null" json:"name"`
+	Pattern    string    `gorm:"type:text;not null" json:"pattern"`
+	Kind       string    `gorm:"type:enum('income','expense');not null;default:'expense'" json:"kind"` // dipakai kalau tidak ada group direction
+	DateFormat string    `gorm:"type:varchar(30)" json:"date_format"`                                  // format group date, kosong = ditebak; tanpa group date dipakai tanggal pesan diterima
+	Active     bool      `gorm:"not null;default:true" json:"active"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// TransactionDraft adalah transaksi hasil baca notifikasi bank yang menunggu review user.
+// Saat di-accept dibuat Transaction biasa dan TransactionID terisi; draft yang di-reject
+// tetap disimpan supaya pesan yang sama tidak jadi draft lagi.
+type TransactionDraft struct {
+	ID            uint      `gorm:"primaryKey" json:"id"`
+	UserID        uint      `gorm:"not null;index" json:"user_id"`
+	Source        string    `gorm:"type:enum('sms','email');not null" json:"source"`
+	Bank          string    `gorm:"type:varchar(50)" json:"bank"`
+	TemplateID    *uint     `json:"template_id"`
+	Sender        string    `gorm:"type:varchar(255)" json:"sender"`
+	RawText       string    `gorm:"type:text;not null" json:"raw_text"`
+	ReceivedAt    time.Time `gorm:"not null" json:"received_at"`
+	MessageHash   string    `gorm:"type:varchar(64);not null;index" json:"-"` // sidik pesan, supaya upload ulang tidak dobel
+	AccountID     *uint     `gorm:"index" json:"account_id"`
+	CategoryID    *uint     `json:"category_id"`
+	PayeeID       *uint     `json:"payee_id"`
+	Amount        Money     `gorm:"type:decimal(19,4);not null" json:"amount"` // selalu positif, arah di Kind
+	Currency      string    `gorm:"type:varchar(3);not null;default:'IDR'" json:"currency"`
+	Kind          string    `gorm:"type:enum('income','expense');not null" json:"kind"`
+	Description   string    `gorm:"type:text" json:"description"`
+	Merchant      string    `gorm:"type:varchar(255)" json:"merchant"`
+	Reference     string    `gorm:"type:varchar(100)" json:"reference,omitempty"`
+	Balance       *Money    `gorm:"type:decimal(19,4)" json:"balance,omitempty"` // saldo setelah transaksi kalau disebut di pesan
+	Date          time.Time `gorm:"not null" json:"date"`
+	DuplicateOf   *uint     `json:"duplicate_of,omitempty"` // transaksi yang mungkin sama (tanggal & amount)
+	Status        string    `gorm:"type:enum('pending','accepted','rejected');not null;default:'pending';index" json:"status"`
+	TransactionID *uint     `json:"transaction_id"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+
+	// Relations
+	Account  *Account  `gorm:"foreignKey:AccountID" json:"account,omitempty"`
+	Category *Category `gorm:"foreignKey:CategoryID" json:"category,omitempty"`
+	Payee    *Payee    `gorm:"foreignKey:PayeeID" json:"payee,omitempty"`
+}
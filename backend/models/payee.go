@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+)
+
+// Payee adalah merchant/penerima per user (misalnya "Indomaret"). Alias dipakai untuk
+// mengenali payee dari description bebas seperti "INDOMARET 123" atau "Indomaret Jl. Sudirman".
+type Payee struct {
+	ID                uint      `gorm:"primaryKey" json:"id"`
+	UserID            uint      `gorm:"not null;uniqueIndex:idx_user_payee" json:"user_id"`
+	Name              string    `gorm:"type:varchar(100);not null;uniqueIndex:idx_user_payee;index:idx_payees_name_fulltext,class:FULLTEXT" json:"name"`
+	DefaultCategoryID *uint     `gorm:"index" json:"default_category_id"` // dipakai kalau transaksi dibuat tanpa category
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+
+	// Relations
+	DefaultCategory *Category    `gorm:"foreignKey:DefaultCategoryID" json:"default_category,omitempty"`
+	Aliases         []PayeeAlias `gorm:"foreignKey:PayeeID" json:"aliases,omitempty"`
+}
+
+// PayeeAlias adalah potongan teks (lowercase) yang kalau muncul di description berarti payee tersebut
+type PayeeAlias struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	UserID    uint      `gorm:"not null;uniqueIndex:idx_user_payee_alias" json:"-"`
+	PayeeID   uint      `gorm:"not null;index" json:"payee_id"`
+	Pattern   string    `gorm:"type:varchar(100);not null;uniqueIndex:idx_user_payee_alias" json:"pattern"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// RecurringTransaction adalah template transaksi berulang (gaji, sewa, tagihan).
+// Aturan pengulangannya kompatibel dengan RRULE RFC 5545 dan tersimpan juga
+// sebagai string di kolom RRule. Scheduler membuat Transaction untuk setiap
+// occurrence yang sudah jatuh tempo dan memajukan NextDate.
+type RecurringTransaction struct {
+	ID          uint           `gorm:"primaryKey" json:"id"`
+	UserID      uint           `gorm:"not null;index" json:"user_id"`
+	AccountID   *uint          `json:"account_id"`
+	CategoryID  uint           `gorm:"not null" json:"category_id"`
+	Amount      Money          `gorm:"type:decimal(19,4);not null" json:"amount"`
+	Currency    string         `gorm:"type:varchar(3);not null;default:'IDR'" json:"currency"`
+	Description string         `gorm:"type:text" json:"description"`
+	Frequency   string         `gorm:"type:enum('daily','weekly','monthly','yearly');not null" json:"frequency"`
+	Interval    int            `gorm:"not null;default:1" json:"interval"`
+	Weekdays    string         `gorm:"type:varchar(30)" json:"weekdays"` // kode hari dipisah koma, misalnya "MO,FR"
+	MonthDay    int            `gorm:"not null;default:0" json:"month_day"`
+	SetPos      int            `gorm:"not null;default:0" json:"set_pos"` // ke-n weekday dalam bulan, -1 = terakhir
+	StartDate   time.Time      `gorm:"type:date;not null" json:"start_date"`
+	EndDate     *time.Time     `gorm:"type:date" json:"end_date"`
+	Count       int            `gorm:"not null;default:0" json:"count"` // 0 = tanpa batas
+	RRule       string         `gorm:"type:varchar(255)" json:"rrule"`
+	NextDate    *time.Time     `gorm:"type:date;index" json:"next_date"` // nil kalau sudah selesai
+	Active      bool           `gorm:"not null;default:true" json:"active"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
+
+	// Relations
+	Category *Category `gorm:"foreignKey:CategoryID" json:"category,omitempty"`
+	Account  *Account  `gorm:"foreignKey:AccountID" json:"account,omitempty"`
+}
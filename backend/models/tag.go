@@ -0,0 +1,18 @@
+package models
+
+import (
+	"time"
+)
+
+// Tag bebas milik user (misalnya "trip-bali" atau "reimbursable"),
+// dipasang ke transaksi lewat tabel many-to-many transaction_tags
+type Tag struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	UserID    uint      `gorm:"not null;uniqueIndex:idx_user_tag" json:"user_id"`
+	Name      string    `gorm:"type:varchar(50);not null;uniqueIndex:idx_user_tag" json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// Relations
+	Transactions []Transaction `gorm:"many2many:transaction_tags" json:"transactions,omitempty"`
+}
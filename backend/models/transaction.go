@@ -7,17 +7,38 @@ import (
 )
 
 type Transaction struct {
-	ID          uint           `gorm:"primaryKey" json:"id"`
-	UserID      uint           `gorm:"not null" json:"user_id"`
-	CategoryID  uint           `gorm:"not null" json:"category_id"`
-	Amount      float64        `gorm:"type:decimal(15,2);not null" json:"amount"`
-	Description string         `gorm:"type:text" json:"description"`
-	Date        time.Time      `gorm:"not null" json:"date"`
-	CreatedAt   time.Time      `json:"created_at"`
-	UpdatedAt   time.Time      `json:"updated_at"`
-	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
-	
+	ID            uint           `gorm:"primaryKey" json:"id"`
+	UserID        uint           `gorm:"not null" json:"user_id"`
+	AccountID     *uint          `gorm:"index" json:"account_id"`
+	CategoryID    *uint          `gorm:"index" json:"category_id"` // nil untuk leg transfer
+	TransferID    *uint          `gorm:"index" json:"transfer_id"`
+	PayeeID       *uint          `gorm:"index" json:"payee_id"`
+	RecurringID   *uint          `gorm:"uniqueIndex:idx_recurring_occurrence" json:"recurring_id"`
+	RecurringDate *time.Time     `gorm:"type:date;uniqueIndex:idx_recurring_occurrence" json:"recurring_date,omitempty"` // unik per template supaya tidak double-post
+	Amount        Money          `gorm:"type:decimal(19,4);not null" json:"amount"`
+	Currency      string         `gorm:"type:varchar(3);not null;default:'IDR'" json:"currency"`
+	Description   string         `gorm:"type:text;index:idx_transactions_description_fulltext,class:FULLTEXT" json:"description"`
+	Date          time.Time      `gorm:"not null" json:"date"`
+	ImportHash    string         `gorm:"type:varchar(64);index" json:"import_hash,omitempty"` // sidik baris statement yang di-import, untuk deteksi duplikat
+	ImportBatchID *uint          `gorm:"index" json:"import_batch_id,omitempty"`              // batch import yang membuat transaksi ini (untuk undo)
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+	DeletedAt     gorm.DeletedAt `gorm:"index" json:"-"`
+
+	// Amount dalam base currency user, diisi saat listing (tidak disimpan)
+	BaseAmount *Money `gorm:"-" json:"base_amount,omitempty"`
+	// Potongan description dengan kata yang cocok dibungkus <mark>, diisi saat search (q=)
+	Highlight string `gorm:"-" json:"highlight,omitempty"`
+
 	// Relations
-	User     User     `gorm:"foreignKey:UserID" json:"user,omitempty"`
-	Category Category `gorm:"foreignKey:CategoryID" json:"category,omitempty"`
-}
\ No newline at end of file
+	User     User      `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	Category *Category `gorm:"foreignKey:CategoryID" json:"category,omitempty"`
+	Account  *Account  `gorm:"foreignKey:AccountID" json:"account,omitempty"`
+	Payee    *Payee    `gorm:"foreignKey:PayeeID" json:"payee,omitempty"`
+
+	// Kalau ada split, category & type diambil per baris split (CategoryID induk = split pertama)
+	Splits []TransactionSplit `gorm:"foreignKey:TransactionID" json:"splits,omitempty"`
+	Tags   []Tag              `gorm:"many2many:transaction_tags" json:"tags,omitempty"`
+
+	Attachments []Attachment `gorm:"foreignKey:TransactionID" json:"attachments,omitempty"`
+}
@@ -0,0 +1,21 @@
+package models
+
+import (
+	"time"
+)
+
+// TransactionSplit adalah satu baris pecahan transaksi (misalnya satu struk
+// supermarket untuk "Makanan" dan "Belanja"). Total amount semua split
+// harus sama dengan amount transaksi induknya.
+type TransactionSplit struct {
+	ID            uint      `gorm:"primaryKey" json:"id"`
+	TransactionID uint      `gorm:"not null;index" json:"transaction_id"`
+	CategoryID    uint      `gorm:"not null;index" json:"category_id"`
+	Amount        Money     `gorm:"type:decimal(19,4);not null" json:"amount"`
+	Memo          string    `gorm:"type:varchar(255)" json:"memo"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+
+	// Relations
+	Category *Category `gorm:"foreignKey:CategoryID" json:"category,omitempty"`
+}
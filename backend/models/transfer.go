@@ -0,0 +1,31 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Transfer memindahkan uang antar account milik user yang sama.
+// Setiap transfer punya dua leg di tabel transactions (keluar dari FromAccount
+// dengan amount negatif, masuk ke ToAccount dengan amount positif) tanpa
+// category, sehingga tidak ikut dihitung sebagai income maupun expense.
+type Transfer struct {
+	ID            uint           `gorm:"primaryKey" json:"id"`
+	UserID        uint           `gorm:"not null;index" json:"user_id"`
+	FromAccountID uint           `gorm:"not null" json:"from_account_id"`
+	ToAccountID   uint           `gorm:"not null" json:"to_account_id"`
+	Amount        Money          `gorm:"type:decimal(19,4);not null" json:"amount"`    // dalam currency FromAccount
+	ToAmount      Money          `gorm:"type:decimal(19,4);not null" json:"to_amount"` // dalam currency ToAccount
+	Description   string         `gorm:"type:text" json:"description"`
+	Date          time.Time      `gorm:"not null" json:"date"`
+	ImportBatchID *uint          `gorm:"index" json:"import_batch_id,omitempty"` // top up / tarik saldo dari batch import
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+	DeletedAt     gorm.DeletedAt `gorm:"index" json:"-"`
+
+	// Relations
+	FromAccount  Account       `gorm:"foreignKey:FromAccountID" json:"from_account,omitempty"`
+	ToAccount    Account       `gorm:"foreignKey:ToAccountID" json:"to_account,omitempty"`
+	Transactions []Transaction `gorm:"foreignKey:TransferID" json:"transactions,omitempty"`
+}
@@ -7,14 +7,16 @@ import (
 )
 
 type User struct {
-	ID        uint           `gorm:"primaryKey" json:"id"`
-	Name      string         `gorm:"type:varchar(100);not null" json:"name"`
-	Email     string         `gorm:"type:varchar(100);unique;not null" json:"email"`
-	Password  string         `gorm:"type:varchar(255);not null" json:"-"` // "-" agar password tidak muncul di JSON
-	CreatedAt time.Time      `json:"created_at"`
-	UpdatedAt time.Time      `json:"updated_at"`
-	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
-	
+	ID           uint           `gorm:"primaryKey" json:"id"`
+	Name         string         `gorm:"type:varchar(100);not null" json:"name"`
+	Email        string         `gorm:"type:varchar(100);unique;not null" json:"email"`
+	Password     string         `gorm:"type:varchar(255);not null" json:"-"` // "-" agar password tidak muncul di JSON
+	BaseCurrency string         `gorm:"type:varchar(3);not null;default:'IDR'" json:"base_currency"`
+	CreatedAt    time.Time      `json:"created_at"`
+	UpdatedAt    time.Time      `json:"updated_at"`
+	DeletedAt    gorm.DeletedAt `gorm:"index" json:"-"`
+
 	// Relations
 	Transactions []Transaction `gorm:"foreignKey:UserID" json:"transactions,omitempty"`
-}
\ No newline at end of file
+	Accounts     []Account     `gorm:"foreignKey:UserID" json:"accounts,omitempty"`
+}
@@ -22,6 +22,7 @@ func SetupRoutes(app *fiber.App) {
 	// Profile
 	protected.Get("/profile", controllers.GetProfile)
 	protected.Put("/profile/password", controllers.ChangePassword)
+	protected.Put("/profile/base-currency", controllers.UpdateBaseCurrency)
 	protected.Delete("/profile/account", controllers.DeleteAccount)
 	protected.Post("/ai/insight", controllers.GetFinancialInsight)
 
@@ -31,13 +32,152 @@ func SetupRoutes(app *fiber.App) {
 	categories.Post("/", controllers.CreateCategory)
 	categories.Put("/:id", controllers.UpdateCategory)
 	categories.Delete("/:id", controllers.DeleteCategory)
+	categories.Post("/:id/merge", controllers.MergeCategory)
+
+	// Accounts (bank, cash, kartu kredit, e-wallet)
+	accounts := protected.Group("/accounts")
+	accounts.Get("/", controllers.GetAccounts)
+	accounts.Get("/:id", controllers.GetAccount)
+	accounts.Post("/", controllers.CreateAccount)
+	accounts.Put("/:id", controllers.UpdateAccount)
+	accounts.Delete("/:id", controllers.DeleteFinancialAccount)
+
+	// Transfers antar account (tidak dihitung income/expense)
+	transfers := protected.Group("/transfers")
+	transfers.Get("/", controllers.GetTransfers)
+	transfers.Get("/:id", controllers.GetTransfer)
+	transfers.Post("/", controllers.CreateTransfer)
+	transfers.Put("/:id", controllers.UpdateTransfer)
+	transfers.Delete("/:id", controllers.DeleteTransfer)
+
+	// Exchange rates (input manual atau import file CSV/ECB XML)
+	exchangeRates := protected.Group("/exchange-rates")
+	exchangeRates.Get("/", controllers.GetExchangeRates)
+	exchangeRates.Post("/", controllers.CreateExchangeRate)
+	exchangeRates.Post("/import", controllers.ImportExchangeRates)
+	exchangeRates.Delete("/:id", controllers.DeleteExchangeRate)
+
+	// Recurring transactions (gaji, sewa, tagihan bulanan)
+	recurring := protected.Group("/recurring")
+	recurring.Get("/", controllers.GetRecurringTransactions)
+	recurring.Get("/:id", controllers.GetRecurringTransaction)
+	recurring.Get("/:id/preview", controllers.PreviewRecurringTransaction)
+	recurring.Post("/", controllers.CreateRecurringTransaction)
+	recurring.Put("/:id", controllers.UpdateRecurringTransaction)
+	recurring.Delete("/:id", controllers.DeleteRecurringTransaction)
+
+	// Tags bebas untuk transaksi ("trip-bali", "reimbursable")
+	tags := protected.Group("/tags")
+	tags.Get("/", controllers.GetTags)
+	tags.Post("/", controllers.CreateTag)
+	tags.Put("/:id", controllers.UpdateTag)
+	tags.Post("/:id/merge", controllers.MergeTag)
+	tags.Delete("/:id", controllers.DeleteTag)
+
+	// Payees / merchant (alias, default category, ringkasan pengeluaran)
+	payees := protected.Group("/payees")
+	payees.Get("/", controllers.GetPayees)
+	payees.Get("/summary", controllers.GetPayeeSummary)
+	payees.Get("/:id", controllers.GetPayee)
+	payees.Get("/:id/summary", controllers.GetPayeeSpending)
+	payees.Post("/", controllers.CreatePayee)
+	payees.Put("/:id", controllers.UpdatePayee)
+	payees.Post("/:id/merge", controllers.MergePayee)
+	payees.Delete("/:id", controllers.DeletePayee)
+
+	// Budgets per category (progress: spent/remaining per periode)
+	budgets := protected.Group("/budgets")
+	budgets.Get("/", controllers.GetBudgets)
+	budgets.Get("/progress", controllers.GetBudgetProgress)
+	budgets.Get("/:id", controllers.GetBudget)
+	budgets.Post("/", controllers.CreateBudget)
+	budgets.Put("/:id", controllers.UpdateBudget)
+	budgets.Delete("/:id", controllers.DeleteBudget)
+
+	// Envelope budgeting (assign income ke amplop per bulan, dengan rollover)
+	envelopes := protected.Group("/envelopes")
+	envelopes.Get("/", controllers.GetEnvelopes)
+	envelopes.Get("/month", controllers.GetEnvelopeMonth)
+	envelopes.Post("/", controllers.CreateEnvelope)
+	envelopes.Put("/:id", controllers.UpdateEnvelope)
+	envelopes.Put("/:id/assign", controllers.AssignEnvelope)
+	envelopes.Delete("/:id", controllers.DeleteEnvelope)
+
+	// Savings goals (target tabungan dan contribution)
+	goals := protected.Group("/goals")
+	goals.Get("/", controllers.GetGoals)
+	goals.Get("/:id", controllers.GetGoal)
+	goals.Get("/:id/progress", controllers.GetGoalProgress)
+	goals.Post("/", controllers.CreateGoal)
+	goals.Put("/:id", controllers.UpdateGoal)
+	goals.Delete("/:id", controllers.DeleteGoal)
+	goals.Post("/:id/contributions", controllers.AddGoalContribution)
+	goals.Delete("/:id/contributions/:contributionId", controllers.DeleteGoalContribution)
+
+	// Debts & cicilan (jadwal amortisasi, pembayaran, report)
+	debts := protected.Group("/debts")
+	debts.Get("/", controllers.GetDebts)
+	debts.Get("/:id", controllers.GetDebt)
+	debts.Get("/:id/schedule", controllers.GetDebtSchedule)
+	debts.Get("/:id/report", controllers.GetDebtReport)
+	debts.Post("/", controllers.CreateDebt)
+	debts.Put("/:id", controllers.UpdateDebt)
+	debts.Delete("/:id", controllers.DeleteDebt)
+	debts.Post("/:id/payments", controllers.AddDebtPayment)
+	debts.Delete("/:id/payments/:paymentId", controllers.DeleteDebtPayment)
+
+	// Import statement bank (CSV dengan mapping tersimpan, OFX/QFX, QIF, MT940, CAMT.053, e-wallet).
+	// Upload masuk ke batch staging (dry_run=true = preview saja), direview per baris, lalu di-commit.
+	imports := protected.Group("/import")
+	imports.Get("/mappings", controllers.GetImportMappings)
+	imports.Post("/mappings", controllers.CreateImportMapping)
+	imports.Put("/mappings/:id", controllers.UpdateImportMapping)
+	imports.Delete("/mappings/:id", controllers.DeleteImportMapping)
+	imports.Post("/csv/preview", controllers.PreviewCSVImport)
+	imports.Post("/ofx/preview", controllers.PreviewOFXImport) // OFX 1.x/2.x dan QFX
+	imports.Post("/qif/preview", controllers.PreviewQIFImport)
+	imports.Post("/mt940/preview", controllers.PreviewMT940Import)
+	imports.Post("/camt/preview", controllers.PreviewCAMTImport)
+	imports.Post("/ewallet/:provider/preview", controllers.PreviewEwalletImport) // gopay, ovo, dana, shopeepay, tokopedia
+	imports.Get("/batches", controllers.GetImportBatches)
+	imports.Get("/batches/:id", controllers.GetImportBatch)
+	imports.Delete("/batches/:id", controllers.DeleteImportBatch)
+	imports.Put("/batches/:id/rows/:rowId", controllers.UpdateImportRow)
+	imports.Post("/batches/:id/accept", controllers.AcceptImportRows)
+	imports.Post("/batches/:id/reject", controllers.RejectImportRows)
+	imports.Post("/batches/:id/commit", controllers.CommitImportBatch)
+	imports.Post("/batches/:id/undo", controllers.UndoImportBatch)
+
+	// Notifikasi bank (SMS/email) -> draft transaksi untuk di-review
+	notifications := protected.Group("/notifications")
+	notifications.Post("/ingest", controllers.IngestNotifications) // JSON text/messages atau multipart file .eml/mbox
+	notifications.Get("/templates", controllers.GetNotificationTemplates)
+	notifications.Post("/templates", controllers.CreateNotificationTemplate)
+	notifications.Post("/templates/test", controllers.TestNotificationTemplate)
+	notifications.Put("/templates/:id", controllers.UpdateNotificationTemplate)
+	notifications.Delete("/templates/:id", controllers.DeleteNotificationTemplate)
+	notifications.Get("/drafts", controllers.GetTransactionDrafts)
+	notifications.Put("/drafts/:id", controllers.UpdateTransactionDraft)
+	notifications.Post("/drafts/:id/accept", controllers.AcceptTransactionDraft)
+	notifications.Post("/drafts/:id/reject", controllers.RejectTransactionDraft)
+	notifications.Delete("/drafts/:id", controllers.DeleteTransactionDraft)
 
 	// Transactions
 	transactions := protected.Group("/transactions")
 	transactions.Get("/", controllers.GetTransactions)
-	transactions.Get("/balance", controllers.GetBalance) // Endpoint khusus untuk balance
+	transactions.Get("/balance", controllers.GetBalance)        // Endpoint khusus untuk balance
+	transactions.Get("/export", controllers.ExportTransactions) // CSV/XLSX, filter sama dengan GET /transactions
 	transactions.Get("/:id", controllers.GetTransaction)
 	transactions.Post("/", controllers.CreateTransaction)
+	transactions.Post("/bulk", controllers.BulkTransactions) // create/update/delete banyak transaksi sekaligus
 	transactions.Put("/:id", controllers.UpdateTransaction)
 	transactions.Delete("/:id", controllers.DeleteTransaction)
+	transactions.Get("/:id/attachments", controllers.GetAttachments)
+	transactions.Post("/:id/attachments", controllers.UploadAttachment)
+
+	// Attachments (foto struk, invoice PDF)
+	attachments := protected.Group("/attachments")
+	attachments.Get("/:id", controllers.DownloadAttachment)
+	attachments.Get("/:id/thumbnail", controllers.GetAttachmentThumbnail)
+	attachments.Delete("/:id", controllers.DeleteAttachment)
 }
@@ -0,0 +1,116 @@
+package scheduler
+
+import (
+	"finance-tracker-backend/config"
+	"finance-tracker-backend/models"
+	"finance-tracker-backend/utils"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// StartRecurring menjalankan scheduler recurring transaction di background:
+// langsung sekali saat start (mengejar occurrence yang terlewat selama server mati),
+// lalu setiap interval.
+func StartRecurring(interval time.Duration) {
+	go func() {
+		runRecurring()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			runRecurring()
+		}
+	}()
+	log.Printf("Recurring transaction scheduler started (every %s)", interval)
+}
+
+func runRecurring() {
+	posted, err := MaterializeDue(time.Now())
+	if err != nil {
+		log.Println("Recurring scheduler error:", err)
+		return
+	}
+	if posted > 0 {
+		log.Printf("Recurring scheduler posted %d transaction(s)", posted)
+	}
+}
+
+// MaterializeDue membuat transaksi untuk semua template aktif yang NextDate-nya sudah lewat
+func MaterializeDue(now time.Time) (int, error) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	var ids []uint
+	if err := config.DB.Model(&models.RecurringTransaction{}).
+		Where("active = ? AND next_date IS NOT NULL AND next_date <= ?", true, today).
+		Pluck("id", &ids).Error; err != nil {
+		return 0, err
+	}
+
+	total := 0
+	for _, id := range ids {
+		posted, err := MaterializeRecurring(id, today)
+		if err != nil {
+			log.Printf("Recurring #%d: %v", id, err)
+			continue
+		}
+		total += posted
+	}
+	return total, nil
+}
+
+// MaterializeRecurring membuat transaksi untuk occurrence template yang jatuh tempo
+// sampai tanggal today, lalu memajukan NextDate. Idempotent: template dikunci selama
+// proses dan setiap occurrence unik per (recurring_id, recurring_date), jadi restart
+// atau beberapa instance server tidak akan double-post.
+func MaterializeRecurring(id uint, today time.Time) (int, error) {
+	posted := 0
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		var template models.RecurringTransaction
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&template, id).Error; err != nil {
+			return err
+		}
+		if !template.Active || template.NextDate == nil || template.NextDate.After(today) {
+			return nil
+		}
+
+		rule, err := utils.RecurrenceOf(&template)
+		if err != nil {
+			return err
+		}
+
+		for _, day := range rule.Between(*template.NextDate, today, 0) {
+			occurrence := day
+			transaction := models.Transaction{
+				UserID:        template.UserID,
+				AccountID:     template.AccountID,
+				CategoryID:    &template.CategoryID,
+				RecurringID:   &template.ID,
+				RecurringDate: &occurrence,
+				Amount:        template.Amount,
+				Currency:      template.Currency,
+				Description:   template.Description,
+				Date:          occurrence,
+			}
+			result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&transaction)
+			if result.Error != nil {
+				return result.Error
+			}
+			posted += int(result.RowsAffected)
+		}
+
+		return tx.Model(&template).Update("next_date", NextOccurrence(rule, today.AddDate(0, 0, 1))).Error
+	})
+	return posted, err
+}
+
+// NextOccurrence mengembalikan occurrence pertama pada atau setelah from, nil kalau rule sudah selesai
+func NextOccurrence(rule utils.Recurrence, from time.Time) *time.Time {
+	next := rule.Next(from, 1)
+	if len(next) == 0 {
+		return nil
+	}
+	return &next[0]
+}
@@ -0,0 +1,75 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+)
+
+// Storage menyimpan file attachment. Key berupa path relatif dengan "/" sebagai
+// separator (misalnya "attachments/3/9f1c2e.jpg").
+type Storage interface {
+	Put(key string, data []byte, contentType string) error
+	Get(key string) (io.ReadCloser, error)
+	Delete(key string) error
+}
+
+// ErrNotFound dikembalikan Get kalau key tidak ada
+var ErrNotFound = errors.New("file not found")
+
+// Files adalah storage yang dipakai aplikasi, diisi oleh Setup
+var Files Storage
+
+// Setup memilih storage dari env STORAGE_DRIVER:
+//   - "local" (default): folder STORAGE_PATH (default ./uploads)
+//   - "s3": bucket S3-compatible (AWS S3, MinIO) dari S3_ENDPOINT, S3_REGION,
+//     S3_BUCKET, S3_ACCESS_KEY dan S3_SECRET_KEY
+func Setup() error {
+	driver := strings.ToLower(os.Getenv("STORAGE_DRIVER"))
+
+	switch driver {
+	case "", "local":
+		root := os.Getenv("STORAGE_PATH")
+		if root == "" {
+			root = "./uploads"
+		}
+		local, err := NewLocal(root)
+		if err != nil {
+			return err
+		}
+		Files = local
+		log.Printf("Attachment storage: local (%s)", root)
+	case "s3":
+		s3, err := NewS3(S3Config{
+			Endpoint:  os.Getenv("S3_ENDPOINT"),
+			Region:    os.Getenv("S3_REGION"),
+			Bucket:    os.Getenv("S3_BUCKET"),
+			AccessKey: os.Getenv("S3_ACCESS_KEY"),
+			SecretKey: os.Getenv("S3_SECRET_KEY"),
+		})
+		if err != nil {
+			return err
+		}
+		Files = s3
+		log.Printf("Attachment storage: s3 (%s/%s)", s3.endpoint, s3.bucket)
+	default:
+		return fmt.Errorf("unknown STORAGE_DRIVER %q", driver)
+	}
+	return nil
+}
+
+// Key harus relatif dan tidak boleh keluar dari root storage
+func validKey(key string) bool {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return false
+	}
+	for _, part := range strings.Split(key, "/") {
+		if part == "" || part == "." || part == ".." {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,118 @@
+package utils
+
+import (
+	"finance-tracker-backend/models"
+	"fmt"
+	"math/big"
+	"time"
+)
+
+// Installment adalah satu baris jadwal cicilan
+type Installment struct {
+	No        int          `json:"no"`
+	DueDate   string       `json:"due_date"`
+	Payment   models.Money `json:"payment"`
+	Principal models.Money `json:"principal"`
+	Interest  models.Money `json:"interest"`
+	Balance   models.Money `json:"balance"` // sisa pokok setelah cicilan ini
+}
+
+// AddMonths memajukan tanggal n bulan; tanggal yang tidak ada di bulan tujuan
+// dijepit ke akhir bulan (31 Jan + 1 bulan = 29 Feb)
+func AddMonths(day time.Time, n int) time.Time {
+	first := time.Date(day.Year(), day.Month()+time.Month(n), 1, 0, 0, 0, 0, time.UTC)
+	last := first.AddDate(0, 1, -1).Day()
+	d := day.Day()
+	if d > last {
+		d = last
+	}
+	return time.Date(first.Year(), first.Month(), d, 0, 0, 0, 0, time.UTC)
+}
+
+// AmortizationSchedule membuat jadwal cicilan bulanan. monthlyRate adalah bunga per bulan
+// (misalnya 1/100 untuk 1%), firstDue tanggal jatuh tempo cicilan pertama. Metode:
+//   - flat: bunga tetap dari pokok awal setiap bulan, pokok dibagi rata
+//   - effective: pokok dibagi rata, bunga dari sisa pokok (cicilan menurun)
+//   - annuity: cicilan tetap, porsi bunga dari sisa pokok (anuitas)
+//
+// Semua nilai dibulatkan ke currency, selisih pembulatan pokok masuk ke cicilan terakhir.
+func AmortizationSchedule(principal models.Money, monthlyRate *big.Rat, months int, method string, firstDue time.Time, currency string) ([]Installment, error) {
+	if principal <= 0 || months <= 0 {
+		return nil, fmt.Errorf("principal and term must be positive")
+	}
+	if monthlyRate.Sign() < 0 {
+		return nil, fmt.Errorf("interest rate must not be negative")
+	}
+
+	n := big.NewRat(int64(months), 1)
+	principalRat := principal.Rat()
+	evenPrincipal := new(big.Rat).Quo(principalRat, n)
+
+	var annuity *big.Rat
+	if method == "annuity" {
+		if monthlyRate.Sign() == 0 {
+			annuity = evenPrincipal
+		} else {
+			// P * r / (1 - (1+r)^-n)
+			growth := new(big.Rat).SetInt64(1)
+			onePlusRate := new(big.Rat).Add(big.NewRat(1, 1), monthlyRate)
+			for i := 0; i < months; i++ {
+				growth.Mul(growth, onePlusRate)
+			}
+			discount := new(big.Rat).Sub(big.NewRat(1, 1), new(big.Rat).Inv(growth))
+			annuity = new(big.Rat).Quo(new(big.Rat).Mul(principalRat, monthlyRate), discount)
+		}
+	} else if method != "flat" && method != "effective" {
+		return nil, fmt.Errorf("method must be flat, effective or annuity")
+	}
+
+	round := func(r *big.Rat) (models.Money, error) {
+		m, err := models.MoneyFromRat(r)
+		return m.Round(currency), err
+	}
+
+	schedule := make([]Installment, 0, months)
+	balance := principal
+	for i := 1; i <= months; i++ {
+		var principalPart, interest models.Money
+		var err error
+
+		switch method {
+		case "flat":
+			if interest, err = round(new(big.Rat).Mul(principalRat, monthlyRate)); err != nil {
+				return nil, err
+			}
+			principalPart, err = round(evenPrincipal)
+		case "effective":
+			if interest, err = round(new(big.Rat).Mul(balance.Rat(), monthlyRate)); err != nil {
+				return nil, err
+			}
+			principalPart, err = round(evenPrincipal)
+		default:
+			if interest, err = round(new(big.Rat).Mul(balance.Rat(), monthlyRate)); err != nil {
+				return nil, err
+			}
+			var payment models.Money
+			payment, err = round(annuity)
+			principalPart = payment - interest
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if i == months || principalPart > balance {
+			principalPart = balance
+		}
+		balance -= principalPart
+
+		schedule = append(schedule, Installment{
+			No:        i,
+			DueDate:   AddMonths(firstDue, i-1).Format("2006-01-02"),
+			Payment:   principalPart + interest,
+			Principal: principalPart,
+			Interest:  interest,
+			Balance:   balance,
+		})
+	}
+	return schedule, nil
+}
@@ -0,0 +1,111 @@
+package utils
+
+import (
+	"finance-tracker-backend/models"
+	"math/big"
+	"testing"
+)
+
+func TestAmortizationSchedule(t *testing.T) {
+	tests := []struct {
+		name          string
+		principal     string
+		rate          *big.Rat // per bulan
+		months        int
+		method        string
+		currency      string
+		firstPayment  string
+		lastPayment   string
+		totalInterest string
+	}{
+		{name: "flat", principal: "12000000", rate: big.NewRat(1, 100), months: 12, method: "flat", currency: "IDR",
+			firstPayment: "1120000", lastPayment: "1120000", totalInterest: "1440000"},
+		{name: "effective", principal: "12000000", rate: big.NewRat(1, 100), months: 12, method: "effective", currency: "IDR",
+			firstPayment: "1120000", lastPayment: "1010000", totalInterest: "780000"},
+		{name: "annuity", principal: "10000", rate: big.NewRat(1, 100), months: 12, method: "annuity", currency: "USD",
+			firstPayment: "888.49", lastPayment: "888.47", totalInterest: "661.86"},
+		{name: "annuity without interest", principal: "1000", rate: new(big.Rat), months: 3, method: "annuity", currency: "IDR",
+			firstPayment: "333", lastPayment: "334", totalInterest: "0"},
+		{name: "flat with remainder", principal: "100", rate: big.NewRat(15, 1000), months: 3, method: "flat", currency: "USD",
+			firstPayment: "34.83", lastPayment: "34.84", totalInterest: "4.5"},
+	}
+
+	for _, tt := range tests {
+		principal, _ := models.ParseMoney(tt.principal)
+		schedule, err := AmortizationSchedule(principal, tt.rate, tt.months, tt.method, mustDate("2024-01-31"), tt.currency)
+		if err != nil {
+			t.Errorf("%s: error %v", tt.name, err)
+			continue
+		}
+		if len(schedule) != tt.months {
+			t.Errorf("%s: %d installments, want %d", tt.name, len(schedule), tt.months)
+			continue
+		}
+
+		var totalPrincipal, totalInterest models.Money
+		for _, item := range schedule {
+			totalPrincipal += item.Principal
+			totalInterest += item.Interest
+			if item.Payment != item.Principal+item.Interest {
+				t.Errorf("%s: installment %d payment %s != principal %s + interest %s", tt.name, item.No, item.Payment, item.Principal, item.Interest)
+			}
+		}
+		last := schedule[len(schedule)-1]
+		if totalPrincipal != principal || last.Balance != 0 {
+			t.Errorf("%s: total principal %s, final balance %s, want %s and 0", tt.name, totalPrincipal, last.Balance, principal)
+		}
+		if got := totalInterest.String(); got != tt.totalInterest {
+			t.Errorf("%s: total interest %s, want %s", tt.name, got, tt.totalInterest)
+		}
+		if got := schedule[0].Payment.String(); got != tt.firstPayment {
+			t.Errorf("%s: first payment %s, want %s", tt.name, got, tt.firstPayment)
+		}
+		if got := last.Payment.String(); got != tt.lastPayment {
+			t.Errorf("%s: last payment %s, want %s", tt.name, got, tt.lastPayment)
+		}
+		if schedule[0].DueDate != "2024-01-31" || schedule[1].DueDate != "2024-02-29" {
+			t.Errorf("%s: due dates %s, %s", tt.name, schedule[0].DueDate, schedule[1].DueDate)
+		}
+	}
+}
+
+func TestAmortizationScheduleInvalid(t *testing.T) {
+	tests := []struct {
+		name      string
+		principal models.Money
+		rate      *big.Rat
+		months    int
+		method    string
+	}{
+		{"zero principal", 0, big.NewRat(1, 100), 12, "flat"},
+		{"zero term", 1000 * models.MoneyScale, big.NewRat(1, 100), 0, "flat"},
+		{"negative rate", 1000 * models.MoneyScale, big.NewRat(-1, 100), 12, "annuity"},
+		{"unknown method", 1000 * models.MoneyScale, big.NewRat(1, 100), 12, "balloon"},
+	}
+
+	for _, tt := range tests {
+		if _, err := AmortizationSchedule(tt.principal, tt.rate, tt.months, tt.method, mustDate("2024-01-01"), "IDR"); err == nil {
+			t.Errorf("%s: want error", tt.name)
+		}
+	}
+}
+
+func TestAddMonths(t *testing.T) {
+	tests := []struct {
+		from string
+		n    int
+		want string
+	}{
+		{"2024-01-31", 1, "2024-02-29"},
+		{"2023-01-31", 1, "2023-02-28"},
+		{"2024-01-31", 2, "2024-03-31"},
+		{"2024-11-30", 3, "2025-02-28"},
+		{"2024-03-15", -1, "2024-02-15"},
+	}
+
+	for _, tt := range tests {
+		if got := AddMonths(mustDate(tt.from), tt.n).Format("2006-01-02"); got != tt.want {
+			t.Errorf("AddMonths(%s, %d) = %s, want %s", tt.from, tt.n, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,191 @@
+package utils
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"html"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// EmailMessage adalah satu email notifikasi dari file .eml atau mbox
+type EmailMessage struct {
+	From    string
+	Subject string
+	Date    time.Time // zero kalau header Date tidak ada/tidak terbaca
+	Body    string    // text/plain, atau text/html yang sudah dibuang tag-nya
+}
+
+var (
+	htmlBreaks = regexp.MustCompile(`(?i)<br\s*/?>|</(p|div|tr|li|h[1-6]|table)>`)
+	htmlCells  = regexp.MustCompile(`(?i)</t[dh]>`)
+	htmlTags   = regexp.MustCompile(`(?s)<style.*?</style>|<script.*?</script>|<[^>]*>`)
+)
+
+// Teks dari body HTML: baris tabel dan paragraf jadi baris baru, sel jadi spasi
+func htmlToText(body string) string {
+	body = htmlBreaks.ReplaceAllString(body, "\n")
+	body = htmlCells.ReplaceAllString(body, " ")
+	body = htmlTags.ReplaceAllString(body, "")
+	return html.UnescapeString(body)
+}
+
+// Decode body sesuai Content-Transfer-Encoding; charset ISO-8859-1/Windows-1252 diubah ke UTF-8
+func decodeEmailBody(body io.Reader, encoding, charset string) ([]byte, error) {
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "base64":
+		data, err := io.ReadAll(body)
+		if err != nil {
+			return nil, err
+		}
+		cleaned := strings.Join(strings.Fields(string(data)), "")
+		decoded, err := base64.StdEncoding.DecodeString(cleaned)
+		if err != nil {
+			return nil, err
+		}
+		body = bytes.NewReader(decoded)
+	case "quoted-printable":
+		body = quotedprintable.NewReader(body)
+	}
+	data, err := io.ReadAll(io.LimitReader(body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+
+	switch strings.ToLower(charset) {
+	case "iso-8859-1", "latin1", "windows-1252", "us-ascii":
+		runes := make([]rune, len(data))
+		for i, b := range data {
+			runes[i] = rune(b)
+		}
+		data = []byte(string(runes))
+	}
+	return data, nil
+}
+
+// Ambil text/plain (diutamakan) atau text/html dari satu bagian email, termasuk multipart bertingkat
+func emailText(contentType, encoding string, body io.Reader) (plain, htmlBody string, err error) {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType, params = "text/plain", map[string]string{}
+	}
+
+	if strings.HasPrefix(mediaType, "multipart/") {
+		reader := multipart.NewReader(body, params["boundary"])
+		for {
+			part, err := reader.NextPart()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return plain, htmlBody, err
+			}
+			partPlain, partHTML, err := emailText(part.Header.Get("Content-Type"), part.Header.Get("Content-Transfer-Encoding"), part)
+			if err != nil {
+				continue
+			}
+			if plain == "" {
+				plain = partPlain
+			}
+			if htmlBody == "" {
+				htmlBody = partHTML
+			}
+		}
+		return plain, htmlBody, nil
+	}
+
+	if mediaType != "text/plain" && mediaType != "text/html" {
+		return "", "", nil
+	}
+	data, err := decodeEmailBody(body, encoding, params["charset"])
+	if err != nil {
+		return "", "", err
+	}
+	if mediaType == "text/html" {
+		return "", htmlToText(string(data)), nil
+	}
+	return string(data), "", nil
+}
+
+// Pecah file mbox jadi pesan per baris pemisah "From ..."; file .eml biasa jadi satu pesan
+func splitMailbox(data []byte) [][]byte {
+	data = bytes.ReplaceAll(data, []byte("\r\n"), []byte("\n"))
+	if !bytes.HasPrefix(data, []byte("From ")) {
+		return [][]byte{data}
+	}
+
+	var messages [][]byte
+	var current bytes.Buffer
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if bytes.HasPrefix(line, []byte("From ")) {
+			if current.Len() > 0 {
+				messages = append(messages, append([]byte(nil), current.Bytes()...))
+				current.Reset()
+			}
+			continue
+		}
+		// mboxrd: baris ">From " di body adalah "From " yang di-escape
+		if bytes.HasPrefix(bytes.TrimLeft(line, ">"), []byte("From ")) && line[0] == '>' {
+			line = line[1:]
+		}
+		current.Write(line)
+		current.WriteByte('\n')
+	}
+	if current.Len() > 0 {
+		messages = append(messages, current.Bytes())
+	}
+	return messages
+}
+
+// ParseEmails membaca file .eml (satu email) atau mbox (banyak email). Email yang tidak bisa
+// dibaca dilewati; error hanya kalau tidak ada email sama sekali.
+func ParseEmails(data []byte) ([]EmailMessage, error) {
+	decoder := new(mime.WordDecoder)
+	var messages []EmailMessage
+	for _, raw := range splitMailbox(data) {
+		message, err := mail.ReadMessage(bytes.NewReader(raw))
+		if err != nil {
+			continue
+		}
+
+		email := EmailMessage{From: message.Header.Get("From"), Subject: message.Header.Get("Subject")}
+		if subject, err := decoder.DecodeHeader(email.Subject); err == nil {
+			email.Subject = subject
+		}
+		if address, err := mail.ParseAddress(email.From); err == nil {
+			email.From = strings.TrimSpace(address.Name + " <" + address.Address + ">")
+		}
+		if date, err := message.Header.Date(); err == nil {
+			email.Date = date
+		}
+
+		contentType := message.Header.Get("Content-Type")
+		if contentType == "" {
+			contentType = "text/plain"
+		}
+		plain, htmlBody, err := emailText(contentType, message.Header.Get("Content-Transfer-Encoding"), message.Body)
+		if err != nil && plain == "" && htmlBody == "" {
+			continue
+		}
+		email.Body = plain
+		if strings.TrimSpace(email.Body) == "" {
+			email.Body = htmlBody
+		}
+		messages = append(messages, email)
+	}
+	if len(messages) == 0 {
+		return nil, fmt.Errorf("file contains no readable email")
+	}
+	return messages, nil
+}
@@ -0,0 +1,110 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+// mbox berisi email m-BCA (text/plain quoted-printable, subject encoded-word) dan email
+// Livin' Mandiri (multipart, hanya bagian HTML base64)
+const sampleMbox = "From bca@bca.co.id Thu May  2 10:15:00 2024\r\n" +
+	"From: \"BCA\" <bca@bca.co.id>\r\n" +
+	"Subject: =?UTF-8?B?VHJhbnNmZXIgQmVyaGFzaWw=?=\r\n" +
+	"Date: Thu, 02 May 2024 10:15:00 +0700\r\n" +
+	"Content-Type: text/plain; charset=utf-8\r\n" +
+	"Content-Transfer-Encoding: quoted-printable\r\n" +
+	"\r\n" +
+	"Nama Penerima : TOKO MAJU\r\n" +
+	"Bank Tujuan : BCA\r\n" +
+	"Nominal Transaksi : Rp 1.500.000,00\r\n" +
+	"Keterangan : pembayaran invoice =\r\n" +
+	"no 42\r\n" +
+	">From the desk of BCA\r\n" +
+	"\r\n" +
+	"From noreply@bankmandiri.co.id Fri May  3 08:00:00 2024\r\n" +
+	"From: noreply@bankmandiri.co.id\r\n" +
+	"Subject: Livin' Transfer\r\n" +
+	"Date: Fri, 03 May 2024 08:00:00 +0700\r\n" +
+	"MIME-Version: 1.0\r\n" +
+	"Content-Type: multipart/alternative; boundary=\"b1\"\r\n" +
+	"\r\n" +
+	"--b1\r\n" +
+	"Content-Type: image/png\r\n" +
+	"\r\n" +
+	"PNG\r\n" +
+	"--b1\r\n" +
+	"Content-Type: text/html; charset=utf-8\r\n" +
+	"Content-Transfer-Encoding: base64\r\n" +
+	"\r\n" +
+	// <table><tr><td>Penerima</td><td>ANI &amp; CO</td></tr><tr><td>Nominal</td><td>Rp 250.000</td></tr></table>
+	"PHRhYmxlPjx0cj48dGQ+UGVuZXJpbWE8L3RkPjx0ZD5BTkkgJmFtcDsgQ088L3RkPjwvdHI+PHRy\r\n" +
+	"Pjx0ZD5Ob21pbmFsPC90ZD48dGQ+UnAgMjUwLjAwMDwvdGQ+PC90cj48L3RhYmxlPg==\r\n" +
+	"--b1--\r\n"
+
+func TestParseEmails(t *testing.T) {
+	emails, err := ParseEmails([]byte(sampleMbox))
+	if err != nil {
+		t.Fatalf("ParseEmails error: %v", err)
+	}
+	if len(emails) != 2 {
+		t.Fatalf("%d emails, want 2", len(emails))
+	}
+
+	tests := []struct {
+		from     string
+		subject  string
+		date     string
+		contains []string
+	}{
+		{
+			from: "BCA <bca@bca.co.id>", subject: "Transfer Berhasil", date: "2024-05-02T10:15:00+07:00",
+			contains: []string{"Nama Penerima : TOKO MAJU", "pembayaran invoice no 42", "\nFrom the desk"},
+		},
+		{
+			from: "<noreply@bankmandiri.co.id>", subject: "Livin' Transfer", date: "2024-05-03T08:00:00+07:00",
+			contains: []string{"Penerima ANI & CO", "Nominal Rp 250.000"},
+		},
+	}
+	for i, tt := range tests {
+		email := emails[i]
+		if email.From != tt.from || email.Subject != tt.subject {
+			t.Errorf("email %d: from %q subject %q, want %q %q", i, email.From, email.Subject, tt.from, tt.subject)
+		}
+		if got := email.Date.Format("2006-01-02T15:04:05-07:00"); got != tt.date {
+			t.Errorf("email %d: date %s, want %s", i, got, tt.date)
+		}
+		for _, text := range tt.contains {
+			if !strings.Contains(email.Body, text) {
+				t.Errorf("email %d: body %q does not contain %q", i, email.Body, text)
+			}
+		}
+	}
+
+	// Email pertama langsung terbaca template transfer m-BCA
+	match, err := MatchNotification(emails[0].Subject+"\n"+emails[0].Body, emails[0].Date, DefaultNotificationTemplates())
+	if err != nil {
+		t.Fatalf("MatchNotification error: %v", err)
+	}
+	if match.Merchant != "TOKO MAJU" || match.Amount.String() != "1500000" || match.Date.Format("2006-01-02") != "2024-05-02" {
+		t.Errorf("match = %+v", match)
+	}
+}
+
+func TestParseEmailsSingleMessage(t *testing.T) {
+	eml := "From: Jenius <noreply@jenius.co.id>\n" +
+		"Subject: Kirim uang\n" +
+		"Content-Type: text/plain; charset=iso-8859-1\n" +
+		"\n" +
+		"Kamu telah mengirim Rp 50.000 ke Caf\xe9 Kita berhasil\n"
+	emails, err := ParseEmails([]byte(eml))
+	if err != nil {
+		t.Fatalf("ParseEmails error: %v", err)
+	}
+	if len(emails) != 1 || !emails[0].Date.IsZero() || !strings.Contains(emails[0].Body, "Café Kita") {
+		t.Errorf("emails = %+v", emails)
+	}
+
+	if _, err := ParseEmails([]byte("bukan email sama sekali")); err == nil {
+		t.Error("want error for a file without readable email")
+	}
+}
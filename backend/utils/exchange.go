@@ -0,0 +1,317 @@
+package utils
+
+import (
+	"encoding/csv"
+	"encoding/xml"
+	"finance-tracker-backend/models"
+	"fmt"
+	"io"
+	"math/big"
+	"sort"
+	"strings"
+	"time"
+)
+
+type ratePair struct {
+	From string
+	To   string
+}
+
+type datedRate struct {
+	Date time.Time
+	Rate *big.Rat
+}
+
+// RateTable menyimpan exchange rate di memory untuk konversi cepat
+type RateTable struct {
+	rates      map[ratePair][]datedRate
+	currencies []string   // terurut, supaya pilihan currency perantara selalu sama
+	base       *RateTable // rate global di bawah rate user (lihat Overlay)
+}
+
+// Buat RateTable dari daftar rate. Kalau ada pair dan tanggal yang sama,
+// rate yang muncul belakangan menang (dipakai agar rate user menimpa rate global).
+func NewRateTable(rates []models.ExchangeRate) *RateTable {
+	table := &RateTable{rates: make(map[ratePair][]datedRate)}
+	index := make(map[ratePair]map[time.Time]int)
+	currencies := make(map[string]bool)
+
+	for _, r := range rates {
+		if r.Rate <= 0 {
+			continue
+		}
+		rate := r.Rate.Rat()
+		pair := ratePair{From: r.FromCurrency, To: r.ToCurrency}
+		day := truncateDay(r.Date)
+
+		days := index[pair]
+		if days == nil {
+			days = make(map[time.Time]int)
+			index[pair] = days
+		}
+		if i, ok := days[day]; ok {
+			table.rates[pair][i].Rate = rate
+		} else {
+			days[day] = len(table.rates[pair])
+			table.rates[pair] = append(table.rates[pair], datedRate{Date: day, Rate: rate})
+		}
+		currencies[r.FromCurrency] = true
+		currencies[r.ToCurrency] = true
+	}
+	for currency := range currencies {
+		table.currencies = append(table.currencies, currency)
+	}
+	sort.Strings(table.currencies)
+
+	for pair := range table.rates {
+		list := table.rates[pair]
+		sort.Slice(list, func(i, j int) bool { return list[i].Date.Before(list[j].Date) })
+	}
+
+	return table
+}
+
+// Overlay membuat table baru dari rates yang menimpa t di tanggal yang sama, tanpa menyalin t.
+// Hasilnya sama dengan NewRateTable(rate t diikuti rates), dipakai untuk rate user di atas
+// table global yang di-cache.
+func (t *RateTable) Overlay(rates []models.ExchangeRate) *RateTable {
+	table := NewRateTable(rates)
+	table.base = t
+
+	currencies := append([]string{}, t.currencies...)
+	for _, currency := range table.currencies {
+		if i := sort.SearchStrings(currencies, currency); i == len(currencies) || currencies[i] != currency {
+			currencies = append(currencies, currency)
+		}
+	}
+	sort.Strings(currencies)
+	table.currencies = currencies
+
+	return table
+}
+
+// Rate terakhir untuk pair persis (tanpa inverse) pada atau sebelum tanggal on.
+// Rate di base hanya dipakai kalau tanggalnya lebih baru dari rate di table ini.
+func (t *RateTable) direct(from, to string, on time.Time) (datedRate, bool) {
+	list := t.rates[ratePair{From: from, To: to}]
+	day := truncateDay(on)
+	idx := sort.Search(len(list), func(i int) bool { return list[i].Date.After(day) })
+
+	var found datedRate
+	ok := idx > 0
+	if ok {
+		found = list[idx-1]
+	}
+	if t.base != nil {
+		if below, baseOK := t.base.direct(from, to, on); baseOK && (!ok || below.Date.After(found.Date)) {
+			return below, true
+		}
+	}
+	return found, ok
+}
+
+// Rate 1 from = x to (exact), mencoba pair langsung, kebalikannya, lalu cross rate lewat satu currency
+// perantara (dicoba urut abjad, yang pertama punya rate ke kedua sisi yang dipakai)
+func (t *RateTable) Rate(from, to string, on time.Time) (*big.Rat, bool) {
+	if from == to {
+		return big.NewRat(1, 1), true
+	}
+	if rate, ok := t.single(from, to, on); ok {
+		return rate, true
+	}
+
+	for _, pivot := range t.currencies {
+		if pivot == from || pivot == to {
+			continue
+		}
+		first, ok := t.single(from, pivot, on)
+		if !ok {
+			continue
+		}
+		second, ok := t.single(pivot, to, on)
+		if !ok {
+			continue
+		}
+		return new(big.Rat).Mul(first, second), true
+	}
+
+	return nil, false
+}
+
+func (t *RateTable) single(from, to string, on time.Time) (*big.Rat, bool) {
+	if rate, ok := t.direct(from, to, on); ok {
+		return rate.Rate, true
+	}
+	if rate, ok := t.direct(to, from, on); ok {
+		return new(big.Rat).Inv(rate.Rate), true
+	}
+	return nil, false
+}
+
+// Konversi amount dari satu currency ke currency lain memakai rate pada tanggal on,
+// hasilnya dibulatkan sesuai jumlah desimal currency tujuan
+func (t *RateTable) Convert(amount models.Money, from, to string, on time.Time) (models.Money, error) {
+	if from == to {
+		return amount, nil
+	}
+	rate, ok := t.Rate(from, to, on)
+	if !ok {
+		return 0, fmt.Errorf("no exchange rate for %s to %s on %s", from, to, on.Format("2006-01-02"))
+	}
+	converted, err := amount.Mul(rate)
+	if err != nil {
+		return 0, err
+	}
+	return converted.Round(to), nil
+}
+
+func truncateDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// Normalisasi & validasi kode currency ISO 4217 (3 huruf)
+func NormalizeCurrency(code string) (string, bool) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if len(code) != 3 {
+		return "", false
+	}
+	for _, r := range code {
+		if r < 'A' || r > 'Z' {
+			return "", false
+		}
+	}
+	return code, true
+}
+
+// Parse file rate CSV. Dua layout didukung:
+//   - long:  date,from,to,rate (satu rate per baris)
+//   - ECB:   Date,USD,JPY,... (base EUR, satu tanggal per baris)
+func ParseRatesCSV(r io.Reader) ([]models.ExchangeRate, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) < 2 {
+		return nil, fmt.Errorf("file contains no rates")
+	}
+
+	header := make([]string, len(records[0]))
+	for i, h := range records[0] {
+		header[i] = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(h, "\ufeff")))
+	}
+
+	var rates []models.ExchangeRate
+	if len(header) >= 4 && header[0] == "date" && header[1] == "from" && header[2] == "to" && header[3] == "rate" {
+		for line, rec := range records[1:] {
+			if len(rec) < 4 {
+				return nil, fmt.Errorf("line %d: expected 4 columns", line+2)
+			}
+			date, err := time.Parse("2006-01-02", strings.TrimSpace(rec[0]))
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid date", line+2)
+			}
+			from, okFrom := NormalizeCurrency(rec[1])
+			to, okTo := NormalizeCurrency(rec[2])
+			if !okFrom || !okTo {
+				return nil, fmt.Errorf("line %d: invalid currency code", line+2)
+			}
+			rate, err := models.ParseRate(rec[3])
+			if err != nil || rate <= 0 {
+				return nil, fmt.Errorf("line %d: invalid rate", line+2)
+			}
+			rates = append(rates, models.ExchangeRate{FromCurrency: from, ToCurrency: to, Date: date, Rate: rate, Source: "csv"})
+		}
+		return rates, nil
+	}
+
+	if header[0] != "date" {
+		return nil, fmt.Errorf("unrecognised CSV header, expected date,from,to,rate or ECB layout")
+	}
+
+	// Layout ECB: kolom pertama tanggal, sisanya kode currency terhadap EUR
+	for line, rec := range records[1:] {
+		date, err := parseECBDate(strings.TrimSpace(rec[0]))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid date", line+2)
+		}
+		for i := 1; i < len(rec) && i < len(header); i++ {
+			code, ok := NormalizeCurrency(header[i])
+			if !ok {
+				continue
+			}
+			value := strings.TrimSpace(rec[i])
+			if value == "" || value == "N/A" {
+				continue
+			}
+			rate, err := models.ParseRate(value)
+			if err != nil || rate <= 0 {
+				continue
+			}
+			rates = append(rates, models.ExchangeRate{FromCurrency: "EUR", ToCurrency: code, Date: date, Rate: rate, Source: "ecb"})
+		}
+	}
+
+	return rates, nil
+}
+
+func parseECBDate(s string) (time.Time, error) {
+	if date, err := time.Parse("2006-01-02", s); err == nil {
+		return date, nil
+	}
+	return time.Parse("02 January 2006", s)
+}
+
+type ecbEnvelope struct {
+	Cubes []struct {
+		Time  string `xml:"time,attr"`
+		Rates []struct {
+			Currency string `xml:"currency,attr"`
+			Rate     string `xml:"rate,attr"`
+		} `xml:"Cube"`
+	} `xml:"Cube>Cube"`
+}
+
+// Parse file XML format ECB (eurofxref-daily.xml / eurofxref-hist.xml)
+func ParseECBXML(r io.Reader) ([]models.ExchangeRate, error) {
+	var envelope ecbEnvelope
+	if err := xml.NewDecoder(r).Decode(&envelope); err != nil {
+		return nil, err
+	}
+
+	var rates []models.ExchangeRate
+	for _, cube := range envelope.Cubes {
+		date, err := time.Parse("2006-01-02", cube.Time)
+		if err != nil {
+			return nil, fmt.Errorf("invalid date %q", cube.Time)
+		}
+		for _, entry := range cube.Rates {
+			code, ok := NormalizeCurrency(entry.Currency)
+			if !ok {
+				continue
+			}
+			rate, err := models.ParseRate(entry.Rate)
+			if err != nil || rate <= 0 {
+				continue
+			}
+			rates = append(rates, models.ExchangeRate{FromCurrency: "EUR", ToCurrency: code, Date: date, Rate: rate, Source: "ecb"})
+		}
+	}
+
+	if len(rates) == 0 {
+		return nil, fmt.Errorf("file contains no rates")
+	}
+	return rates, nil
+}
+
+// Parse file rate, format ditentukan dari isi (XML diawali '<')
+func ParseRatesFile(data []byte) ([]models.ExchangeRate, error) {
+	trimmed := strings.TrimSpace(string(data))
+	if strings.HasPrefix(trimmed, "<") {
+		return ParseECBXML(strings.NewReader(trimmed))
+	}
+	return ParseRatesCSV(strings.NewReader(trimmed))
+}
@@ -0,0 +1,107 @@
+package utils
+
+import (
+	"finance-tracker-backend/models"
+	"testing"
+)
+
+func sampleRate(userID uint, from, to, date, value string) models.ExchangeRate {
+	r, err := models.ParseRate(value)
+	if err != nil {
+		panic(err)
+	}
+	return models.ExchangeRate{UserID: userID, FromCurrency: from, ToCurrency: to, Date: mustDate(date), Rate: r}
+}
+
+var sampleGlobalRates = []models.ExchangeRate{
+	sampleRate(0, "EUR", "USD", "2024-03-01", "1.08"),
+	sampleRate(0, "EUR", "IDR", "2024-03-01", "17000"),
+	sampleRate(0, "EUR", "USD", "2024-03-04", "1.09"),
+	sampleRate(0, "EUR", "USD", "2024-03-04", "1.1"), // tanggal sama, yang belakangan menang
+	sampleRate(0, "USD", "IDR", "2024-03-10", "15800"),
+}
+
+func TestRateTableRate(t *testing.T) {
+	own := []models.ExchangeRate{
+		sampleRate(7, "EUR", "USD", "2024-03-04", "1.2"),
+		sampleRate(7, "EUR", "USD", "2024-03-02", "1.15"),
+	}
+	tables := map[string]*RateTable{
+		"merged":  NewRateTable(append(append([]models.ExchangeRate{}, sampleGlobalRates...), own...)),
+		"overlay": NewRateTable(sampleGlobalRates).Overlay(own),
+	}
+
+	tests := []struct {
+		name string
+		from string
+		to   string
+		on   string
+		want string // kosong = tidak ada rate
+	}{
+		{name: "currency sama", from: "JPY", to: "JPY", on: "2024-03-01", want: "1"},
+		{name: "langsung", from: "EUR", to: "IDR", on: "2024-03-05", want: "17000"},
+		{name: "inverse", from: "IDR", to: "EUR", on: "2024-03-05", want: "1/17000"},
+		{name: "sebelum rate pertama", from: "EUR", to: "USD", on: "2024-02-29"},
+		{name: "rate global sebelum rate user", from: "EUR", to: "USD", on: "2024-03-01", want: "27/25"},
+		{name: "rate user lebih baru dari rate global", from: "EUR", to: "USD", on: "2024-03-03", want: "23/20"},
+		{name: "rate user menimpa rate global di tanggal sama", from: "EUR", to: "USD", on: "2024-03-09", want: "6/5"},
+		{name: "pair langsung didahulukan dari cross", from: "USD", to: "IDR", on: "2024-03-10", want: "15800"},
+		// USD->EUR (1/1.2) lalu EUR->IDR (17000)
+		{name: "cross lewat EUR", from: "USD", to: "IDR", on: "2024-03-09", want: "42500/3"},
+		{name: "currency tidak dikenal", from: "EUR", to: "JPY", on: "2024-03-09"},
+	}
+
+	for name, table := range tables {
+		for _, tt := range tests {
+			got, ok := table.Rate(tt.from, tt.to, mustDate(tt.on))
+			if tt.want == "" {
+				if ok {
+					t.Errorf("%s %s: Rate = %s, want none", name, tt.name, got.RatString())
+				}
+				continue
+			}
+			if !ok || got.RatString() != tt.want {
+				t.Errorf("%s %s: Rate = %v, %v, want %s", name, tt.name, got, ok, tt.want)
+			}
+		}
+	}
+}
+
+func TestRateTableConvert(t *testing.T) {
+	table := NewRateTable(sampleGlobalRates)
+
+	amount, _ := models.ParseMoney("100")
+	got, err := table.Convert(amount, "USD", "EUR", mustDate("2024-03-05"))
+	if err != nil || got.String() != "90.91" {
+		t.Errorf("Convert USD->EUR = %s, %v, want 90.91", got, err)
+	}
+	got, err = table.Convert(amount, "USD", "IDR", mustDate("2024-03-05"))
+	if err != nil || got.String() != "1545455" {
+		t.Errorf("Convert USD->IDR = %s, %v, want 1545455", got, err)
+	}
+	if _, err := table.Convert(amount, "USD", "JPY", mustDate("2024-03-05")); err == nil {
+		t.Error("want error without a JPY rate")
+	}
+}
+
+func TestParseRatesFile(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want int
+	}{
+		{name: "long", data: "date,from,to,rate\n2024-03-01,usd,IDR,15800.5\n2024-03-01,EUR,JPY,1.6e+02\n", want: 2},
+		{name: "ECB CSV", data: "Date,USD,JPY,CYP,\n2024-03-01,1.0842,162.31,N/A,\n", want: 2},
+		{name: "ECB XML", data: `<gesmes:Envelope><Cube><Cube time="2024-03-01"><Cube currency="USD" rate="1.0842"/><Cube currency="IDR" rate="17000"/></Cube></Cube></gesmes:Envelope>`, want: 2},
+	}
+	for _, tt := range tests {
+		rates, err := ParseRatesFile([]byte(tt.data))
+		if err != nil || len(rates) != tt.want {
+			t.Errorf("%s: %d rates, %v, want %d", tt.name, len(rates), err, tt.want)
+		}
+	}
+
+	if _, err := ParseRatesFile([]byte("date,from,to,rate\n2024-03-01,USD,IDR,1/3\n")); err == nil {
+		t.Error("want error for a fraction rate")
+	}
+}
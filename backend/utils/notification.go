@@ -0,0 +1,228 @@
+package utils
+
+import (
+	"finance-tracker-backend/models"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Potongan regex yang dipakai template bawaan
+const (
+	notificationAmount = `(?:rp\.?|idr)\s*(?P<amount>\d[\d.,]*\d|\d)`
+	notificationDate   = `(?P<date>\d{1,2}[/-]\d{1,2}[/-]\d{2,4}|\d{1,2}\s+[a-z]{3,9}\s+\d{4})`
+	// Akhir nama merchant/pengirim: tanggal, kata "berhasil", tanda baca, atau akhir baris
+	notificationEnd = `(?:\s+(?:pada|tgl\.?|tanggal)\s+` + notificationDate + `|\s+(?:berhasil|sukses|telah)\b|[.,;]\s|\.?\s*$)`
+	// "Dana masuk Rp500.000 dari NAMA" dan sejenisnya, hampir sama di semua bank
+	notificationIncoming = `(?:dana\s+masuk|transfer\s+masuk|terima\s+transfer|menerima(?:\s+dana)?|kredit|uang\s+masuk)\s+(?:sebesar\s+)?` +
+		notificationAmount + `(?:\s+dari\s+(?P<merchant>.+?))?` + notificationEnd
+)
+
+// DefaultNotificationTemplates adalah template sistem untuk format notifikasi bank yang umum.
+// Di-seed dengan user_id = 0; pattern selalu dicocokkan tanpa peduli huruf besar/kecil.
+func DefaultNotificationTemplates() []models.NotificationTemplate {
+	return []models.NotificationTemplate{
+		{Bank: "BCA", Name: "Transaksi kartu debit / QRIS", Kind: "expense",
+			Pattern: `transaksi\s+(?:sebesar\s+)?` + notificationAmount + `\s+(?:di|pada|ke)\s+(?P<merchant>.+?)` + notificationEnd},
+		{Bank: "BCA", Name: "Dana masuk", Kind: "income", Pattern: notificationIncoming},
+		{Bank: "BCA", Name: "Transfer m-BCA / KlikBCA (email)", Kind: "expense",
+			Pattern: `(?:nama\s+)?penerima\s*:\s*(?P<merchant>[^\n]+?)\s*\n[\s\S]*?(?:nominal|jumlah)(?:\s+transaksi)?\s*:\s*` + notificationAmount},
+		{Bank: "Mandiri", Name: "Transaksi debit / QRIS", Kind: "expense",
+			Pattern: `transaksi(?:\s+kartu)?(?:\s+debit|\s+qris)?\s+(?:sebesar\s+)?` + notificationAmount + `\s+(?:di|pada)\s+(?P<merchant>.+?)` + notificationEnd},
+		{Bank: "Mandiri", Name: "Dana masuk", Kind: "income", Pattern: notificationIncoming},
+		{Bank: "Mandiri", Name: "Transfer Livin' (email)", Kind: "expense",
+			Pattern: `penerima\s*:?\s*\n?\s*(?P<merchant>[^\n]+?)\s*\n[\s\S]*?nominal(?:\s+transaksi)?\s*:?\s*\n?\s*` + notificationAmount},
+		{Bank: "BNI", Name: "Transaksi kartu debit", Kind: "expense",
+			Pattern: `(?:trx|transaksi)\s+(?:sebesar\s+)?` + notificationAmount + `\s+(?:di|pada)\s+(?P<merchant>.+?)` + notificationEnd},
+		{Bank: "BNI", Name: "Dana masuk", Kind: "income", Pattern: notificationIncoming},
+		{Bank: "BNI", Name: "Transfer BNI Mobile", Kind: "expense",
+			Pattern: `transfer\s+(?:sebesar\s+)?` + notificationAmount + `\s+ke\s+(?P<merchant>.+?)` + notificationEnd},
+		{Bank: "BRI", Name: "Transaksi kartu debit / QRIS", Kind: "expense",
+			Pattern: `transaksi\s+(?:sebesar\s+)?` + notificationAmount + `\s+(?:di|pada)\s+(?P<merchant>.+?)` + notificationEnd},
+		{Bank: "BRI", Name: "Dana masuk", Kind: "income", Pattern: notificationIncoming},
+		{Bank: "BRI", Name: "Transfer BRImo", Kind: "expense",
+			Pattern: `transfer\s+(?:sebesar\s+)?` + notificationAmount + `\s+ke\s+(?P<merchant>.+?)` + notificationEnd},
+		{Bank: "Jenius", Name: "Pembayaran", Kind: "expense",
+			Pattern: `(?:pembayaran|transaksi)\s+(?:sebesar\s+)?` + notificationAmount + `\s+(?:di|ke)\s+(?P<merchant>.+?)` + notificationEnd},
+		{Bank: "Jenius", Name: "Terima uang", Kind: "income",
+			Pattern: `(?:kamu\s+)?(?:menerima|terima)\s+` + notificationAmount + `\s+dari\s+(?P<merchant>.+?)` + notificationEnd},
+		{Bank: "Jenius", Name: "Kirim uang", Kind: "expense",
+			Pattern: `(?:kamu\s+)?(?:telah\s+)?(?:mengirim|kirim)\s+` + notificationAmount + `\s+ke\s+(?P<merchant>.+?)` + notificationEnd},
+	}
+}
+
+// Nama bank yang dikenali dari pengirim SMS/email (misalnya "BCA", "bankmandiri.co.id", "BRImo")
+var notificationBanks = []struct {
+	bank    string
+	senders []string
+}{
+	{"BCA", []string{"bca"}},
+	{"Mandiri", []string{"mandiri", "livin"}},
+	{"BNI", []string{"bni"}},
+	{"BRI", []string{"bri"}},
+	{"Jenius", []string{"jenius", "btpn"}},
+}
+
+// NotificationBank menebak bank dari pengirim pesan, kosong kalau tidak dikenal
+func NotificationBank(sender string) string {
+	sender = strings.ToLower(sender)
+	for _, candidate := range notificationBanks {
+		if containsAny(sender, candidate.senders) {
+			return candidate.bank
+		}
+	}
+	return ""
+}
+
+// Batas panjang pattern template, dicek sebelum compile
+const maxNotificationPatternLength = 1000
+
+// Cache regex template tersimpan per ID template supaya setiap pesan tidak meng-compile ulang
+// semua template. Pattern ikut disimpan: kalau template diubah, entry lama tidak dipakai.
+var notificationPatterns sync.Map // uint -> notificationPattern
+
+type notificationPattern struct {
+	pattern string
+	re      *regexp.Regexp
+}
+
+// CompileNotificationPattern meng-compile pattern template (tidak peka huruf besar/kecil).
+// Pattern harus punya group amount.
+func CompileNotificationPattern(pattern string) (*regexp.Regexp, error) {
+	if len(pattern) > maxNotificationPatternLength {
+		return nil, fmt.Errorf("pattern is too long (max %d characters)", maxNotificationPatternLength)
+	}
+	re, err := regexp.Compile(`(?i)` + pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern: %v", err)
+	}
+	if re.SubexpIndex("amount") < 0 {
+		return nil, fmt.Errorf("pattern must have an amount group, e.g. (?P<amount>[\\d.,]+)")
+	}
+	return re, nil
+}
+
+// Regex template, dari cache kalau template sudah tersimpan (ID != 0). Template yang belum
+// disimpan (misalnya dari endpoint test) selalu di-compile tanpa di-cache.
+func templatePattern(template *models.NotificationTemplate) (*regexp.Regexp, error) {
+	if template.ID != 0 {
+		if cached, ok := notificationPatterns.Load(template.ID); ok && cached.(notificationPattern).pattern == template.Pattern {
+			return cached.(notificationPattern).re, nil
+		}
+	}
+	re, err := CompileNotificationPattern(template.Pattern)
+	if err != nil {
+		return nil, err
+	}
+	if template.ID != 0 {
+		notificationPatterns.Store(template.ID, notificationPattern{pattern: template.Pattern, re: re})
+	}
+	return re, nil
+}
+
+// ForgetNotificationPattern membuang regex template dari cache, dipanggil saat template diubah atau dihapus
+func ForgetNotificationPattern(templateID uint) {
+	notificationPatterns.Delete(templateID)
+}
+
+// NotificationMatch adalah hasil baca satu pesan dengan template yang cocok
+type NotificationMatch struct {
+	Template    *models.NotificationTemplate `json:"-"`
+	Amount      models.Money                 `json:"amount"` // selalu positif
+	Kind        string                       `json:"kind"`
+	Date        time.Time                    `json:"date"`
+	Merchant    string                       `json:"merchant"`
+	Description string                       `json:"description"`
+	Reference   string                       `json:"reference,omitempty"`
+	Balance     *models.Money                `json:"balance,omitempty"`
+}
+
+// NormalizeNotificationText merapikan teks pesan: baris baru dipertahankan (dipakai template
+// email), spasi berlebih dan baris kosong dibuang.
+func NormalizeNotificationText(text string) string {
+	lines := strings.Split(strings.ReplaceAll(text, "\r", ""), "\n")
+	result := make([]string, 0, len(lines))
+	for _, line := range lines {
+		if line = strings.Join(strings.Fields(line), " "); line != "" {
+			result = append(result, line)
+		}
+	}
+	return strings.Join(result, "\n")
+}
+
+// Group pertama dengan nama tersebut yang terisi (nama group boleh dipakai lebih dari sekali)
+func notificationGroups(re *regexp.Regexp, match []string) map[string]string {
+	groups := make(map[string]string)
+	for i, name := range re.SubexpNames() {
+		if name != "" && groups[name] == "" && i < len(match) {
+			groups[name] = strings.TrimSpace(match[i])
+		}
+	}
+	return groups
+}
+
+// MatchNotification mencoba template berurutan dan mengembalikan hasil template pertama yang
+// cocok dan amount-nya terbaca. Tanggal diambil dari group date, kalau tidak ada dari received.
+func MatchNotification(text string, received time.Time, templates []models.NotificationTemplate) (*NotificationMatch, error) {
+	text = NormalizeNotificationText(text)
+	for i := range templates {
+		template := &templates[i]
+		re, err := templatePattern(template)
+		if err != nil {
+			continue
+		}
+		match := re.FindStringSubmatch(text)
+		if match == nil {
+			continue
+		}
+		groups := notificationGroups(re, match)
+
+		amount, err := ParseIndonesianAmount(groups["amount"])
+		if err != nil || amount.IsZero() {
+			continue
+		}
+		result := &NotificationMatch{
+			Template:    template,
+			Amount:      absAmount(amount),
+			Kind:        template.Kind,
+			Date:        time.Date(received.Year(), received.Month(), received.Day(), 0, 0, 0, 0, time.UTC),
+			Merchant:    strings.Trim(groups["merchant"], " .,:;-"),
+			Description: groups["description"],
+			Reference:   groups["reference"],
+		}
+		if direction := strings.ToLower(groups["direction"]); direction != "" {
+			switch {
+			case containsAny(direction, []string{"masuk", "kredit", "credit", "terima"}) || direction == "cr":
+				result.Kind = "income"
+			case containsAny(direction, []string{"keluar", "debit", "kirim", "bayar"}) || direction == "db":
+				result.Kind = "expense"
+			}
+		}
+		if value := groups["date"]; value != "" {
+			var date time.Time
+			if template.DateFormat != "" {
+				date, err = ParseStatementDate(value, DateLayout(template.DateFormat))
+			} else {
+				date, err = ParseLooseDate(value)
+			}
+			if err == nil {
+				result.Date = date
+			}
+		}
+		if value := groups["balance"]; value != "" {
+			if balance, err := ParseIndonesianAmount(value); err == nil {
+				result.Balance = &balance
+			}
+		}
+		if result.Description == "" {
+			result.Description = result.Merchant
+		}
+		if result.Description == "" {
+			result.Description = template.Bank + " - " + template.Name
+		}
+		return result, nil
+	}
+	return nil, fmt.Errorf("no template matches this message")
+}
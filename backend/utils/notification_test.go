@@ -0,0 +1,139 @@
+package utils
+
+import (
+	"finance-tracker-backend/models"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMatchNotification(t *testing.T) {
+	received := time.Date(2024, 5, 3, 9, 30, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		text     string
+		bank     string
+		kind     string
+		amount   string
+		date     string
+		merchant string
+		wantErr  bool
+	}{
+		{
+			name: "BCA QRIS dengan tanggal",
+			text: "BCA: Transaksi sebesar Rp 125.500 di INDOMARET JKT pada 02/05/2024 berhasil. Info 1500888",
+			bank: "BCA", kind: "expense", amount: "125500", date: "2024-05-02", merchant: "INDOMARET JKT",
+		},
+		{
+			name: "dana masuk tanpa tanggal memakai waktu terima",
+			text: "Dana masuk Rp500.000 dari ANI LESTARI. Saldo akhir Rp1.250.000",
+			bank: "BCA", kind: "income", amount: "500000", date: "2024-05-03", merchant: "ANI LESTARI",
+		},
+		{
+			name: "Jenius kirim uang, spasi dan baris baru dirapikan",
+			text: "Kamu  telah mengirim\r\nRp 250.000 ke Budi Santoso berhasil",
+			bank: "Jenius", kind: "expense", amount: "250000", date: "2024-05-03", merchant: "Budi Santoso",
+		},
+		{
+			name: "email transfer m-BCA",
+			text: "Transfer Berhasil\nNama Penerima : TOKO MAJU\nBank Tujuan : BCA\nNominal Transaksi : Rp 1.500.000,00\n",
+			bank: "BCA", kind: "expense", amount: "1500000", date: "2024-05-03", merchant: "TOKO MAJU",
+		},
+		{
+			name:    "bukan notifikasi transaksi",
+			text:    "JANGAN BERIKAN kode OTP 123456 kepada siapapun",
+			wantErr: true,
+		},
+	}
+
+	templates := DefaultNotificationTemplates()
+	for _, tt := range tests {
+		match, err := MatchNotification(tt.text, received, templates)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("%s: want no match, got %s %s", tt.name, match.Template.Name, match.Amount)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: error %v", tt.name, err)
+			continue
+		}
+		if match.Template.Bank != tt.bank || match.Kind != tt.kind || match.Amount.String() != tt.amount {
+			t.Errorf("%s: %s %s %s, want %s %s %s", tt.name, match.Template.Bank, match.Kind, match.Amount, tt.bank, tt.kind, tt.amount)
+		}
+		if got := match.Date.Format("2006-01-02"); got != tt.date {
+			t.Errorf("%s: date %s, want %s", tt.name, got, tt.date)
+		}
+		if match.Merchant != tt.merchant {
+			t.Errorf("%s: merchant %q, want %q", tt.name, match.Merchant, tt.merchant)
+		}
+	}
+}
+
+func TestMatchNotificationCustomTemplate(t *testing.T) {
+	template := models.NotificationTemplate{
+		ID: 9001, Bank: "Seabank", Name: "Kartu", Kind: "expense", DateFormat: "DD-MM-YYYY",
+		Pattern: `(?P<direction>debit|kredit) (?:rp)?(?P<amount>[\d.]+) (?P<merchant>\S+) tgl (?P<date>\S+) ref (?P<reference>\w+) saldo (?P<balance>[\d.]+)`,
+	}
+	match, err := MatchNotification("Kredit Rp75.000 REFUND tgl 01-05-2024 ref AB12 saldo 1.075.000", time.Now(), []models.NotificationTemplate{template})
+	if err != nil {
+		t.Fatalf("error %v", err)
+	}
+	if match.Kind != "income" || match.Amount.String() != "75000" || match.Date.Format("2006-01-02") != "2024-05-01" ||
+		match.Reference != "AB12" || match.Balance == nil || match.Balance.String() != "1075000" {
+		t.Errorf("match = %+v", match)
+	}
+
+	// Pattern template tersimpan di-cache per ID; setelah diubah pattern baru yang dipakai
+	template.Pattern = `tidak pernah cocok (?P<amount>\d+)`
+	if _, err := MatchNotification("Kredit Rp75.000 REFUND tgl 01-05-2024 ref AB12 saldo 1.075.000", time.Now(), []models.NotificationTemplate{template}); err == nil {
+		t.Error("want no match after the template pattern changed")
+	}
+	ForgetNotificationPattern(template.ID)
+	if _, ok := notificationPatterns.Load(template.ID); ok {
+		t.Error("pattern still cached after ForgetNotificationPattern")
+	}
+}
+
+func TestCompileNotificationPattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		wantErr bool
+	}{
+		{pattern: `bayar (?P<amount>[\d.]+)`},
+		{pattern: `bayar ([\d.]+)`, wantErr: true},
+		{pattern: `bayar (?P<amount>[\d.]+`, wantErr: true},
+		{pattern: `(?P<amount>\d+)` + strings.Repeat("x", maxNotificationPatternLength), wantErr: true},
+	}
+	for _, tt := range tests {
+		if _, err := CompileNotificationPattern(tt.pattern); tt.wantErr != (err != nil) {
+			t.Errorf("CompileNotificationPattern(%.40q) error = %v, wantErr %v", tt.pattern, err, tt.wantErr)
+		}
+	}
+
+	// Semua template bawaan harus valid
+	for _, template := range DefaultNotificationTemplates() {
+		if _, err := CompileNotificationPattern(template.Pattern); err != nil {
+			t.Errorf("default template %s %s: %v", template.Bank, template.Name, err)
+		}
+	}
+}
+
+func TestNotificationBank(t *testing.T) {
+	tests := []struct {
+		sender string
+		want   string
+	}{
+		{"BCA", "BCA"},
+		{"notifikasi@bankmandiri.co.id", "Mandiri"},
+		{"BRImo", "BRI"},
+		{"Jenius <noreply@jenius.co.id>", "Jenius"},
+		{"+6281234567890", ""},
+	}
+	for _, tt := range tests {
+		if got := NotificationBank(tt.sender); got != tt.want {
+			t.Errorf("NotificationBank(%q) = %q, want %q", tt.sender, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,345 @@
+package utils
+
+import (
+	"finance-tracker-backend/models"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Batas iterasi periode supaya rule yang tidak pernah menghasilkan tanggal
+// (misalnya BYMONTHDAY=31 tiap Februari) tidak looping selamanya
+const maxRecurrencePeriods = 100000
+
+var weekdayCodes = map[string]time.Weekday{
+	"SU": time.Sunday, "MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday,
+	"TH": time.Thursday, "FR": time.Friday, "SA": time.Saturday,
+}
+
+var weekdayNames = [...]string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+
+// Recurrence adalah subset RFC 5545 RRULE yang dipakai recurring transaction:
+// FREQ (DAILY/WEEKLY/MONTHLY/YEARLY), INTERVAL, BYDAY, BYMONTHDAY, BYSETPOS, UNTIL dan COUNT.
+// Semua tanggal diperlakukan sebagai tanggal saja (00:00 UTC).
+type Recurrence struct {
+	Frequency string         // daily, weekly, monthly atau yearly
+	Interval  int            // setiap N periode (default 1)
+	Weekdays  []time.Weekday // weekly: hari dalam minggu; monthly/yearly + SetPos: hari ke-n
+	MonthDay  int            // monthly/yearly: tanggal (negatif dihitung dari akhir bulan)
+	SetPos    int            // monthly/yearly: ke-n (1..5) atau -1 untuk terakhir
+	Start     time.Time
+	Until     *time.Time
+	Count     int // 0 = tanpa batas
+}
+
+// Validate memastikan kombinasi field bisa dipakai untuk generate tanggal
+func (r *Recurrence) Validate() error {
+	switch r.Frequency {
+	case "daily", "weekly", "monthly", "yearly":
+	default:
+		return fmt.Errorf("frequency must be daily, weekly, monthly or yearly")
+	}
+	if r.Interval < 0 || r.Count < 0 {
+		return fmt.Errorf("interval and count must not be negative")
+	}
+	if r.Interval == 0 {
+		r.Interval = 1
+	}
+	if r.Start.IsZero() {
+		return fmt.Errorf("start date is required")
+	}
+	r.Start = truncateDay(r.Start)
+	if r.Until != nil {
+		until := truncateDay(*r.Until)
+		if until.Before(r.Start) {
+			return fmt.Errorf("end date must not be before start date")
+		}
+		r.Until = &until
+	}
+	if r.MonthDay < -31 || r.MonthDay > 31 {
+		return fmt.Errorf("month day must be between -31 and 31")
+	}
+	if r.SetPos != 0 {
+		if r.SetPos < -1 || r.SetPos > 5 {
+			return fmt.Errorf("nth weekday must be 1-5 or -1 for last")
+		}
+		if len(r.Weekdays) != 1 || (r.Frequency != "monthly" && r.Frequency != "yearly") {
+			return fmt.Errorf("nth weekday needs a monthly or yearly frequency and exactly one weekday")
+		}
+		if r.MonthDay != 0 {
+			return fmt.Errorf("nth weekday cannot be combined with month day")
+		}
+	} else if len(r.Weekdays) > 0 && r.Frequency != "weekly" {
+		// BYDAY tanpa urutan (misalnya FREQ=MONTHLY;BYDAY=MO) tidak didukung, jangan diam-diam diabaikan
+		return fmt.Errorf("weekdays need a weekly frequency, or an nth weekday for monthly and yearly")
+	}
+	return nil
+}
+
+// Between mengembalikan occurrence dalam rentang [from, to] (inklusif), maksimal limit buah (0 = tanpa batas).
+// COUNT selalu dihitung dari tanggal start supaya hasilnya sama walaupun dipanggil berkali-kali.
+func (r Recurrence) Between(from, to time.Time, limit int) []time.Time {
+	from, to = truncateDay(from), truncateDay(to)
+	var result []time.Time
+	seen := 0
+
+	for period := 0; period < maxRecurrencePeriods; period++ {
+		candidates := r.periodCandidates(period)
+		if candidates == nil {
+			periodStart := r.periodStart(period)
+			if periodStart.After(to) || (r.Until != nil && periodStart.After(*r.Until)) {
+				break
+			}
+		}
+		for _, day := range candidates {
+			if day.Before(r.Start) {
+				continue
+			}
+			if r.Until != nil && day.After(*r.Until) {
+				return result
+			}
+			seen++
+			if r.Count > 0 && seen > r.Count {
+				return result
+			}
+			if day.After(to) {
+				return result
+			}
+			if !day.Before(from) {
+				result = append(result, day)
+				if limit > 0 && len(result) >= limit {
+					return result
+				}
+			}
+		}
+	}
+	return result
+}
+
+// Next mengembalikan n occurrence pertama pada atau setelah tanggal from
+func (r Recurrence) Next(from time.Time, n int) []time.Time {
+	return r.Between(from, time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC), n)
+}
+
+// Awal periode ke-n (hari, minggu mulai Senin, bulan atau tahun)
+func (r Recurrence) periodStart(n int) time.Time {
+	step := n * r.Interval
+	switch r.Frequency {
+	case "daily":
+		return r.Start.AddDate(0, 0, step)
+	case "weekly":
+		offset := (int(r.Start.Weekday()) + 6) % 7 // Senin = 0
+		return r.Start.AddDate(0, 0, -offset+7*step)
+	case "monthly":
+		return time.Date(r.Start.Year(), r.Start.Month()+time.Month(step), 1, 0, 0, 0, 0, time.UTC)
+	default:
+		return time.Date(r.Start.Year()+step, 1, 1, 0, 0, 0, 0, time.UTC)
+	}
+}
+
+// Tanggal kandidat di periode ke-n, terurut
+func (r Recurrence) periodCandidates(n int) []time.Time {
+	start := r.periodStart(n)
+	switch r.Frequency {
+	case "daily":
+		return []time.Time{start}
+	case "weekly":
+		weekdays := r.Weekdays
+		if len(weekdays) == 0 {
+			weekdays = []time.Weekday{r.Start.Weekday()}
+		}
+		days := make([]time.Time, 0, len(weekdays))
+		for _, wd := range weekdays {
+			days = append(days, start.AddDate(0, 0, (int(wd)+6)%7))
+		}
+		sort.Slice(days, func(i, j int) bool { return days[i].Before(days[j]) })
+		return days
+	case "monthly":
+		return monthCandidate(start.Year(), start.Month(), r)
+	default:
+		return monthCandidate(start.Year(), r.Start.Month(), r)
+	}
+}
+
+func monthCandidate(year int, month time.Month, r Recurrence) []time.Time {
+	first := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+	daysInMonth := first.AddDate(0, 1, -1).Day()
+
+	if r.SetPos != 0 {
+		wd := r.Weekdays[0]
+		if r.SetPos > 0 {
+			day := 1 + (int(wd)-int(first.Weekday())+7)%7 + 7*(r.SetPos-1)
+			if day > daysInMonth {
+				return nil
+			}
+			return []time.Time{first.AddDate(0, 0, day-1)}
+		}
+		last := first.AddDate(0, 0, daysInMonth-1)
+		back := (int(last.Weekday()) - int(wd) + 7) % 7
+		return []time.Time{last.AddDate(0, 0, -back)}
+	}
+
+	day := r.MonthDay
+	if day == 0 {
+		day = r.Start.Day()
+	}
+	if day < 0 {
+		day = daysInMonth + day + 1
+	}
+	// Sesuai RRULE, bulan yang tidak punya tanggal tersebut dilewati (pakai -1 untuk akhir bulan)
+	if day < 1 || day > daysInMonth {
+		return nil
+	}
+	return []time.Time{first.AddDate(0, 0, day-1)}
+}
+
+// RRule menulis recurrence sebagai string RRULE RFC 5545 (tanpa DTSTART)
+func (r Recurrence) RRule() string {
+	parts := []string{"FREQ=" + strings.ToUpper(r.Frequency)}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if len(r.Weekdays) > 0 {
+		codes := make([]string, len(r.Weekdays))
+		for i, wd := range r.Weekdays {
+			codes[i] = weekdayNames[wd]
+		}
+		parts = append(parts, "BYDAY="+strings.Join(codes, ","))
+	}
+	if r.MonthDay != 0 {
+		parts = append(parts, "BYMONTHDAY="+strconv.Itoa(r.MonthDay))
+	}
+	if r.SetPos != 0 {
+		parts = append(parts, "BYSETPOS="+strconv.Itoa(r.SetPos))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	if r.Until != nil {
+		parts = append(parts, "UNTIL="+r.Until.Format("20060102"))
+	}
+	return strings.Join(parts, ";")
+}
+
+// ParseRRule membaca string RRULE ("RRULE:" prefix opsional) dengan tanggal start yang diberikan.
+// BYDAY dengan prefix angka (misalnya "2MO" atau "-1FR") diterjemahkan ke BYSETPOS.
+func ParseRRule(rule string, start time.Time) (Recurrence, error) {
+	r := Recurrence{Start: start}
+	rule = strings.TrimPrefix(strings.TrimSpace(rule), "RRULE:")
+
+	for _, part := range strings.Split(rule, ";") {
+		if part == "" {
+			continue
+		}
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 {
+			return r, fmt.Errorf("invalid RRULE part %q", part)
+		}
+		key, value := strings.ToUpper(kv[0]), strings.ToUpper(kv[1])
+
+		switch key {
+		case "FREQ":
+			r.Frequency = strings.ToLower(value)
+		case "INTERVAL", "COUNT", "BYMONTHDAY", "BYSETPOS":
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				return r, fmt.Errorf("invalid %s value %q", key, value)
+			}
+			switch key {
+			case "INTERVAL":
+				r.Interval = n
+			case "COUNT":
+				r.Count = n
+			case "BYMONTHDAY":
+				r.MonthDay = n
+			default:
+				r.SetPos = n
+			}
+		case "UNTIL":
+			layout := "20060102"
+			if len(value) > 8 {
+				layout = "20060102T150405Z"
+			}
+			until, err := time.Parse(layout, value)
+			if err != nil {
+				return r, fmt.Errorf("invalid UNTIL value %q", value)
+			}
+			r.Until = &until
+		case "BYDAY":
+			for _, code := range strings.Split(value, ",") {
+				if len(code) > 2 {
+					pos, err := strconv.Atoi(code[:len(code)-2])
+					if err != nil {
+						return r, fmt.Errorf("invalid BYDAY value %q", code)
+					}
+					r.SetPos = pos
+					code = code[len(code)-2:]
+				}
+				wd, ok := weekdayCodes[code]
+				if !ok {
+					return r, fmt.Errorf("invalid BYDAY value %q", code)
+				}
+				r.Weekdays = append(r.Weekdays, wd)
+			}
+		case "WKST":
+			if value != "MO" {
+				return r, fmt.Errorf("only WKST=MO is supported")
+			}
+		default:
+			return r, fmt.Errorf("unsupported RRULE part %s", key)
+		}
+	}
+
+	if err := r.Validate(); err != nil {
+		return r, err
+	}
+	return r, nil
+}
+
+// ParseWeekdays membaca kode hari ("MO", "FR", ...) menjadi time.Weekday
+func ParseWeekdays(codes []string) ([]time.Weekday, error) {
+	weekdays := make([]time.Weekday, 0, len(codes))
+	for _, code := range codes {
+		wd, ok := weekdayCodes[strings.ToUpper(strings.TrimSpace(code))]
+		if !ok {
+			return nil, fmt.Errorf("invalid weekday %q", code)
+		}
+		weekdays = append(weekdays, wd)
+	}
+	return weekdays, nil
+}
+
+// FormatWeekdays menulis weekday sebagai kode dipisah koma ("MO,FR")
+func FormatWeekdays(weekdays []time.Weekday) string {
+	codes := make([]string, len(weekdays))
+	for i, wd := range weekdays {
+		codes[i] = weekdayNames[wd]
+	}
+	return strings.Join(codes, ",")
+}
+
+// RecurrenceOf membangun Recurrence dari kolom-kolom recurring template
+func RecurrenceOf(t *models.RecurringTransaction) (Recurrence, error) {
+	r := Recurrence{
+		Frequency: t.Frequency,
+		Interval:  t.Interval,
+		MonthDay:  t.MonthDay,
+		SetPos:    t.SetPos,
+		Start:     t.StartDate,
+		Until:     t.EndDate,
+		Count:     t.Count,
+	}
+	if t.Weekdays != "" {
+		weekdays, err := ParseWeekdays(strings.Split(t.Weekdays, ","))
+		if err != nil {
+			return r, err
+		}
+		r.Weekdays = weekdays
+	}
+	if err := r.Validate(); err != nil {
+		return r, err
+	}
+	return r, nil
+}
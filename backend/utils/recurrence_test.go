@@ -0,0 +1,135 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func mustDate(s string) time.Time {
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func formatDays(days []time.Time) []string {
+	result := make([]string, len(days))
+	for i, d := range days {
+		result[i] = d.Format("2006-01-02")
+	}
+	return result
+}
+
+func TestParseRRuleRoundTrip(t *testing.T) {
+	tests := []struct {
+		rule  string
+		start string
+		want  string // RRule() setelah parse; kosong = sama dengan rule
+	}{
+		{rule: "FREQ=DAILY", start: "2024-01-01"},
+		{rule: "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,FR", start: "2024-01-01"},
+		{rule: "FREQ=MONTHLY;BYMONTHDAY=-1", start: "2024-01-31"},
+		{rule: "FREQ=MONTHLY;BYDAY=FR;BYSETPOS=-1", start: "2024-01-01"},
+		{rule: "RRULE:FREQ=MONTHLY;BYDAY=2MO;COUNT=6", start: "2024-01-01", want: "FREQ=MONTHLY;BYDAY=MO;BYSETPOS=2;COUNT=6"},
+		{rule: "FREQ=YEARLY;UNTIL=20301231", start: "2024-03-15"},
+		{rule: "FREQ=WEEKLY;UNTIL=20240630T235959Z;WKST=MO", start: "2024-01-01", want: "FREQ=WEEKLY;UNTIL=20240630"},
+		{rule: "freq=monthly;interval=3", start: "2024-01-10", want: "FREQ=MONTHLY;INTERVAL=3"},
+	}
+
+	for _, tt := range tests {
+		r, err := ParseRRule(tt.rule, mustDate(tt.start))
+		if err != nil {
+			t.Errorf("ParseRRule(%q) error: %v", tt.rule, err)
+			continue
+		}
+		want := tt.want
+		if want == "" {
+			want = tt.rule
+		}
+		got := r.RRule()
+		if got != want {
+			t.Errorf("ParseRRule(%q).RRule() = %q, want %q", tt.rule, got, want)
+		}
+		again, err := ParseRRule(got, mustDate(tt.start))
+		if err != nil || again.RRule() != got {
+			t.Errorf("re-parse of %q = %q, %v", got, again.RRule(), err)
+		}
+	}
+}
+
+func TestParseRRuleInvalid(t *testing.T) {
+	tests := []string{
+		"",
+		"FREQ=HOURLY",
+		"FREQ=DAILY;INTERVAL=x",
+		"FREQ=MONTHLY;BYDAY=MO",
+		"FREQ=YEARLY;BYDAY=MO,WE",
+		"FREQ=DAILY;BYDAY=MO",
+		"FREQ=MONTHLY;BYDAY=6MO",
+		"FREQ=MONTHLY;BYDAY=2MO;BYMONTHDAY=3",
+		"FREQ=WEEKLY;BYDAY=XX",
+		"FREQ=WEEKLY;WKST=SU",
+		"FREQ=DAILY;BYHOUR=9",
+		"FREQ=DAILY;UNTIL=20231231",
+	}
+
+	for _, rule := range tests {
+		if r, err := ParseRRule(rule, mustDate("2024-01-01")); err == nil {
+			t.Errorf("ParseRRule(%q) = %q, want error", rule, r.RRule())
+		}
+	}
+}
+
+func TestRecurrenceNext(t *testing.T) {
+	tests := []struct {
+		rule  string
+		start string
+		from  string
+		n     int
+		want  []string
+	}{
+		{
+			rule: "FREQ=MONTHLY;BYMONTHDAY=31", start: "2024-01-31", from: "2024-01-01", n: 3,
+			want: []string{"2024-01-31", "2024-03-31", "2024-05-31"},
+		},
+		{
+			rule: "FREQ=MONTHLY;BYMONTHDAY=-1", start: "2024-01-31", from: "2024-01-01", n: 3,
+			want: []string{"2024-01-31", "2024-02-29", "2024-03-31"},
+		},
+		{
+			rule: "FREQ=MONTHLY;BYDAY=-1FR", start: "2024-01-01", from: "2024-01-01", n: 3,
+			want: []string{"2024-01-26", "2024-02-23", "2024-03-29"},
+		},
+		{
+			rule: "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,FR", start: "2024-01-03", from: "2024-01-01", n: 4,
+			want: []string{"2024-01-05", "2024-01-15", "2024-01-19", "2024-01-29"},
+		},
+		{
+			rule: "FREQ=DAILY;COUNT=3", start: "2024-01-01", from: "2024-01-02", n: 10,
+			want: []string{"2024-01-02", "2024-01-03"},
+		},
+		{
+			rule: "FREQ=YEARLY", start: "2024-02-29", from: "2024-01-01", n: 2,
+			want: []string{"2024-02-29", "2028-02-29"},
+		},
+	}
+
+	for _, tt := range tests {
+		r, err := ParseRRule(tt.rule, mustDate(tt.start))
+		if err != nil {
+			t.Fatalf("ParseRRule(%q) error: %v", tt.rule, err)
+		}
+		got := formatDays(r.Next(mustDate(tt.from), tt.n))
+		if len(got) != len(tt.want) {
+			t.Errorf("%s: Next = %v, want %v", tt.rule, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("%s: Next = %v, want %v", tt.rule, got, tt.want)
+				break
+			}
+		}
+	}
+}
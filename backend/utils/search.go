@@ -0,0 +1,152 @@
+package utils
+
+import (
+	"html"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Panjang token minimum FULLTEXT InnoDB (innodb_ft_min_token_size), kata yang lebih pendek
+// tidak masuk index sehingga dicari dengan LIKE
+const MinFullTextWord = 3
+
+// SearchQuery adalah hasil parsing parameter q: frasa dalam tanda kutip ("grab car")
+// dan kata lepas yang dicocokkan sebagai prefix (grab -> grabfood, grabcar)
+type SearchQuery struct {
+	Phrases []string
+	Words   []string
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// Pecah teks jadi kata (huruf/angka saja), lowercase
+func searchTokens(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool { return !isWordRune(r) })
+}
+
+// ParseSearchQuery membaca q seperti `grab "jl sudirman" mar`. Operator boolean MySQL
+// (+ - * ~ < > ( ) @) dibuang supaya input user tidak bisa mengubah arti query.
+func ParseSearchQuery(q string) SearchQuery {
+	var sq SearchQuery
+	parts := strings.Split(q, `"`)
+	for i, part := range parts {
+		tokens := searchTokens(part)
+		if len(tokens) == 0 {
+			continue
+		}
+		// Bagian ganjil berada di dalam tanda kutip (kutip yang tidak ditutup dianggap frasa sampai akhir)
+		if i%2 == 1 && len(tokens) > 1 {
+			sq.Phrases = append(sq.Phrases, strings.Join(tokens, " "))
+		} else {
+			sq.Words = append(sq.Words, tokens...)
+		}
+	}
+	return sq
+}
+
+func (sq SearchQuery) IsEmpty() bool {
+	return len(sq.Phrases) == 0 && len(sq.Words) == 0
+}
+
+// BooleanQuery menulis query untuk MATCH ... AGAINST (... IN BOOLEAN MODE):
+// semua frasa dan kata wajib ada, kata dicocokkan sebagai prefix
+func (sq SearchQuery) BooleanQuery() string {
+	var parts []string
+	for _, phrase := range sq.Phrases {
+		parts = append(parts, `+"`+phrase+`"`)
+	}
+	for _, word := range sq.Words {
+		if utf8.RuneCountInString(word) >= MinFullTextWord {
+			parts = append(parts, "+"+word+"*")
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// ShortWords adalah kata yang terlalu pendek untuk index FULLTEXT
+func (sq SearchQuery) ShortWords() []string {
+	var words []string
+	for _, word := range sq.Words {
+		if utf8.RuneCountInString(word) < MinFullTextWord {
+			words = append(words, word)
+		}
+	}
+	return words
+}
+
+// Regex untuk mencari posisi match: frasa persis (pemisah apa saja di antara kata),
+// kata sebagai prefix di awal kata
+func (sq SearchQuery) matcher() *regexp.Regexp {
+	var alts []string
+	for _, phrase := range sq.Phrases {
+		words := strings.Split(phrase, " ")
+		for i, w := range words {
+			words[i] = regexp.QuoteMeta(w)
+		}
+		alts = append(alts, strings.Join(words, `[^\p{L}\p{N}]+`))
+	}
+	for _, word := range sq.Words {
+		alts = append(alts, regexp.QuoteMeta(word)+`[\p{L}\p{N}]*`)
+	}
+	if len(alts) == 0 {
+		return nil
+	}
+	return regexp.MustCompile(`(?i)(?:^|[^\p{L}\p{N}])(` + strings.Join(alts, "|") + `)`)
+}
+
+// Highlight mengembalikan potongan text (maksimal sekitar maxLen karakter) di sekitar match pertama,
+// dengan bagian yang cocok dibungkus <mark>...</mark>. Text di-escape sehingga aman dirender sebagai HTML.
+// String kosong kalau tidak ada yang cocok.
+func Highlight(text string, sq SearchQuery, maxLen int) string {
+	re := sq.matcher()
+	if re == nil {
+		return ""
+	}
+	matches := re.FindAllStringSubmatchIndex(text, -1)
+	if len(matches) == 0 {
+		return ""
+	}
+
+	// Potong di sekitar match pertama (di batas rune)
+	start, end := 0, len(text)
+	if utf8.RuneCountInString(text) > maxLen {
+		first := matches[0][2]
+		start = first
+		for back := 0; start > 0 && back < maxLen/3; back++ {
+			_, size := utf8.DecodeLastRuneInString(text[:start])
+			start -= size
+		}
+		end = start
+		for n := 0; end < len(text) && n < maxLen; n++ {
+			_, size := utf8.DecodeRuneInString(text[end:])
+			end += size
+		}
+	}
+
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString("…")
+	}
+	pos := start
+	for _, m := range matches {
+		from, to := m[2], m[3]
+		if from < pos || from >= end {
+			continue
+		}
+		if to > end {
+			to = end
+		}
+		b.WriteString(html.EscapeString(text[pos:from]))
+		b.WriteString("<mark>" + html.EscapeString(text[from:to]) + "</mark>")
+		pos = to
+	}
+	b.WriteString(html.EscapeString(text[pos:end]))
+	if end < len(text) {
+		b.WriteString("…")
+	}
+	return b.String()
+}
@@ -0,0 +1,197 @@
+package utils
+
+import (
+	"finance-tracker-backend/models"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// StatementRow adalah satu transaksi hasil parsing file statement (CSV, OFX, dll).
+// Amount selalu positif, arah uangnya ada di Kind.
+type StatementRow struct {
+	Line        int          `json:"line"` // nomor baris/record di file, untuk pesan error
+	Date        time.Time    `json:"date"`
+	Amount      models.Money `json:"amount"`
+	Kind        string       `json:"kind"` // income atau expense
+	Currency    string       `json:"currency,omitempty"`
+	Description string       `json:"description"`
+	Category    string       `json:"category,omitempty"`  // nama category dari file, dicocokkan dengan category user
+	Payee       string       `json:"payee,omitempty"`     // nama payee dari file
+	Reference   string       `json:"reference,omitempty"` // ID transaksi dari bank (kalau ada), dipakai untuk deteksi duplikat
+	Transfer    bool         `json:"transfer,omitempty"`  // top up / tarik saldo: pindah uang antar rekening sendiri, Kind = arah di rekening ini
+	Error       string       `json:"error,omitempty"`     // baris tidak bisa dibaca, tidak ikut di-import
+}
+
+// Statement adalah satu statement rekening dari file import (OFX, MT940, CAMT.053, QIF)
+// beserta saldo awal/akhir kalau formatnya menyediakan
+type Statement struct {
+	AccountNumber  string         `json:"account_number"`
+	Currency       string         `json:"currency"`
+	OpeningBalance *models.Money  `json:"opening_balance,omitempty"`
+	OpeningDate    *time.Time     `json:"opening_date,omitempty"`
+	ClosingBalance *models.Money  `json:"closing_balance,omitempty"`
+	ClosingDate    *time.Time     `json:"closing_date,omitempty"`
+	Rows           []StatementRow `json:"-"`
+}
+
+// Awalan mata uang di nominal ("Rp", "Rp.", "IDR")
+var currencyPrefix = regexp.MustCompile(`(?i)\b(rp\.?|idr)\s*`)
+
+// Nama bulan Indonesia yang berbeda dari singkatan Inggris
+var indonesianMonths = strings.NewReplacer(
+	"Januari", "Jan", "Februari", "Feb", "Maret", "Mar", "April", "Apr", "Juni", "Jun", "Juli", "Jul",
+	"Agustus", "Aug", "September", "Sep", "Oktober", "Oct", "November", "Nov", "Desember", "Dec",
+	"Peb", "Feb", "Mei", "May", "Agu", "Aug", "Ags", "Aug", "Okt", "Oct", "Nop", "Nov", "Des", "Dec",
+)
+
+// Token format tanggal yang dipahami user -> layout Go (urutan penting: token panjang dulu)
+var dateFormatTokens = strings.NewReplacer(
+	"YYYY", "2006", "YY", "06", "MMM", "Jan", "MM", "01", "DD", "02", "M", "1", "D", "2",
+	"HH", "15", "mm", "04", "ss", "05",
+)
+
+// DateLayout mengubah format seperti "DD/MM/YYYY" atau "D MMM YYYY" menjadi layout Go
+func DateLayout(format string) string {
+	return dateFormatTokens.Replace(format)
+}
+
+// ParseStatementDate membaca tanggal dengan layout Go, nama bulan Indonesia ikut dikenali
+func ParseStatementDate(value, layout string) (time.Time, error) {
+	value = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(value), "'"))
+	if strings.Contains(layout, "Jan") {
+		value = indonesianMonths.Replace(value)
+	}
+	date, err := time.Parse(layout, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q", value)
+	}
+	return time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC), nil
+}
+
+// Layout tanggal yang dicoba berurutan (nama bulan Indonesia ikut dikenali)
+var looseDateLayouts = []string{
+	"2006-01-02", "2006/01/02", "2/1/2006", "2-1-2006", "2.1.2006", "2/1/06",
+	"2 Jan 2006", "2 January 2006", "Jan 2 2006", "January 2 2006", "2-Jan-2006", "2-Jan-06",
+}
+
+// Jam di belakang tanggal ("14:30", "pukul 14.30 WIB", "T14:30:00+07:00")
+var timeSuffix = regexp.MustCompile(`(?i)[ T,]+(pukul\s+)?\d{1,2}[:.]\d{2}([:.]\d{2})?.*$`)
+
+// ParseLooseDate membaca tanggal yang formatnya tidak diketahui pasti (export e-wallet, notifikasi
+// bank): "02/05/2024 14:30", "2 Mei 2024, 14:30 WIB", "2024-05-02T14:30:00+07:00", dll.
+// Urutan hari/bulan selalu hari dulu; jamnya dibuang.
+func ParseLooseDate(value string) (time.Time, error) {
+	normalized := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(value), "'"))
+	normalized = timeSuffix.ReplaceAllString(normalized, "")
+	normalized = strings.Join(strings.Fields(strings.ReplaceAll(normalized, ",", " ")), " ")
+	for _, layout := range looseDateLayouts {
+		if date, err := ParseStatementDate(normalized, layout); err == nil {
+			return date, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q", value)
+}
+
+// ParseStatementAmount membaca angka dari statement: "1.500.000,00", "(25,000.50)", "Rp 10.000",
+// "150.000-" dan sejenisnya. decimalSeparator "," berarti titik adalah pemisah ribuan.
+func ParseStatementAmount(value, decimalSeparator string) (models.Money, error) {
+	s := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(value), "'"))
+	negative := false
+	if strings.HasPrefix(s, "(") && strings.HasSuffix(s, ")") {
+		negative = true
+		s = s[1 : len(s)-1]
+	}
+	s = strings.TrimSpace(s)
+	if strings.HasSuffix(s, "-") {
+		negative = true
+		s = strings.TrimSuffix(s, "-")
+	}
+
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case r == '-':
+			negative = !negative
+		case string(r) == decimalSeparator && b.Len() > 0: // "Rp." di depan angka bukan desimal
+			b.WriteByte('.')
+		}
+	}
+	// Desimal tanpa angka di belakangnya ("1500," di MT940) sama dengan bilangan bulat
+	digits := strings.TrimSuffix(b.String(), ".")
+	if digits == "" || strings.Count(digits, ".") > 1 {
+		return 0, fmt.Errorf("invalid amount %q", value)
+	}
+
+	amount, err := models.ParseMoney(digits)
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount %q", value)
+	}
+	if negative {
+		amount = -amount
+	}
+	return amount, nil
+}
+
+// ParseIndonesianAmount membaca nominal gaya Indonesia dari export e-wallet dan notifikasi bank:
+// "Rp150.000", "-Rp 15,000", "15000.00", "Rp. 1.250.000,50". Titik diikuti 3 digit adalah
+// pemisah ribuan; pemisah desimal ditebak dari isinya karena tiap sumber berbeda.
+func ParseIndonesianAmount(value string) (models.Money, error) {
+	s := currencyPrefix.ReplaceAllString(strings.TrimSpace(value), "")
+	lastDot, lastComma := strings.LastIndex(s, "."), strings.LastIndex(s, ",")
+	separator := "."
+	switch {
+	case lastDot >= 0 && lastComma >= 0:
+		if lastComma > lastDot {
+			separator = ","
+		}
+	case lastComma >= 0:
+		// Satu koma diikuti selain 3 digit adalah desimal ("15000,5"), selain itu pemisah ribuan
+		if strings.Count(s, ",") == 1 && digitsAfter(s, lastComma) != 3 {
+			separator = ","
+		}
+	case lastDot >= 0:
+		if strings.Count(s, ".") > 1 || digitsAfter(s, lastDot) == 3 {
+			separator = ","
+		}
+	}
+	return ParseStatementAmount(s, separator)
+}
+
+func digitsAfter(s string, index int) int {
+	count := 0
+	for _, r := range s[index+1:] {
+		if r < '0' || r > '9' {
+			break
+		}
+		count++
+	}
+	return count
+}
+
+// Amount di format statement standar (OFX, QIF) memakai titik desimal, tapi sebagian bank
+// di luar US menulis koma ("-150000,00")
+func statementAmount(value string) (models.Money, error) {
+	if strings.Contains(value, ",") && !strings.Contains(value, ".") {
+		return ParseStatementAmount(value, ",")
+	}
+	return ParseStatementAmount(value, ".")
+}
+
+// Kind dan amount positif dari amount bertanda (negatif = pengeluaran)
+func signedStatementAmount(amount models.Money) (models.Money, string) {
+	if amount < 0 {
+		return -amount, "expense"
+	}
+	return amount, "income"
+}
+
+func absAmount(amount models.Money) models.Money {
+	if amount < 0 {
+		return -amount
+	}
+	return amount
+}
@@ -0,0 +1,248 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/xml"
+	"finance-tracker-backend/models"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Struktur CAMT.053 (ISO 20022) yang dipakai; tag tanpa namespace supaya semua versi
+// (camt.053.001.02 sampai .08) terbaca
+type camtDocument struct {
+	Statements []camtStatement `xml:"BkToCstmrStmt>Stmt"`
+}
+
+type camtStatement struct {
+	ID      string `xml:"Id"`
+	Account struct {
+		IBAN     string `xml:"Id>IBAN"`
+		Other    string `xml:"Id>Othr>Id"`
+		Currency string `xml:"Ccy"`
+	} `xml:"Acct"`
+	Balances []camtBalance `xml:"Bal"`
+	Entries  []camtEntry   `xml:"Ntry"`
+}
+
+type camtAmount struct {
+	Value    string `xml:",chardata"`
+	Currency string `xml:"Ccy,attr"`
+}
+
+type camtDate struct {
+	Date     string `xml:"Dt"`
+	DateTime string `xml:"DtTm"`
+}
+
+type camtBalance struct {
+	Type      string     `xml:"Tp>CdOrPrtry>Cd"`
+	Amount    camtAmount `xml:"Amt"`
+	Indicator string     `xml:"CdtDbtInd"`
+	Date      camtDate   `xml:"Dt"`
+}
+
+// Status entry: teks langsung (BOOK) atau <Cd>BOOK</Cd> di camt.053.001.08
+type camtStatus struct {
+	Value string `xml:",chardata"`
+	Code  string `xml:"Cd"`
+}
+
+type camtParty struct {
+	Name      string `xml:"Nm"`
+	PartyName string `xml:"Pty>Nm"` // camt.053.001.08
+}
+
+type camtEntry struct {
+	Reference    string     `xml:"NtryRef"`
+	Amount       camtAmount `xml:"Amt"`
+	Indicator    string     `xml:"CdtDbtInd"`
+	Reversal     bool       `xml:"RvslInd"`
+	Status       camtStatus `xml:"Sts"`
+	BookingDate  camtDate   `xml:"BookgDt"`
+	ValueDate    camtDate   `xml:"ValDt"`
+	ServicerRef  string     `xml:"AcctSvcrRef"`
+	Info         string     `xml:"AddtlNtryInf"`
+	Transactions []struct {
+		ServicerRef string    `xml:"Refs>AcctSvcrRef"`
+		EndToEndID  string    `xml:"Refs>EndToEndId"`
+		TxID        string    `xml:"Refs>TxId"`
+		Debtor      camtParty `xml:"RltdPties>Dbtr"`
+		Creditor    camtParty `xml:"RltdPties>Cdtr"`
+		Remittance  []string  `xml:"RmtInf>Ustrd"`
+		Info        string    `xml:"AddtlTxInf"`
+	} `xml:"NtryDtls>TxDtls"`
+}
+
+func (d camtDate) parse() (time.Time, error) {
+	value := strings.TrimSpace(d.Date)
+	if value == "" {
+		value = strings.TrimSpace(d.DateTime)
+	}
+	if len(value) < 10 {
+		return time.Time{}, fmt.Errorf("invalid date %q", value)
+	}
+	date, err := time.Parse("2006-01-02", value[:10])
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q", value)
+	}
+	return date, nil
+}
+
+func (p camtParty) name() string {
+	if p.Name != "" {
+		return strings.TrimSpace(p.Name)
+	}
+	return strings.TrimSpace(p.PartyName)
+}
+
+// Referensi yang tidak bermakna di CAMT
+func camtReference(values ...string) string {
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value != "" && !strings.EqualFold(value, "NOTPROVIDED") && !strings.EqualFold(value, "NONREF") {
+			return value
+		}
+	}
+	return ""
+}
+
+// ParseCAMT053 membaca statement ISO 20022 camt.053. Saldo OPBD/PRCD jadi saldo awal,
+// CLBD saldo akhir. Entry yang belum dibukukan (PDNG/INFO) dilewati.
+func ParseCAMT053(data []byte) ([]Statement, error) {
+	var document camtDocument
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.CharsetReader = func(charset string, input io.Reader) (io.Reader, error) {
+		// File bank kadang menulis encoding="ISO-8859-1"; isinya tetap dibaca apa adanya
+		return input, nil
+	}
+	if err := decoder.Decode(&document); err != nil {
+		return nil, fmt.Errorf("invalid XML: %v", err)
+	}
+	if len(document.Statements) == 0 {
+		return nil, fmt.Errorf("file contains no camt.053 statement")
+	}
+
+	statements := make([]Statement, 0, len(document.Statements))
+	for _, source := range document.Statements {
+		statement := Statement{AccountNumber: source.Account.IBAN}
+		if statement.AccountNumber == "" {
+			statement.AccountNumber = source.Account.Other
+		}
+		statement.Currency, _ = NormalizeCurrency(source.Account.Currency)
+
+		for _, balance := range source.Balances {
+			amount, err := camtSignedAmount(balance.Amount.Value, balance.Indicator)
+			if err != nil {
+				continue
+			}
+			date, err := balance.Date.parse()
+			if err != nil {
+				continue
+			}
+			if statement.Currency == "" {
+				statement.Currency, _ = NormalizeCurrency(balance.Amount.Currency)
+			}
+			switch balance.Type {
+			case "OPBD", "PRCD":
+				if statement.OpeningBalance == nil || balance.Type == "OPBD" {
+					statement.OpeningBalance, statement.OpeningDate = &amount, &date
+				}
+			case "CLBD":
+				statement.ClosingBalance, statement.ClosingDate = &amount, &date
+			}
+		}
+
+		for i, entry := range source.Entries {
+			status := strings.TrimSpace(entry.Status.Value)
+			if entry.Status.Code != "" {
+				status = strings.TrimSpace(entry.Status.Code)
+			}
+			if status != "" && status != "BOOK" {
+				continue
+			}
+			row := camtRow(entry)
+			row.Line = i + 1
+			if row.Currency == "" {
+				row.Currency = statement.Currency
+			}
+			statement.Rows = append(statement.Rows, row)
+		}
+		statements = append(statements, statement)
+	}
+	return statements, nil
+}
+
+func camtSignedAmount(value, indicator string) (models.Money, error) {
+	amount, err := ParseStatementAmount(value, ".")
+	if err != nil {
+		return 0, err
+	}
+	if strings.TrimSpace(indicator) == "DBIT" {
+		amount = -amount
+	}
+	return amount, nil
+}
+
+func camtRow(entry camtEntry) StatementRow {
+	row := StatementRow{}
+	if code, ok := NormalizeCurrency(entry.Amount.Currency); ok {
+		row.Currency = code
+	}
+
+	// Lawan transaksi: untuk uang masuk pengirimnya (Dbtr), untuk uang keluar penerimanya (Cdtr)
+	var name string
+	var remittance []string
+	var detailRefs []string
+	for _, detail := range entry.Transactions {
+		if name == "" {
+			if entry.Indicator == "CRDT" {
+				name = detail.Debtor.name()
+			} else {
+				name = detail.Creditor.name()
+			}
+		}
+		remittance = append(remittance, detail.Remittance...)
+		if len(detail.Remittance) == 0 && detail.Info != "" {
+			remittance = append(remittance, detail.Info)
+		}
+		detailRefs = append(detailRefs, detail.ServicerRef, detail.TxID, detail.EndToEndID)
+	}
+	purpose := strings.Join(strings.Fields(strings.Join(remittance, " ")), " ")
+	if purpose == "" {
+		purpose = strings.Join(strings.Fields(entry.Info), " ")
+	}
+	row.Payee = name
+	row.Description = strings.Trim(strings.Join([]string{name, purpose}, " - "), " -")
+	row.Reference = camtReference(append([]string{entry.ServicerRef, entry.Reference}, detailRefs...)...)
+
+	date, err := entry.BookingDate.parse()
+	if err != nil {
+		if date, err = entry.ValueDate.parse(); err != nil {
+			row.Error = err.Error()
+			return row
+		}
+	}
+	row.Date = date
+
+	amount, err := ParseStatementAmount(entry.Amount.Value, ".")
+	if err != nil {
+		row.Error = err.Error()
+		return row
+	}
+	// Entry pembatalan (RvslInd) membalik arah CdtDbtInd
+	income := entry.Indicator == "CRDT"
+	if entry.Reversal {
+		income = !income
+	}
+	row.Amount, row.Kind = amount, "expense"
+	if income {
+		row.Kind = "income"
+	}
+	if row.Amount.IsZero() {
+		row.Error = "amount is zero"
+	}
+	return row
+}
@@ -0,0 +1,113 @@
+package utils
+
+import "testing"
+
+// camt.053.001.02 dengan entry booked, pending dan reversal
+const sampleCAMT053 = `<?xml version="1.0" encoding="ISO-8859-1"?>
+<Document xmlns="urn:iso:std:iso:20022:tech:xsd:camt.053.001.02">
+  <BkToCstmrStmt>
+    <GrpHdr><MsgId>STMT-20240331</MsgId><CreDtTm>2024-04-01T06:00:00</CreDtTm></GrpHdr>
+    <Stmt>
+      <Id>20240331-001</Id>
+      <Acct><Id><IBAN>NL91ABNA0417164300</IBAN></Id><Ccy>EUR</Ccy></Acct>
+      <Bal>
+        <Tp><CdOrPrtry><Cd>OPBD</Cd></CdOrPrtry></Tp>
+        <Amt Ccy="EUR">1500.00</Amt><CdtDbtInd>CRDT</CdtDbtInd>
+        <Dt><Dt>2024-03-01</Dt></Dt>
+      </Bal>
+      <Bal>
+        <Tp><CdOrPrtry><Cd>CLBD</Cd></CdOrPrtry></Tp>
+        <Amt Ccy="EUR">3410.75</Amt><CdtDbtInd>CRDT</CdtDbtInd>
+        <Dt><Dt>2024-03-31</Dt></Dt>
+      </Bal>
+      <Ntry>
+        <NtryRef>0001</NtryRef>
+        <Amt Ccy="EUR">89.25</Amt>
+        <CdtDbtInd>DBIT</CdtDbtInd>
+        <Sts>BOOK</Sts>
+        <BookgDt><Dt>2024-03-04</Dt></BookgDt>
+        <ValDt><Dt>2024-03-04</Dt></ValDt>
+        <AcctSvcrRef>NOTPROVIDED</AcctSvcrRef>
+        <NtryDtls><TxDtls>
+          <Refs><EndToEndId>E2E-778899</EndToEndId></Refs>
+          <RltdPties><Cdtr><Nm>Albert Heijn</Nm></Cdtr></RltdPties>
+          <RmtInf><Ustrd>Boodschappen</Ustrd><Ustrd>pas 12</Ustrd></RmtInf>
+        </TxDtls></NtryDtls>
+      </Ntry>
+      <Ntry>
+        <Amt Ccy="EUR">2000.00</Amt>
+        <CdtDbtInd>CRDT</CdtDbtInd>
+        <Sts>BOOK</Sts>
+        <BookgDt><DtTm>2024-03-25T09:30:00</DtTm></BookgDt>
+        <AcctSvcrRef>BANKREF-2503</AcctSvcrRef>
+        <NtryDtls><TxDtls>
+          <RltdPties><Dbtr><Nm>ACME B.V.</Nm></Dbtr></RltdPties>
+          <RmtInf><Ustrd>Salaris maart</Ustrd></RmtInf>
+        </TxDtls></NtryDtls>
+      </Ntry>
+      <Ntry>
+        <Amt Ccy="EUR">5.00</Amt>
+        <CdtDbtInd>DBIT</CdtDbtInd>
+        <RvslInd>true</RvslInd>
+        <Sts>BOOK</Sts>
+        <BookgDt><Dt>2024-03-28</Dt></BookgDt>
+        <AddtlNtryInf>Storno kosten</AddtlNtryInf>
+      </Ntry>
+      <Ntry>
+        <Amt Ccy="EUR">10.00</Amt>
+        <CdtDbtInd>DBIT</CdtDbtInd>
+        <Sts>PDNG</Sts>
+        <BookgDt><Dt>2024-03-31</Dt></BookgDt>
+      </Ntry>
+    </Stmt>
+  </BkToCstmrStmt>
+</Document>
+`
+
+func TestParseCAMT053(t *testing.T) {
+	statements, err := ParseCAMT053([]byte(sampleCAMT053))
+	if err != nil {
+		t.Fatalf("ParseCAMT053 error: %v", err)
+	}
+	if len(statements) != 1 {
+		t.Fatalf("%d statements, want 1", len(statements))
+	}
+	statement := statements[0]
+	if statement.AccountNumber != "NL91ABNA0417164300" || statement.Currency != "EUR" {
+		t.Errorf("account %q %q, want NL91ABNA0417164300 EUR", statement.AccountNumber, statement.Currency)
+	}
+	if statement.OpeningBalance == nil || statement.OpeningBalance.String() != "1500" {
+		t.Errorf("opening balance %v, want 1500", statement.OpeningBalance)
+	}
+	if statement.ClosingBalance == nil || statement.ClosingBalance.String() != "3410.75" {
+		t.Errorf("closing balance %v, want 3410.75", statement.ClosingBalance)
+	}
+
+	// Entry PDNG dilewati
+	checkRows(t, "CAMT.053", statement.Rows, []wantRow{
+		{line: 1, date: "2024-03-04", amount: "89.25", kind: "expense", description: "Albert Heijn - Boodschappen pas 12"},
+		{line: 2, date: "2024-03-25", amount: "2000", kind: "income", description: "ACME B.V. - Salaris maart"},
+		{line: 3, date: "2024-03-28", amount: "5", kind: "income", description: "Storno kosten"},
+	})
+	refs := []string{"0001", "BANKREF-2503", ""}
+	for i, ref := range refs {
+		if statement.Rows[i].Reference != ref {
+			t.Errorf("row %d: reference %q, want %q", i, statement.Rows[i].Reference, ref)
+		}
+		if statement.Rows[i].Currency != "EUR" {
+			t.Errorf("row %d: currency %q, want EUR", i, statement.Rows[i].Currency)
+		}
+	}
+}
+
+func TestParseCAMT053Invalid(t *testing.T) {
+	tests := []string{
+		"not xml",
+		`<Document><BkToCstmrNtfctn></BkToCstmrNtfctn></Document>`,
+	}
+	for _, data := range tests {
+		if _, err := ParseCAMT053([]byte(data)); err == nil {
+			t.Errorf("ParseCAMT053(%q): want error", data)
+		}
+	}
+}
@@ -0,0 +1,199 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/csv"
+	"finance-tracker-backend/models"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Cari index kolom dari nama header (tidak peka huruf besar/kecil) atau nomor kolom mulai dari 1.
+// Nama kosong berarti kolom tidak dipakai (-1).
+func csvColumn(header []string, name string) (int, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return -1, nil
+	}
+	for i, h := range header {
+		if strings.EqualFold(strings.TrimSpace(h), name) {
+			return i, nil
+		}
+	}
+	if n, err := strconv.Atoi(name); err == nil && n >= 1 {
+		return n - 1, nil
+	}
+	return -1, fmt.Errorf("column %q not found", name)
+}
+
+func csvCell(record []string, index int) string {
+	if index < 0 || index >= len(record) {
+		return ""
+	}
+	return strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(record[index]), "'"))
+}
+
+// ParseStatementCSV membaca CSV statement bank dengan mapping kolom user. Baris yang tidak
+// bisa dibaca tetap dikembalikan dengan Error terisi; error hanya untuk file/mapping yang salah.
+func ParseStatementCSV(data []byte, mapping models.ImportMapping) ([]StatementRow, error) {
+	data = bytes.TrimPrefix(data, []byte("\ufeff"))
+
+	// Baris pembuka dihitung per baris teks (baris kosong ikut dihitung), bukan per record CSV
+	for i := 0; i < mapping.SkipRows && len(data) > 0; i++ {
+		if next := bytes.IndexByte(data, '\n'); next >= 0 {
+			data = data[next+1:]
+		} else {
+			data = nil
+		}
+	}
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	if mapping.Delimiter != "" {
+		reader.Comma = []rune(mapping.Delimiter)[0]
+	}
+
+	var records [][]string
+	var lines []int
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := reader.FieldPos(0)
+		records = append(records, record)
+		lines = append(lines, mapping.SkipRows+line)
+	}
+
+	var header []string
+	if mapping.HasHeader {
+		if len(records) == 0 {
+			return nil, fmt.Errorf("file contains no transactions")
+		}
+		header, records, lines = records[0], records[1:], lines[1:]
+	}
+
+	columns := map[string]int{}
+	for key, name := range map[string]string{
+		"date":        mapping.DateColumn,
+		"amount":      mapping.AmountColumn,
+		"debit":       mapping.DebitColumn,
+		"credit":      mapping.CreditColumn,
+		"indicator":   mapping.IndicatorColumn,
+		"description": mapping.DescriptionColumn,
+		"category":    mapping.CategoryColumn,
+		"payee":       mapping.PayeeColumn,
+		"currency":    mapping.CurrencyColumn,
+	} {
+		index, err := csvColumn(header, name)
+		if err != nil {
+			return nil, err
+		}
+		columns[key] = index
+	}
+
+	layout := DateLayout(mapping.DateFormat)
+	var rows []StatementRow
+	for i, record := range records {
+		// Baris kosong (biasanya di akhir file) dilewati
+		if strings.TrimSpace(strings.Join(record, "")) == "" {
+			continue
+		}
+
+		row := StatementRow{
+			Line:        lines[i],
+			Description: csvCell(record, columns["description"]),
+			Category:    csvCell(record, columns["category"]),
+			Payee:       csvCell(record, columns["payee"]),
+		}
+		if code := csvCell(record, columns["currency"]); code != "" {
+			if normalized, ok := NormalizeCurrency(code); ok {
+				row.Currency = normalized
+			}
+		}
+
+		date, err := ParseStatementDate(csvCell(record, columns["date"]), layout)
+		if err != nil {
+			row.Error = err.Error()
+			rows = append(rows, row)
+			continue
+		}
+		row.Date = date
+
+		if err := csvAmount(&row, record, columns, mapping); err != nil {
+			row.Error = err.Error()
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// Isi Amount & Kind sesuai amount_mode mapping
+func csvAmount(row *StatementRow, record []string, columns map[string]int, mapping models.ImportMapping) error {
+	switch mapping.AmountMode {
+	case "debit_credit":
+		debit := csvCell(record, columns["debit"])
+		credit := csvCell(record, columns["credit"])
+		var amount models.Money
+		var err error
+		if debit != "" && debit != "0" {
+			if amount, err = ParseStatementAmount(debit, mapping.DecimalSeparator); err != nil {
+				return err
+			}
+		}
+		if !amount.IsZero() {
+			row.Amount, row.Kind = absAmount(amount), "expense"
+			return nil
+		}
+		if credit == "" {
+			return fmt.Errorf("missing debit or credit amount")
+		}
+		if amount, err = ParseStatementAmount(credit, mapping.DecimalSeparator); err != nil {
+			return err
+		}
+		row.Amount, row.Kind = absAmount(amount), "income"
+
+	case "indicator":
+		value := csvCell(record, columns["amount"])
+		indicator := csvCell(record, columns["indicator"])
+		if columns["indicator"] == columns["amount"] {
+			// Penanda ikut di kolom amount, misalnya "150,000.00 DB"
+			fields := strings.Fields(value)
+			if len(fields) > 1 {
+				indicator = fields[len(fields)-1]
+				value = strings.Join(fields[:len(fields)-1], " ")
+			} else {
+				indicator = ""
+			}
+		}
+		amount, err := ParseStatementAmount(value, mapping.DecimalSeparator)
+		if err != nil {
+			return err
+		}
+		row.Amount, row.Kind = absAmount(amount), "income"
+		if strings.EqualFold(indicator, mapping.DebitIndicator) {
+			row.Kind = "expense"
+		}
+
+	default:
+		amount, err := ParseStatementAmount(csvCell(record, columns["amount"]), mapping.DecimalSeparator)
+		if err != nil {
+			return err
+		}
+		if mapping.InvertSign {
+			amount = -amount
+		}
+		row.Amount, row.Kind = signedStatementAmount(amount)
+	}
+
+	if row.Amount.IsZero() {
+		return fmt.Errorf("amount is zero")
+	}
+	return nil
+}
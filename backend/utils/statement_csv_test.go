@@ -0,0 +1,158 @@
+package utils
+
+import (
+	"finance-tracker-backend/models"
+	"testing"
+)
+
+// Baris yang diharapkan dari satu sampel statement, dibandingkan dalam bentuk string
+type wantRow struct {
+	line        int
+	date        string
+	amount      string
+	kind        string
+	description string
+	err         bool
+}
+
+func checkRows(t *testing.T, name string, rows []StatementRow, want []wantRow) {
+	t.Helper()
+	if len(rows) != len(want) {
+		t.Fatalf("%s: %d rows, want %d: %+v", name, len(rows), len(want), rows)
+	}
+	for i, w := range want {
+		row := rows[i]
+		if w.err {
+			if row.Error == "" {
+				t.Errorf("%s row %d: want error, got %+v", name, i, row)
+			}
+			continue
+		}
+		if row.Error != "" {
+			t.Errorf("%s row %d: unexpected error %q", name, i, row.Error)
+			continue
+		}
+		if w.line != 0 && row.Line != w.line {
+			t.Errorf("%s row %d: line %d, want %d", name, i, row.Line, w.line)
+		}
+		if got := row.Date.Format("2006-01-02"); got != w.date {
+			t.Errorf("%s row %d: date %s, want %s", name, i, got, w.date)
+		}
+		if got := row.Amount.String(); got != w.amount || row.Kind != w.kind {
+			t.Errorf("%s row %d: %s %s, want %s %s", name, i, row.Kind, got, w.kind, w.amount)
+		}
+		if w.description != "" && row.Description != w.description {
+			t.Errorf("%s row %d: description %q, want %q", name, i, row.Description, w.description)
+		}
+	}
+}
+
+func TestParseStatementCSV(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		mapping models.ImportMapping
+		want    []wantRow
+	}{
+		{
+			name: "KlikBCA, penanda DB/CR di kolom jumlah",
+			data: "\ufeffNo. rekening : 1234567890\n" +
+				"Nama : BUDI SANTOSO\n" +
+				"Periode : 01/03/2024 - 31/03/2024\n" +
+				"Kode Mata Uang : Rp\n" +
+				"\n" +
+				"Tanggal Transaksi,Keterangan,Cabang,Jumlah,Saldo\n" +
+				"'01/03/2024,TRSF E-BANKING DB 0103/FTSCY/WS95051 GOPAY,'0000,\"50,000.00 DB\",\"1,950,000.00\"\n" +
+				"'25/03/2024,TRSF E-BANKING CR 2503/ACMCY/WS95011 GAJI MARET,'0000,\"8,500,000.00 CR\",\"10,450,000.00\"\n" +
+				"'26/03/2024,BIAYA ADM,'0000,\"abc DB\",\"10,450,000.00\"\n",
+			mapping: models.ImportMapping{
+				Delimiter: ",", SkipRows: 5, HasHeader: true, DateColumn: "Tanggal Transaksi", DateFormat: "DD/MM/YYYY",
+				DecimalSeparator: ".", AmountMode: "indicator", AmountColumn: "Jumlah", IndicatorColumn: "Jumlah",
+				DebitIndicator: "DB", DescriptionColumn: "Keterangan",
+			},
+			want: []wantRow{
+				{line: 7, date: "2024-03-01", amount: "50000", kind: "expense", description: "TRSF E-BANKING DB 0103/FTSCY/WS95051 GOPAY"},
+				{line: 8, date: "2024-03-25", amount: "8500000", kind: "income"},
+				{err: true},
+			},
+		},
+		{
+			name: "Mandiri, kolom debit dan kredit terpisah dengan desimal koma",
+			data: "Tanggal;Keterangan;Debit;Kredit;Saldo\n" +
+				"02/04/2024;Pembayaran QRIS INDOMARET;125.500,00;0;4.874.500,00\n" +
+				"03/04/2024;Transfer masuk dari ANI;;1.000.000,00;5.874.500,00\n" +
+				";;;;\n" +
+				"31/04/2024;Tanggal salah;10.000,00;;\n",
+			mapping: models.ImportMapping{
+				Delimiter: ";", HasHeader: true, DateColumn: "Tanggal", DateFormat: "DD/MM/YYYY", DecimalSeparator: ",",
+				AmountMode: "debit_credit", DebitColumn: "Debit", CreditColumn: "Kredit", DescriptionColumn: "Keterangan",
+			},
+			want: []wantRow{
+				{line: 2, date: "2024-04-02", amount: "125500", kind: "expense", description: "Pembayaran QRIS INDOMARET"},
+				{line: 3, date: "2024-04-03", amount: "1000000", kind: "income"},
+				{err: true},
+			},
+		},
+		{
+			name: "kartu kredit, satu kolom amount dengan tanda dibalik, kolom berdasarkan nomor",
+			data: "2024-05-01,Tokopedia,150000.50,IDR\n" +
+				"2024-05-02,Pembayaran tagihan,-1000000,idr\n",
+			mapping: models.ImportMapping{
+				Delimiter: ",", DateColumn: "1", DateFormat: "YYYY-MM-DD", DecimalSeparator: ".",
+				AmountMode: "signed", AmountColumn: "3", InvertSign: true, DescriptionColumn: "2", CurrencyColumn: "4",
+			},
+			want: []wantRow{
+				{line: 1, date: "2024-05-01", amount: "150000.5", kind: "expense", description: "Tokopedia"},
+				{line: 2, date: "2024-05-02", amount: "1000000", kind: "income"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		rows, err := ParseStatementCSV([]byte(tt.data), tt.mapping)
+		if err != nil {
+			t.Errorf("%s: error %v", tt.name, err)
+			continue
+		}
+		checkRows(t, tt.name, rows, tt.want)
+	}
+}
+
+func TestParseStatementCSVMissingColumn(t *testing.T) {
+	mapping := models.ImportMapping{HasHeader: true, DateColumn: "Tanggal", AmountColumn: "Nominal", DateFormat: "DD/MM/YYYY"}
+	if _, err := ParseStatementCSV([]byte("Tanggal,Jumlah\n01/01/2024,100\n"), mapping); err == nil {
+		t.Error("want error for a mapping column that is not in the header")
+	}
+}
+
+func TestParseStatementAmount(t *testing.T) {
+	tests := []struct {
+		value     string
+		separator string
+		want      string
+		wantErr   bool
+	}{
+		{value: "1.500.000,00", separator: ",", want: "1500000"},
+		{value: "(25,000.50)", separator: ".", want: "-25000.5"},
+		{value: "Rp 10.000", separator: ",", want: "10000"},
+		{value: "Rp. 10.000", separator: ",", want: "10000"},
+		{value: "150.000-", separator: ",", want: "-150000"},
+		{value: "1500,", separator: ",", want: "1500"},
+		{value: "'-42.10", separator: ".", want: "-42.1"},
+		{value: "1.2.3", separator: ".", wantErr: true},
+		{value: "abc", separator: ".", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseStatementAmount(tt.value, tt.separator)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseStatementAmount(%q) = %s, want error", tt.value, got)
+			}
+			continue
+		}
+		if err != nil || got.String() != tt.want {
+			t.Errorf("ParseStatementAmount(%q, %q) = %s, %v, want %s", tt.value, tt.separator, got, err, tt.want)
+		}
+	}
+}
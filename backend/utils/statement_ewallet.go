@@ -0,0 +1,366 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/csv"
+	"finance-tracker-backend/models"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Nama kolom (huruf kecil) untuk setiap peran di history e-wallet/marketplace.
+// Layout export berubah-ubah antar versi aplikasi, jadi kolom dicari lewat header, bukan posisi.
+var ewalletCommonColumns = map[string][]string{
+	"date":        {"tanggal", "tanggal transaksi", "waktu", "waktu transaksi", "tanggal & waktu", "date", "transaction date", "transaction time", "created at"},
+	"description": {"deskripsi", "keterangan", "detail", "detail transaksi", "description", "transaction details"},
+	"type":        {"tipe", "tipe transaksi", "jenis", "jenis transaksi", "type", "transaction type", "kategori", "layanan", "service"},
+	"merchant":    {"merchant", "nama merchant", "penerima", "tujuan", "recipient"},
+	"amount":      {"jumlah", "nominal", "amount", "total", "nilai"},
+	"debit":       {"uang keluar", "debit", "pengeluaran", "money out"},
+	"credit":      {"uang masuk", "kredit", "credit", "pemasukan", "money in"},
+	"direction":   {"arus dana", "arus uang", "debit/kredit", "db/cr", "cash flow", "in/out"},
+	"reference":   {"id transaksi", "no. transaksi", "nomor transaksi", "no. referensi", "nomor referensi", "reference", "transaction id", "order id", "ref"},
+	"status":      {"status", "status transaksi", "transaction status"},
+}
+
+// Layout per provider: kolom tambahan di atas ewalletCommonColumns dan default category
+// untuk pengeluaran yang tidak cocok keyword apapun
+type ewalletLayout struct {
+	name     string
+	columns  map[string][]string
+	category string
+}
+
+var ewalletLayouts = map[string]ewalletLayout{
+	"gopay": {
+		name:    "GoPay",
+		columns: map[string][]string{"reference": {"order id gopay", "gopay transaction id"}},
+	},
+	"ovo": {
+		name:    "OVO",
+		columns: map[string][]string{"reference": {"no. ref", "id referensi"}, "merchant": {"nama toko"}},
+	},
+	"dana": {
+		name:    "DANA",
+		columns: map[string][]string{"reference": {"dana id", "id transaksi dana"}},
+	},
+	"shopeepay": {
+		name: "ShopeePay",
+		columns: map[string][]string{
+			"reference": {"no. pesanan", "id transaksi shopeepay"},
+			"amount":    {"jumlah (rp)", "nominal (rp)"},
+		},
+	},
+	"tokopedia": {
+		name: "Tokopedia",
+		columns: map[string][]string{
+			"date":        {"tanggal pembelian", "tanggal pesanan", "tanggal transaksi"},
+			"reference":   {"nomor invoice", "no. invoice", "invoice"},
+			"description": {"nama produk", "produk"},
+			"merchant":    {"nama toko", "toko", "penjual"},
+			"amount":      {"total pembayaran", "total belanja", "total harga"},
+			"status":      {"status pesanan"},
+		},
+		category: "Belanja",
+	},
+}
+
+// EwalletProvider mengembalikan nama tampilan provider e-wallet/marketplace yang didukung
+func EwalletProvider(provider string) (string, bool) {
+	layout, ok := ewalletLayouts[strings.ToLower(provider)]
+	return layout.name, ok
+}
+
+// Status transaksi yang tidak jadi/belum final, tidak di-import
+var ewalletSkippedStatus = []string{"gagal", "batal", "dibatalkan", "failed", "cancel", "expired", "kedaluwarsa", "pending", "menunggu", "diproses", "refunded"}
+
+// Keyword untuk top up (uang masuk dari rekening lain) dan tarik saldo (uang keluar ke rekening lain)
+var (
+	ewalletTopUpKeywords    = []string{"top up", "topup", "top-up", "isi saldo", "isi ulang saldo"}
+	ewalletWithdrawKeywords = []string{"tarik saldo", "tarik tunai", "penarikan", "withdraw", "transfer ke bank", "kirim ke bank", "pencairan"}
+	// "Top up pulsa/e-money" adalah pembelian, bukan isi saldo
+	ewalletPurchaseKeywords = []string{"pulsa", "paket data", "token", "listrik", "pln", "game", "voucher", "e-money", "emoney", "flazz", "brizzi"}
+	ewalletIncomeKeywords   = []string{"cashback", "refund", "pengembalian", "terima", "diterima", "received", "uang masuk", "transfer masuk"}
+	ewalletOutgoingWords    = []string{"debit", "db", "dr", "keluar", "out", "pengeluaran", "-"}
+	ewalletIncomingWords    = []string{"kredit", "credit", "cr", "masuk", "in", "pemasukan", "+"}
+)
+
+// Default category dari keyword (nama category seed: Makanan, Transport, Belanja). Urutan penting:
+// "shopeefood" harus cocok Makanan sebelum "shopee" cocok Belanja.
+var categoryKeywords = []struct {
+	category string
+	keywords []string
+}{
+	{"Makanan", []string{"gofood", "go-food", "go food", "shopeefood", "shopee food", "grabfood", "makan", "resto", "food", "kopi", "coffee", "cafe", "bakery", "warung"}},
+	{"Transport", []string{"goride", "go-ride", "go ride", "gocar", "go-car", "go car", "grab", "ojek", "taksi", "taxi", "bluebird", "parkir", "bayar tol", "jalan tol", "e-toll", "krl", "mrt", "lrt", "transjakarta", "kereta", "bensin", "pertamina", "spbu"}},
+	{"Belanja", []string{"gomart", "go-mart", "shopee", "tokopedia", "alfamart", "indomaret", "supermarket", "minimarket", "belanja", "pembelian", "pesanan", "order"}},
+}
+
+// KeywordCategory menebak nama category pengeluaran dari keterangan transaksi (GoFood -> Makanan,
+// GoRide -> Transport, Tokopedia -> Belanja), kosong kalau tidak ada yang cocok
+func KeywordCategory(text string) string {
+	text = strings.ToLower(text)
+	for _, rule := range categoryKeywords {
+		if containsAny(text, rule.keywords) {
+			return rule.category
+		}
+	}
+	return ""
+}
+
+func containsAny(text string, keywords []string) bool {
+	for _, keyword := range keywords {
+		if strings.Contains(text, keyword) {
+			return true
+		}
+	}
+	return false
+}
+
+// Cocok persis dengan salah satu kata (untuk kolom arah seperti "DB"/"CR" atau "Masuk")
+func matchesWord(value string, words []string) bool {
+	for _, word := range words {
+		if value == word {
+			return true
+		}
+	}
+	return false
+}
+
+// Baca file export (CSV atau XLSX) jadi baris-baris teks. Nomor baris dimulai dari 1.
+func ewalletRecords(data []byte) ([][]string, error) {
+	if bytes.HasPrefix(data, []byte("PK\x03\x04")) {
+		return ReadXLSX(data)
+	}
+
+	data = bytes.TrimPrefix(data, []byte("\ufeff"))
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	reader.Comma = csvDelimiter(data)
+
+	var records [][]string
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// Tebak delimiter CSV dari beberapa baris pertama (",", ";" atau tab)
+func csvDelimiter(data []byte) rune {
+	sample := data
+	for i, lines := 0, 0; i < len(data); i++ {
+		if data[i] == '\n' {
+			if lines++; lines == 5 {
+				sample = data[:i]
+				break
+			}
+		}
+	}
+	delimiter, best := ',', bytes.Count(sample, []byte(","))
+	for _, candidate := range []rune{';', '\t'} {
+		if count := bytes.Count(sample, []byte(string(candidate))); count > best {
+			delimiter, best = candidate, count
+		}
+	}
+	return delimiter
+}
+
+func normalizeHeader(value string) string {
+	value = strings.ToLower(strings.Join(strings.Fields(value), " "))
+	return strings.TrimSpace(strings.TrimSuffix(strings.TrimSuffix(value, "(idr)"), "(rp)"))
+}
+
+// Cari baris header (beberapa export diawali info akun/periode) dan index kolom tiap peran.
+// Alias khusus provider dicoba lebih dulu.
+func ewalletHeader(records [][]string, layout ewalletLayout) (int, map[string]int, error) {
+	for i := 0; i < len(records) && i < 20; i++ {
+		index := make(map[string]int, len(records[i]))
+		for column, value := range records[i] {
+			if name := normalizeHeader(value); name != "" {
+				if _, ok := index[name]; !ok {
+					index[name] = column
+				}
+			}
+		}
+
+		columns := make(map[string]int, len(ewalletCommonColumns))
+		for role, common := range ewalletCommonColumns {
+			columns[role] = -1
+			for _, alias := range append(append([]string{}, layout.columns[role]...), common...) {
+				if column, ok := index[alias]; ok {
+					columns[role] = column
+					break
+				}
+			}
+		}
+		if columns["date"] >= 0 && (columns["amount"] >= 0 || columns["debit"] >= 0 || columns["credit"] >= 0) {
+			return i, columns, nil
+		}
+	}
+	return 0, nil, fmt.Errorf("%s header row not found (expected date and amount columns)", layout.name)
+}
+
+// ParseEwallet membaca history transaksi GoPay, OVO, DANA, ShopeePay atau Tokopedia (CSV/XLSX).
+// Top up jadi baris transfer masuk, tarik saldo transfer keluar; pengeluaran lain diberi
+// category default dari keyword. Baris dengan nomor referensi yang sama (satu invoice dengan
+// beberapa produk) digabung jadi satu transaksi.
+func ParseEwallet(provider string, data []byte) ([]StatementRow, error) {
+	layout, ok := ewalletLayouts[strings.ToLower(provider)]
+	if !ok {
+		return nil, fmt.Errorf("unknown provider %q", provider)
+	}
+
+	records, err := ewalletRecords(data)
+	if err != nil {
+		return nil, err
+	}
+	headerIndex, columns, err := ewalletHeader(records, layout)
+	if err != nil {
+		return nil, err
+	}
+
+	// Kalau kolom amount tidak pernah negatif dan tidak ada kolom arah, amount positif
+	// berarti pengeluaran kecuali keterangannya jelas uang masuk
+	signed := false
+	for _, record := range records[headerIndex+1:] {
+		if strings.Contains(csvCell(record, columns["amount"]), "-") {
+			signed = true
+			break
+		}
+	}
+
+	var rows []StatementRow
+	byReference := make(map[string]int)
+	for i, record := range records[headerIndex+1:] {
+		if strings.TrimSpace(strings.Join(record, "")) == "" {
+			continue
+		}
+		row := ewalletRow(record, columns, layout, signed)
+		row.Line = headerIndex + i + 2
+
+		// Satu invoice/pesanan bisa ditulis per produk: gabungkan ke baris pertama
+		if row.Error == "" && row.Reference != "" {
+			if first, ok := byReference[row.Reference]; ok {
+				existing := &rows[first]
+				if existing.Kind == row.Kind && existing.Transfer == row.Transfer {
+					existing.Amount += row.Amount
+					// Nama toko cukup sekali di depan, produk berikutnya ditambahkan di belakang
+					item := strings.TrimPrefix(row.Description, row.Payee+" - ")
+					if item != "" && !strings.Contains(existing.Description, item) {
+						existing.Description += ", " + item
+					}
+					continue
+				}
+				row.Reference += "|" + row.Kind
+			}
+			byReference[row.Reference] = len(rows)
+		}
+		rows = append(rows, row)
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("file contains no transactions")
+	}
+	return rows, nil
+}
+
+func ewalletRow(record []string, columns map[string]int, layout ewalletLayout, signed bool) StatementRow {
+	transactionType := csvCell(record, columns["type"])
+	description := csvCell(record, columns["description"])
+	merchant := csvCell(record, columns["merchant"])
+
+	row := StatementRow{
+		Payee:     merchant,
+		Reference: csvCell(record, columns["reference"]),
+		Currency:  "IDR",
+	}
+	switch {
+	case description == "":
+		description = transactionType
+	case transactionType != "" && !strings.Contains(strings.ToLower(description), strings.ToLower(transactionType)):
+		description = transactionType + " - " + description
+	}
+	if merchant != "" && !strings.Contains(strings.ToLower(description), strings.ToLower(merchant)) {
+		description = strings.Trim(merchant+" - "+description, " -")
+	}
+	row.Description = description
+	text := strings.ToLower(transactionType + " " + description + " " + merchant)
+
+	if status := strings.ToLower(csvCell(record, columns["status"])); status != "" && containsAny(status, ewalletSkippedStatus) {
+		row.Error = "transaction status is " + csvCell(record, columns["status"])
+		return row
+	}
+
+	date, err := ParseLooseDate(csvCell(record, columns["date"]))
+	if err != nil {
+		row.Error = err.Error()
+		return row
+	}
+	row.Date = date
+
+	// Arah uang: kolom uang keluar/masuk, kolom arah, tanda amount, lalu keyword
+	var amount models.Money
+	direction := ""
+	debit, credit := csvCell(record, columns["debit"]), csvCell(record, columns["credit"])
+	if debit != "" || credit != "" {
+		if debitAmount, err := ParseIndonesianAmount(debit); err == nil && !debitAmount.IsZero() {
+			amount, direction = absAmount(debitAmount), "expense"
+		} else if creditAmount, err := ParseIndonesianAmount(credit); err == nil {
+			amount, direction = absAmount(creditAmount), "income"
+		} else {
+			row.Error = err.Error()
+			return row
+		}
+	} else {
+		value, err := ParseIndonesianAmount(csvCell(record, columns["amount"]))
+		if err != nil {
+			row.Error = err.Error()
+			return row
+		}
+		amount = absAmount(value)
+		flow := strings.ToLower(csvCell(record, columns["direction"]))
+		switch {
+		case matchesWord(flow, ewalletOutgoingWords) || strings.Contains(flow, "keluar"):
+			direction = "expense"
+		case matchesWord(flow, ewalletIncomingWords) || strings.Contains(flow, "masuk"):
+			direction = "income"
+		case value < 0:
+			direction = "expense"
+		case signed:
+			direction = "income"
+		}
+	}
+	if amount.IsZero() {
+		row.Error = "amount is zero"
+		return row
+	}
+	row.Amount = amount
+
+	switch {
+	case direction != "expense" && containsAny(text, ewalletTopUpKeywords) && !containsAny(text, ewalletPurchaseKeywords):
+		row.Kind, row.Transfer = "income", true
+	case direction != "income" && containsAny(text, ewalletWithdrawKeywords):
+		row.Kind, row.Transfer = "expense", true
+	case direction != "":
+		row.Kind = direction
+	case containsAny(text, ewalletIncomeKeywords):
+		row.Kind = "income"
+	default:
+		row.Kind = "expense"
+	}
+
+	if row.Kind == "expense" && !row.Transfer {
+		if row.Category = KeywordCategory(text); row.Category == "" {
+			row.Category = layout.category
+		}
+	}
+	return row
+}
@@ -0,0 +1,136 @@
+package utils
+
+import "testing"
+
+// Export riwayat GoPay: amount selalu positif, arah dari kolom "Arus Dana". Baris kosong
+// dilewati reader CSV, jadi Line adalah nomor record.
+const sampleGoPay = "Riwayat Transaksi GoPay\n" +
+	"Periode: 1 Mei 2024 - 31 Mei 2024\n" +
+	"\n" +
+	"Tanggal,Tipe Transaksi,Deskripsi,Arus Dana,Nominal,Status,Order ID GoPay\n" +
+	"\"2 Mei 2024, 12:15 WIB\",GoFood,Ayam Geprek Bensu,Keluar,\"Rp35.000\",Berhasil,GF-240502-001\n" +
+	"\"3 Mei 2024, 08:01 WIB\",Top Up,Top Up dari BCA Virtual Account,Masuk,\"Rp500.000\",Berhasil,TU-240503-001\n" +
+	"\"4 Mei 2024, 18:40 WIB\",GoRide,Perjalanan ke Stasiun Sudirman,Keluar,\"Rp18.500\",Berhasil,GR-240504-001\n" +
+	"\"5 Mei 2024, 10:00 WIB\",Tarik Saldo,Tarik Saldo ke BCA,Keluar,\"Rp200.000\",Berhasil,WD-240505-001\n" +
+	"\"6 Mei 2024, 10:00 WIB\",Cashback,Cashback GoFood,Masuk,\"Rp5.000\",Berhasil,CB-240506-001\n" +
+	"\"7 Mei 2024, 11:00 WIB\",GoPay Later,Pembayaran Pulsa,Keluar,\"Rp50.000\",Gagal,PL-240507-001\n"
+
+// Export pesanan Tokopedia (separator titik koma): satu invoice ditulis per produk
+const sampleTokopedia = "Nomor Invoice;Tanggal Pembelian;Nama Toko;Nama Produk;Total Pembayaran;Status Pesanan\n" +
+	"INV/20240510/MPL/001;10/05/2024;Toko Elektronik Jaya;Kabel USB-C;45.000;Selesai\n" +
+	"INV/20240510/MPL/001;10/05/2024;Toko Elektronik Jaya;Charger 20W;155.000;Selesai\n" +
+	"INV/20240512/MPL/002;12/05/2024;Kopi Kenangan Official;Kopi Susu 1L;89.000;Selesai\n" +
+	"INV/20240513/MPL/003;13/05/2024;Toko Buku;Novel;120.000;Dibatalkan\n"
+
+func TestParseEwallet(t *testing.T) {
+	tests := []struct {
+		provider   string
+		data       string
+		want       []wantRow
+		transfers  []bool
+		categories []string
+		references []string
+	}{
+		{
+			provider: "gopay",
+			data:     sampleGoPay,
+			want: []wantRow{
+				{line: 4, date: "2024-05-02", amount: "35000", kind: "expense", description: "GoFood - Ayam Geprek Bensu"},
+				{line: 5, date: "2024-05-03", amount: "500000", kind: "income", description: "Top Up dari BCA Virtual Account"},
+				{line: 6, date: "2024-05-04", amount: "18500", kind: "expense"},
+				{line: 7, date: "2024-05-05", amount: "200000", kind: "expense", description: "Tarik Saldo ke BCA"},
+				{line: 8, date: "2024-05-06", amount: "5000", kind: "income", description: "Cashback GoFood"},
+				{err: true},
+			},
+			transfers:  []bool{false, true, false, true, false},
+			categories: []string{"Makanan", "", "Transport", "", ""},
+			references: []string{"GF-240502-001", "TU-240503-001", "GR-240504-001", "WD-240505-001", "CB-240506-001"},
+		},
+		{
+			provider: "tokopedia",
+			data:     sampleTokopedia,
+			want: []wantRow{
+				{line: 2, date: "2024-05-10", amount: "200000", kind: "expense", description: "Toko Elektronik Jaya - Kabel USB-C, Charger 20W"},
+				{line: 4, date: "2024-05-12", amount: "89000", kind: "expense", description: "Kopi Kenangan Official - Kopi Susu 1L"},
+				{err: true},
+			},
+			transfers:  []bool{false, false},
+			categories: []string{"Belanja", "Makanan"},
+			references: []string{"INV/20240510/MPL/001", "INV/20240512/MPL/002"},
+		},
+	}
+
+	for _, tt := range tests {
+		rows, err := ParseEwallet(tt.provider, []byte(tt.data))
+		if err != nil {
+			t.Errorf("%s: error %v", tt.provider, err)
+			continue
+		}
+		checkRows(t, tt.provider, rows, tt.want)
+		for i := range tt.transfers {
+			row := rows[i]
+			if row.Transfer != tt.transfers[i] || row.Category != tt.categories[i] || row.Reference != tt.references[i] {
+				t.Errorf("%s row %d: transfer %v category %q reference %q, want %v %q %q", tt.provider, i,
+					row.Transfer, row.Category, row.Reference, tt.transfers[i], tt.categories[i], tt.references[i])
+			}
+			if row.Currency != "IDR" {
+				t.Errorf("%s row %d: currency %q, want IDR", tt.provider, i, row.Currency)
+			}
+		}
+	}
+}
+
+func TestParseEwalletInvalid(t *testing.T) {
+	if _, err := ParseEwallet("linkaja", []byte(sampleGoPay)); err == nil {
+		t.Error("want error for an unknown provider")
+	}
+	if _, err := ParseEwallet("ovo", []byte("Nama,Saldo\nBudi,100\n")); err == nil {
+		t.Error("want error for a file without date and amount columns")
+	}
+}
+
+func TestParseIndonesianAmount(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{value: "Rp150.000", want: "150000"},
+		{value: "-Rp 15,000", want: "-15000"},
+		{value: "15000.00", want: "15000"},
+		{value: "Rp. 1.250.000,50", want: "1250000.5"},
+		{value: "IDR 1,250,000.50", want: "1250000.5"},
+		{value: "15000,5", want: "15000.5"},
+		{value: "Rp", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseIndonesianAmount(tt.value)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseIndonesianAmount(%q) = %s, want error", tt.value, got)
+			}
+			continue
+		}
+		if err != nil || got.String() != tt.want {
+			t.Errorf("ParseIndonesianAmount(%q) = %s, %v, want %s", tt.value, got, err, tt.want)
+		}
+	}
+}
+
+func TestKeywordCategory(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"ShopeeFood - Nasi Padang", "Makanan"},
+		{"Shopee - Kaos polos", "Belanja"},
+		{"GoCar ke Bandara", "Transport"},
+		{"Bayar tol Jagorawi", "Transport"},
+		{"Transfer ke teman", ""},
+	}
+	for _, tt := range tests {
+		if got := KeywordCategory(tt.text); got != tt.want {
+			t.Errorf("KeywordCategory(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,238 @@
+package utils
+
+import (
+	"bufio"
+	"bytes"
+	"finance-tracker-backend/models"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// :61: YYMMDD [MMDD] D/C/RD/RC [funds code] amount type+code customer-ref [//bank-ref]
+var mt940EntryPattern = regexp.MustCompile(`^(\d{6})(\d{4})?(RC|RD|C|D)([A-Z])?(\d+,\d*)([NSF][A-Z0-9]{3})([^/]*)(?://(.*))?$`)
+
+// :60F:/:62F: C/D YYMMDD currency amount
+var mt940BalancePattern = regexp.MustCompile(`^([CD])(\d{6})([A-Z]{3})(\d+,\d*)$`)
+
+// Tag SWIFT terstruktur di :86: seperti /NAME/.../REMI/... (hanya tag 4 huruf yang dikenal)
+var mt940InfoTagPattern = regexp.MustCompile(`/(NAME|REMI|ORDP|BENM|CDTR|DBTR|EREF|IREF|PURP|ADDR)/`)
+
+func parseMT940Date(value string) (time.Time, error) {
+	date, err := time.Parse("060102", value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q", value)
+	}
+	return date, nil
+}
+
+// Saldo :60F:/:62F: menjadi amount bertanda (D = negatif)
+func parseMT940Balance(value string) (models.Money, time.Time, string, error) {
+	match := mt940BalancePattern.FindStringSubmatch(strings.TrimSpace(value))
+	if match == nil {
+		return 0, time.Time{}, "", fmt.Errorf("invalid balance %q", value)
+	}
+	date, err := parseMT940Date(match[2])
+	if err != nil {
+		return 0, time.Time{}, "", err
+	}
+	amount, err := ParseStatementAmount(match[4], ",")
+	if err != nil {
+		return 0, time.Time{}, "", err
+	}
+	if match[1] == "D" {
+		amount = -amount
+	}
+	return amount, date, match[3], nil
+}
+
+// Nama lawan transaksi dan keterangan dari field :86:. Mendukung format subfield ?NN
+// (?20-?29 keterangan, ?32-?33 nama), tag /NAME/ /REMI/, atau teks bebas.
+func parseMT940Info(info string) (name, purpose string) {
+	info = strings.TrimSpace(info)
+	if len(info) > 4 && info[3] == '?' {
+		fields := map[string]string{}
+		for _, part := range strings.Split(info[4:], "?") {
+			if len(part) >= 2 {
+				fields[part[:2]] += part[2:]
+			}
+		}
+		name = strings.TrimSpace(fields["32"] + fields["33"])
+		var purposes []string
+		for i := 20; i <= 29; i++ {
+			if value := strings.TrimSpace(fields[fmt.Sprint(i)]); value != "" {
+				purposes = append(purposes, value)
+			}
+		}
+		if len(purposes) == 0 {
+			purposes = append(purposes, strings.TrimSpace(fields["00"]))
+		}
+		return name, strings.Join(purposes, " ")
+	}
+
+	if locations := mt940InfoTagPattern.FindAllStringSubmatchIndex(info, -1); len(locations) > 0 {
+		for i, location := range locations {
+			end := len(info)
+			if i+1 < len(locations) {
+				end = locations[i+1][0]
+			}
+			value := strings.Trim(strings.TrimSpace(info[location[1]:end]), "/")
+			switch info[location[2]:location[3]] {
+			case "NAME":
+				if name == "" {
+					name = value
+				}
+			case "REMI":
+				purpose = value
+			}
+		}
+		if name != "" || purpose != "" {
+			return name, purpose
+		}
+	}
+	return "", info
+}
+
+type mt940Field struct {
+	tag   string
+	value string
+	line  int
+}
+
+// Pecah isi MT940 jadi field :TAG:value (baris lanjutan digabung ke field sebelumnya)
+func mt940Fields(data []byte) ([]mt940Field, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	var fields []mt940Field
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimRight(scanner.Text(), "\r")
+		trimmed := strings.TrimSpace(line)
+		// Header/trailer block SWIFT ({1:...}{2:...}{4:, -}) dan baris kosong
+		if trimmed == "" || strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "-") {
+			if i := strings.Index(trimmed, "{4:"); i >= 0 && strings.HasPrefix(strings.TrimSpace(trimmed[i+3:]), ":") {
+				line = strings.TrimSpace(trimmed[i+3:])
+			} else {
+				continue
+			}
+		}
+		if strings.HasPrefix(line, ":") {
+			if end := strings.Index(line[1:], ":"); end > 0 {
+				fields = append(fields, mt940Field{tag: line[1 : end+1], value: line[end+2:], line: lineNumber})
+				continue
+			}
+		}
+		if len(fields) > 0 {
+			fields[len(fields)-1].value += "\n" + line
+		}
+	}
+	return fields, scanner.Err()
+}
+
+// ParseMT940 membaca statement SWIFT MT940 (bisa beberapa statement dalam satu file).
+// :60F:/:60M: jadi saldo awal, :62F:/:62M: saldo akhir, setiap :61: (+ :86:) satu transaksi.
+func ParseMT940(data []byte) ([]Statement, error) {
+	fields, err := mt940Fields(bytes.TrimPrefix(data, []byte("\ufeff")))
+	if err != nil {
+		return nil, err
+	}
+
+	var statements []Statement
+	var current *Statement
+	var lastRow *StatementRow
+	for _, field := range fields {
+		switch field.tag {
+		case "20":
+			statements = append(statements, Statement{})
+			current, lastRow = &statements[len(statements)-1], nil
+		case "25":
+			if current != nil {
+				current.AccountNumber = strings.TrimSpace(field.value)
+			}
+		case "60F", "60M":
+			if current == nil {
+				continue
+			}
+			if amount, date, currency, err := parseMT940Balance(field.value); err == nil {
+				current.OpeningBalance, current.OpeningDate = &amount, &date
+				current.Currency, _ = NormalizeCurrency(currency)
+			}
+		case "62F", "62M":
+			if current == nil {
+				continue
+			}
+			if amount, date, _, err := parseMT940Balance(field.value); err == nil {
+				current.ClosingBalance, current.ClosingDate = &amount, &date
+			}
+		case "61":
+			if current == nil {
+				continue
+			}
+			current.Rows = append(current.Rows, mt940Row(field, current.Currency))
+			lastRow = &current.Rows[len(current.Rows)-1]
+		case "86":
+			if lastRow == nil {
+				continue
+			}
+			name, purpose := parseMT940Info(strings.Join(strings.Fields(field.value), " "))
+			lastRow.Payee = name
+			lastRow.Description = strings.Trim(strings.Join([]string{name, purpose}, " - "), " -")
+			lastRow = nil
+		}
+	}
+
+	if len(statements) == 0 {
+		return nil, fmt.Errorf("file contains no MT940 statement")
+	}
+	return statements, nil
+}
+
+func mt940Row(field mt940Field, currency string) StatementRow {
+	row := StatementRow{Line: field.line, Currency: currency}
+
+	lines := strings.SplitN(field.value, "\n", 2)
+	match := mt940EntryPattern.FindStringSubmatch(strings.TrimSpace(lines[0]))
+	if match == nil {
+		row.Error = fmt.Sprintf("invalid transaction line %q", strings.TrimSpace(lines[0]))
+		return row
+	}
+	if len(lines) > 1 {
+		// Keterangan tambahan di baris kedua :61:, dipakai kalau tidak ada :86:
+		row.Description = strings.TrimSpace(lines[1])
+	}
+
+	date, err := parseMT940Date(match[1])
+	if err != nil {
+		row.Error = err.Error()
+		return row
+	}
+	row.Date = date
+
+	amount, err := ParseStatementAmount(match[5], ",")
+	if err != nil {
+		row.Error = err.Error()
+		return row
+	}
+	// C = kredit (masuk), D = debit; RC/RD adalah pembatalan sehingga arahnya terbalik
+	row.Amount = amount
+	switch match[3] {
+	case "C", "RD":
+		row.Kind = "income"
+	default:
+		row.Kind = "expense"
+	}
+	if row.Amount.IsZero() {
+		row.Error = "amount is zero"
+	}
+
+	// Referensi bank jadi ID untuk re-import; NONREF tidak unik sehingga tidak dipakai
+	customerRef := strings.TrimSpace(match[7])
+	bankRef := strings.TrimSpace(match[8])
+	if bankRef != "" || (customerRef != "" && customerRef != "NONREF") {
+		row.Reference = strings.Join([]string{match[1], match[3], match[5], customerRef, bankRef}, "|")
+	}
+	return row
+}
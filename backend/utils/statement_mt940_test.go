@@ -0,0 +1,80 @@
+package utils
+
+import "testing"
+
+// MT940 dengan header SWIFT, :86: format subfield ?NN, tag /NAME/ dan teks bebas
+const sampleMT940 = `{1:F01BMRIIDJAXXXX0000000000}{2:O9400000000000BMRIIDJAXXXX00000000000000000000N}{4:
+:20:STMT240331
+:25:1230012345678
+:28C:00031/001
+:60F:C240301IDR5000000,00
+:61:2403020302D125500,00NTRFNONREF//FT24062ABCDE
+:86:020?00QRIS PAYMENT?20INDOMARET JKT?21STRUK 8812?32INDOMARET
+:61:2403250325C8500000,NTRFPAYROLL0324//FT24085XYZ12
+:86:/NAME/PT MAJU JAYA/REMI/GAJI MARET 2024
+:61:2403280328RD10000,00NCHGNONREF
+:86:KOREKSI BIAYA ADMIN
+:61:240399D1,00NTRFNONREF
+:62F:C240331IDR13384500,00
+-}
+`
+
+func TestParseMT940(t *testing.T) {
+	statements, err := ParseMT940([]byte(sampleMT940))
+	if err != nil {
+		t.Fatalf("ParseMT940 error: %v", err)
+	}
+	if len(statements) != 1 {
+		t.Fatalf("%d statements, want 1", len(statements))
+	}
+	statement := statements[0]
+	if statement.AccountNumber != "1230012345678" || statement.Currency != "IDR" {
+		t.Errorf("account %q %q, want 1230012345678 IDR", statement.AccountNumber, statement.Currency)
+	}
+	if statement.OpeningBalance == nil || statement.OpeningBalance.String() != "5000000" {
+		t.Errorf("opening balance %v, want 5000000", statement.OpeningBalance)
+	}
+	if statement.ClosingBalance == nil || statement.ClosingBalance.String() != "13384500" ||
+		statement.ClosingDate == nil || statement.ClosingDate.Format("2006-01-02") != "2024-03-31" {
+		t.Errorf("closing balance %v on %v, want 13384500 on 2024-03-31", statement.ClosingBalance, statement.ClosingDate)
+	}
+
+	checkRows(t, "MT940", statement.Rows, []wantRow{
+		{line: 6, date: "2024-03-02", amount: "125500", kind: "expense", description: "INDOMARET - INDOMARET JKT STRUK 8812"},
+		{line: 8, date: "2024-03-25", amount: "8500000", kind: "income", description: "PT MAJU JAYA - GAJI MARET 2024"},
+		{line: 10, date: "2024-03-28", amount: "10000", kind: "income", description: "KOREKSI BIAYA ADMIN"},
+		{err: true},
+	})
+
+	// NONREF tanpa referensi bank tidak dipakai sebagai ID re-import
+	refs := []string{"240302|D|125500,00|NONREF|FT24062ABCDE", "240325|C|8500000,|PAYROLL0324|FT24085XYZ12", ""}
+	for i, ref := range refs {
+		if statement.Rows[i].Reference != ref {
+			t.Errorf("row %d: reference %q, want %q", i, statement.Rows[i].Reference, ref)
+		}
+	}
+}
+
+func TestParseMT940Invalid(t *testing.T) {
+	if _, err := ParseMT940([]byte("Tanggal,Jumlah\n01/01/2024,100\n")); err == nil {
+		t.Error("want error for a file without :20:")
+	}
+}
+
+func TestParseMT940Info(t *testing.T) {
+	tests := []struct {
+		info    string
+		name    string
+		purpose string
+	}{
+		{info: "166?00SEPA-UEBERWEISUNG?20SVWZ+RECHNUNG 42?21VOM 01.03.?32MAX MUSTER", name: "MAX MUSTER", purpose: "SVWZ+RECHNUNG 42 VOM 01.03."},
+		{info: "/ORDP/ANI/REMI/ARISAN/NAME/ANI LESTARI", name: "ANI LESTARI", purpose: "ARISAN"},
+		{info: "SETORAN TUNAI", purpose: "SETORAN TUNAI"},
+	}
+	for _, tt := range tests {
+		name, purpose := parseMT940Info(tt.info)
+		if name != tt.name || purpose != tt.purpose {
+			t.Errorf("parseMT940Info(%q) = %q, %q, want %q, %q", tt.info, name, purpose, tt.name, tt.purpose)
+		}
+	}
+}
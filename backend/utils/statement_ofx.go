@@ -0,0 +1,244 @@
+package utils
+
+import (
+	"fmt"
+	"html"
+	"strings"
+	"time"
+)
+
+// Token OFX: tag pembuka, tag penutup, atau teks di antaranya
+type ofxToken struct {
+	tag  string
+	end  bool
+	text string
+}
+
+// Pecah OFX jadi token. OFX 1.x (SGML) tidak menutup elemen daun (<TRNAMT>-10.00 langsung
+// disusul tag lain), OFX 2.x (XML) menutupnya; keduanya ditangani dengan cara yang sama.
+func ofxTokens(data string) []ofxToken {
+	var tokens []ofxToken
+	for i := 0; i < len(data); {
+		start := strings.IndexByte(data[i:], '<')
+		if start < 0 {
+			if text := strings.TrimSpace(data[i:]); text != "" {
+				tokens = append(tokens, ofxToken{text: text})
+			}
+			break
+		}
+		if text := strings.TrimSpace(data[i : i+start]); text != "" {
+			tokens = append(tokens, ofxToken{text: text})
+		}
+		i += start
+		end := strings.IndexByte(data[i:], '>')
+		if end < 0 {
+			break
+		}
+		tag := strings.TrimSpace(data[i+1 : i+end])
+		i += end + 1
+		// Header XML (<?xml ?>, <?OFX ?>) dan komentar dilewati
+		if tag == "" || tag[0] == '?' || tag[0] == '!' {
+			continue
+		}
+		if tag[0] == '/' {
+			tokens = append(tokens, ofxToken{tag: strings.ToUpper(strings.TrimSpace(tag[1:])), end: true})
+			continue
+		}
+		if fields := strings.Fields(tag); len(fields) > 0 {
+			tag = fields[0]
+		}
+		tokens = append(tokens, ofxToken{tag: strings.ToUpper(strings.TrimSuffix(tag, "/"))})
+	}
+	return tokens
+}
+
+// Elemen OFX: daun punya Value, aggregate punya Children
+type ofxElement struct {
+	Name     string
+	Value    string
+	Children []*ofxElement
+}
+
+// Anak pertama dengan nama tersebut, path dipisah "/" (misalnya "LEDGERBAL/BALAMT")
+func (e *ofxElement) find(path string) *ofxElement {
+	current := e
+	for _, name := range strings.Split(path, "/") {
+		var next *ofxElement
+		for _, child := range current.Children {
+			if child.Name == name {
+				next = child
+				break
+			}
+		}
+		if next == nil {
+			return nil
+		}
+		current = next
+	}
+	return current
+}
+
+func (e *ofxElement) value(path string) string {
+	if found := e.find(path); found != nil {
+		return found.Value
+	}
+	return ""
+}
+
+// Semua elemen dengan nama tersebut di seluruh tree
+func (e *ofxElement) all(name string) []*ofxElement {
+	var found []*ofxElement
+	for _, child := range e.Children {
+		if child.Name == name {
+			found = append(found, child)
+		}
+		found = append(found, child.all(name)...)
+	}
+	return found
+}
+
+// Susun tree dari token. Tag yang langsung diikuti teks adalah elemen daun
+// (tag penutupnya optional); tag lain adalah aggregate yang ditutup dengan </TAG>.
+// Tag kosong yang tidak pernah ditutup (SGML <MEMO> tanpa isi) dianggap daun kosong.
+func parseOFXTree(tokens []ofxToken) *ofxElement {
+	closed := make(map[string]bool)
+	for _, token := range tokens {
+		if token.end {
+			closed[token.tag] = true
+		}
+	}
+
+	root := &ofxElement{Name: "ROOT"}
+	stack := []*ofxElement{root}
+	for i := 0; i < len(tokens); i++ {
+		token := tokens[i]
+		parent := stack[len(stack)-1]
+		switch {
+		case token.end:
+			// Tutup aggregate yang cocok; penutup elemen daun (XML) tidak ada di stack dan diabaikan
+			for j := len(stack) - 1; j > 0; j-- {
+				if stack[j].Name == token.tag {
+					stack = stack[:j]
+					break
+				}
+			}
+		case token.tag == "":
+			// Teks tanpa tag (header SGML "OFXHEADER:100" dsb) diabaikan
+		case i+1 < len(tokens) && tokens[i+1].tag == "" && !tokens[i+1].end:
+			parent.Children = append(parent.Children, &ofxElement{Name: token.tag, Value: html.UnescapeString(tokens[i+1].text)})
+			i++
+		case !closed[token.tag]:
+			parent.Children = append(parent.Children, &ofxElement{Name: token.tag})
+		default:
+			element := &ofxElement{Name: token.tag}
+			parent.Children = append(parent.Children, element)
+			stack = append(stack, element)
+		}
+	}
+	return root
+}
+
+// ParseOFXDate membaca tanggal OFX: YYYYMMDD, opsional diikuti HHMMSS[.XXX][offset:TZ].
+// Hanya tanggalnya yang dipakai.
+func ParseOFXDate(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if len(value) < 8 {
+		return time.Time{}, fmt.Errorf("invalid date %q", value)
+	}
+	date, err := time.Parse("20060102", value[:8])
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q", value)
+	}
+	return date, nil
+}
+
+// ParseOFX membaca file OFX 1.x (SGML) atau 2.x (XML), termasuk QFX, dan mengembalikan
+// semua statement bank/kartu kredit di dalamnya (saldo akhir dari LEDGERBAL). STMTTRN
+// dipetakan ke StatementRow dengan FITID sebagai Reference; TRNAMT negatif berarti pengeluaran.
+func ParseOFX(data []byte) ([]Statement, error) {
+	text := string(data)
+	if !strings.Contains(strings.ToUpper(text), "<OFX") {
+		return nil, fmt.Errorf("not an OFX file")
+	}
+
+	root := parseOFXTree(ofxTokens(text))
+	var statements []Statement
+	aggregates := append(root.all("STMTRS"), root.all("CCSTMTRS")...)
+	for _, aggregate := range aggregates {
+		statement := Statement{AccountNumber: aggregate.value("BANKACCTFROM/ACCTID")}
+		if code, ok := NormalizeCurrency(aggregate.value("CURDEF")); ok {
+			statement.Currency = code
+		}
+		if statement.AccountNumber == "" {
+			statement.AccountNumber = aggregate.value("CCACCTFROM/ACCTID")
+		}
+		if balance := aggregate.value("LEDGERBAL/BALAMT"); balance != "" {
+			if amount, err := statementAmount(balance); err == nil {
+				statement.ClosingBalance = &amount
+			}
+		}
+		if asOf := aggregate.value("LEDGERBAL/DTASOF"); asOf != "" {
+			if date, err := ParseOFXDate(asOf); err == nil {
+				statement.ClosingDate = &date
+			}
+		}
+
+		for i, entry := range aggregate.all("STMTTRN") {
+			row := ofxRow(entry, statement.Currency)
+			row.Line = i + 1
+			statement.Rows = append(statement.Rows, row)
+		}
+		statements = append(statements, statement)
+	}
+	if len(statements) == 0 {
+		return nil, fmt.Errorf("file contains no bank or credit card statement")
+	}
+	return statements, nil
+}
+
+func ofxRow(entry *ofxElement, currency string) StatementRow {
+	name := entry.value("NAME")
+	if name == "" {
+		name = entry.value("PAYEE/NAME")
+	}
+	memo := entry.value("MEMO")
+	description := name
+	if memo != "" && !strings.EqualFold(memo, name) {
+		if description != "" {
+			description += " - "
+		}
+		description += memo
+	}
+
+	row := StatementRow{
+		Description: description,
+		Payee:       name,
+		Reference:   entry.value("FITID"),
+		Currency:    currency,
+	}
+	// Transaksi dalam currency lain (CURRENCY/ORIGCURRENCY): amount tetap dalam CURDEF statement,
+	// CURSYM hanya dipakai kalau statement tidak punya CURDEF
+	if row.Currency == "" {
+		if code, ok := NormalizeCurrency(entry.value("CURRENCY/CURSYM")); ok {
+			row.Currency = code
+		}
+	}
+
+	date, err := ParseOFXDate(entry.value("DTPOSTED"))
+	if err != nil {
+		row.Error = err.Error()
+		return row
+	}
+	row.Date = date
+
+	amount, err := statementAmount(entry.value("TRNAMT"))
+	if err != nil {
+		row.Error = err.Error()
+		return row
+	}
+	row.Amount, row.Kind = signedStatementAmount(amount)
+	if row.Amount.IsZero() {
+		row.Error = "amount is zero"
+	}
+	return row
+}
@@ -0,0 +1,187 @@
+package utils
+
+import "testing"
+
+// OFX 1.x (SGML): elemen daun tidak ditutup
+const sampleOFX1 = `OFXHEADER:100
+DATA:OFXSGML
+VERSION:102
+SECURITY:NONE
+ENCODING:USASCII
+CHARSET:1252
+COMPRESSION:NONE
+OLDFILEUID:NONE
+NEWFILEUID:NONE
+
+<OFX>
+<SIGNONMSGSRSV1><SONRS><STATUS><CODE>0<SEVERITY>INFO</STATUS><DTSERVER>20240331120000<LANGUAGE>ENG</SONRS></SIGNONMSGSRSV1>
+<BANKMSGSRSV1>
+<STMTTRNRS>
+<TRNUID>1
+<STATUS><CODE>0<SEVERITY>INFO</STATUS>
+<STMTRS>
+<CURDEF>USD
+<BANKACCTFROM>
+<BANKID>121000248
+<ACCTID>000123456789
+<ACCTTYPE>CHECKING
+</BANKACCTFROM>
+<BANKTRANLIST>
+<DTSTART>20240301
+<DTEND>20240331
+<STMTTRN>
+<TRNTYPE>DEBIT
+<DTPOSTED>20240305120000.000[-5:EST]
+<TRNAMT>-42.17
+<FITID>2024030501
+<NAME>WHOLE FOODS MARKET
+<MEMO>POS PURCHASE
+</STMTTRN>
+<STMTTRN>
+<TRNTYPE>CREDIT
+<DTPOSTED>20240315
+<TRNAMT>2500.00
+<FITID>2024031501
+<NAME>ACME CORP PAYROLL
+<MEMO>ACME CORP PAYROLL
+</STMTTRN>
+<STMTTRN>
+<TRNTYPE>DEBIT
+<DTPOSTED>2024
+<TRNAMT>-1.00
+<FITID>2024031502
+</STMTTRN>
+</BANKTRANLIST>
+<LEDGERBAL>
+<BALAMT>3457.83
+<DTASOF>20240331
+</LEDGERBAL>
+</STMTRS>
+</STMTTRNRS>
+</BANKMSGSRSV1>
+</OFX>
+`
+
+// OFX 2.x (XML) kartu kredit
+const sampleOFX2 = `<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<?OFX OFXHEADER="200" VERSION="220" SECURITY="NONE" OLDFILEUID="NONE" NEWFILEUID="NONE"?>
+<OFX>
+  <CREDITCARDMSGSRSV1>
+    <CCSTMTTRNRS>
+      <TRNUID>1</TRNUID>
+      <CCSTMTRS>
+        <CURDEF>IDR</CURDEF>
+        <CCACCTFROM><ACCTID>4111XXXXXXXX1111</ACCTID></CCACCTFROM>
+        <BANKTRANLIST>
+          <STMTTRN>
+            <TRNTYPE>DEBIT</TRNTYPE>
+            <DTPOSTED>20240402</DTPOSTED>
+            <TRNAMT>-1250000.00</TRNAMT>
+            <FITID>CC-0001</FITID>
+            <NAME>GARUDA INDONESIA &amp; CO</NAME>
+          </STMTTRN>
+          <STMTTRN>
+            <TRNTYPE>CREDIT</TRNTYPE>
+            <DTPOSTED>20240410</DTPOSTED>
+            <TRNAMT>1250000</TRNAMT>
+            <FITID>CC-0002</FITID>
+            <NAME>PAYMENT THANK YOU</NAME>
+          </STMTTRN>
+        </BANKTRANLIST>
+        <LEDGERBAL><BALAMT>-3500000</BALAMT><DTASOF>20240430</DTASOF></LEDGERBAL>
+      </CCSTMTRS>
+    </CCSTMTTRNRS>
+  </CREDITCARDMSGSRSV1>
+</OFX>
+`
+
+func TestParseOFX(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		account  string
+		currency string
+		closing  string
+		refs     []string
+		want     []wantRow
+	}{
+		{
+			name: "OFX 1.x checking", data: sampleOFX1, account: "000123456789", currency: "USD", closing: "3457.83",
+			refs: []string{"2024030501", "2024031501"},
+			want: []wantRow{
+				{line: 1, date: "2024-03-05", amount: "42.17", kind: "expense", description: "WHOLE FOODS MARKET - POS PURCHASE"},
+				{line: 2, date: "2024-03-15", amount: "2500", kind: "income", description: "ACME CORP PAYROLL"},
+				{err: true},
+			},
+		},
+		{
+			name: "OFX 2.x credit card", data: sampleOFX2, account: "4111XXXXXXXX1111", currency: "IDR", closing: "-3500000",
+			refs: []string{"CC-0001", "CC-0002"},
+			want: []wantRow{
+				{line: 1, date: "2024-04-02", amount: "1250000", kind: "expense", description: "GARUDA INDONESIA & CO"},
+				{line: 2, date: "2024-04-10", amount: "1250000", kind: "income"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		statements, err := ParseOFX([]byte(tt.data))
+		if err != nil {
+			t.Errorf("%s: error %v", tt.name, err)
+			continue
+		}
+		if len(statements) != 1 {
+			t.Errorf("%s: %d statements, want 1", tt.name, len(statements))
+			continue
+		}
+		statement := statements[0]
+		if statement.AccountNumber != tt.account || statement.Currency != tt.currency {
+			t.Errorf("%s: account %q %q, want %q %q", tt.name, statement.AccountNumber, statement.Currency, tt.account, tt.currency)
+		}
+		if statement.ClosingBalance == nil || statement.ClosingBalance.String() != tt.closing {
+			t.Errorf("%s: closing balance %v, want %s", tt.name, statement.ClosingBalance, tt.closing)
+		}
+		checkRows(t, tt.name, statement.Rows, tt.want)
+		for i, ref := range tt.refs {
+			if statement.Rows[i].Reference != ref {
+				t.Errorf("%s row %d: reference %q, want %q", tt.name, i, statement.Rows[i].Reference, ref)
+			}
+		}
+	}
+}
+
+func TestParseOFXInvalid(t *testing.T) {
+	tests := []string{
+		"Tanggal,Jumlah\n01/01/2024,100\n",
+		"<OFX><SIGNONMSGSRSV1></SIGNONMSGSRSV1></OFX>",
+	}
+	for _, data := range tests {
+		if _, err := ParseOFX([]byte(data)); err == nil {
+			t.Errorf("ParseOFX(%q): want error", data)
+		}
+	}
+}
+
+func TestParseOFXDate(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{value: "20240305", want: "2024-03-05"},
+		{value: "20240305120000", want: "2024-03-05"},
+		{value: "20240305235959.999[+7:WIB]", want: "2024-03-05"},
+		{value: "202403", wantErr: true},
+		{value: "20241305", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseOFXDate(tt.value)
+		if tt.wantErr != (err != nil) {
+			t.Errorf("ParseOFXDate(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && got.Format("2006-01-02") != tt.want {
+			t.Errorf("ParseOFXDate(%q) = %s, want %s", tt.value, got.Format("2006-01-02"), tt.want)
+		}
+	}
+}
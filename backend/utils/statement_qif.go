@@ -0,0 +1,223 @@
+package utils
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Jenis section QIF yang berisi transaksi rekening (bukan investasi, daftar category, dll)
+var qifAccountTypes = map[string]bool{
+	"bank": true, "cash": true, "ccard": true, "oth a": true, "oth l": true,
+}
+
+// ParseQIFDate membaca tanggal QIF seperti "01/05/2024", "1/ 5'24", "05.01.24" atau "2024-01-05".
+// dayFirst menentukan urutan hari/bulan untuk format dengan tahun di belakang.
+func ParseQIFDate(value string, dayFirst bool) (time.Time, error) {
+	normalized := strings.ReplaceAll(strings.TrimSpace(value), " ", "")
+	apostrophe := strings.Contains(normalized, "'")
+	parts := strings.FieldsFunc(normalized, func(r rune) bool {
+		return r == '/' || r == '-' || r == '.' || r == '\''
+	})
+	if len(parts) != 3 {
+		return time.Time{}, fmt.Errorf("invalid date %q", value)
+	}
+
+	numbers := make([]int, 3)
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid date %q", value)
+		}
+		numbers[i] = n
+	}
+
+	var year, month, day int
+	switch {
+	case len(parts[0]) == 4:
+		year, month, day = numbers[0], numbers[1], numbers[2]
+	case dayFirst:
+		day, month, year = numbers[0], numbers[1], numbers[2]
+	default:
+		month, day, year = numbers[0], numbers[1], numbers[2]
+	}
+	// Tahun 2 digit: apostrof (Quicken) berarti 2000-an, selain itu < 70 dianggap 2000-an
+	if len(parts[2]) <= 2 && len(parts[0]) != 4 {
+		if apostrophe || year < 70 {
+			year += 2000
+		} else {
+			year += 1900
+		}
+	}
+
+	date := time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+	if date.Month() != time.Month(month) || date.Day() != day {
+		return time.Time{}, fmt.Errorf("invalid date %q", value)
+	}
+	return date, nil
+}
+
+// ParseQIF membaca file QIF (Quicken Interchange Format). Setiap section !Type:Bank/Cash/CCard
+// jadi satu statement; nama rekening diambil dari blok !Account sebelumnya kalau ada.
+// Record "Opening Balance" dengan category [Rekening] dipakai sebagai saldo awal, bukan transaksi,
+// dan saldo akhir dihitung dari saldo awal + semua transaksi.
+func ParseQIF(data []byte, dayFirst bool) ([]Statement, error) {
+	data = bytes.TrimPrefix(data, []byte("\ufeff"))
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	var statements []Statement
+	var current *Statement
+	accountName := ""
+	inAccountBlock := false
+	record := map[byte]string{}
+	recordLine := 0
+	lineNumber := 0
+
+	finishRecord := func() {
+		if current != nil && len(record) > 0 {
+			qifRecord(current, record, recordLine, dayFirst)
+		}
+		record = map[byte]string{}
+		recordLine = 0
+	}
+
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		if line[0] == '!' {
+			finishRecord()
+			header := strings.ToLower(strings.TrimSpace(line[1:]))
+			switch {
+			case header == "account":
+				inAccountBlock, current = true, nil
+			case strings.HasPrefix(header, "type:") && qifAccountTypes[strings.TrimSpace(header[5:])]:
+				inAccountBlock = false
+				statements = append(statements, Statement{AccountNumber: accountName})
+				current = &statements[len(statements)-1]
+			default:
+				// !Type:Invst, !Type:Cat, !Option:... dan sejenisnya tidak diimport
+				inAccountBlock, current = false, nil
+			}
+			continue
+		}
+
+		if line[0] == '^' {
+			if inAccountBlock {
+				inAccountBlock = false
+				record = map[byte]string{}
+				continue
+			}
+			finishRecord()
+			continue
+		}
+
+		code, value := line[0], strings.TrimSpace(line[1:])
+		if inAccountBlock {
+			if code == 'N' {
+				accountName = value
+			}
+			continue
+		}
+		if recordLine == 0 {
+			recordLine = lineNumber
+		}
+		// Baris split (S, E, $) diabaikan, transaksi dipakai utuh
+		if _, ok := record[code]; !ok {
+			record[code] = value
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	finishRecord()
+
+	if len(statements) == 0 {
+		return nil, fmt.Errorf("file contains no bank, cash or credit card transactions")
+	}
+
+	// Saldo akhir = saldo awal + semua transaksi
+	for i := range statements {
+		statement := &statements[i]
+		if statement.OpeningBalance == nil {
+			continue
+		}
+		closing := *statement.OpeningBalance
+		var last *time.Time
+		for _, row := range statement.Rows {
+			if row.Error != "" {
+				continue
+			}
+			if row.Kind == "income" {
+				closing += row.Amount
+			} else {
+				closing -= row.Amount
+			}
+			if last == nil || row.Date.After(*last) {
+				date := row.Date
+				last = &date
+			}
+		}
+		if last == nil {
+			last = statement.OpeningDate
+		}
+		statement.ClosingBalance, statement.ClosingDate = &closing, last
+	}
+	return statements, nil
+}
+
+func qifRecord(statement *Statement, record map[byte]string, line int, dayFirst bool) {
+	row := StatementRow{Line: line, Payee: record['P']}
+
+	description := record['P']
+	if memo := record['M']; memo != "" && !strings.EqualFold(memo, description) {
+		if description != "" {
+			description += " - "
+		}
+		description += memo
+	}
+	row.Description = description
+
+	// Category "Makanan:Restoran" -> sub-category terakhir; "[Rekening]" adalah transfer
+	if category := record['L']; category != "" && !strings.HasPrefix(category, "[") {
+		parts := strings.Split(category, ":")
+		row.Category = strings.TrimSpace(parts[len(parts)-1])
+	}
+
+	date, err := ParseQIFDate(record['D'], dayFirst)
+	if err != nil {
+		row.Error = err.Error()
+		statement.Rows = append(statement.Rows, row)
+		return
+	}
+	row.Date = date
+
+	value := record['T']
+	if value == "" {
+		value = record['U']
+	}
+	amount, err := statementAmount(value)
+	if err != nil {
+		row.Error = err.Error()
+		statement.Rows = append(statement.Rows, row)
+		return
+	}
+
+	if strings.EqualFold(record['P'], "Opening Balance") && strings.HasPrefix(record['L'], "[") {
+		statement.OpeningBalance, statement.OpeningDate = &amount, &date
+		return
+	}
+
+	row.Amount, row.Kind = signedStatementAmount(amount)
+	if row.Amount.IsZero() {
+		row.Error = "amount is zero"
+	}
+	statement.Rows = append(statement.Rows, row)
+}
@@ -0,0 +1,103 @@
+package utils
+
+import "testing"
+
+// Ekspor QIF dari GnuCash/Quicken: blok !Account, saldo awal, split dan category bertingkat
+const sampleQIF = "!Account\r\n" +
+	"NBCA Tahapan\r\n" +
+	"TBank\r\n" +
+	"^\r\n" +
+	"!Type:Bank\r\n" +
+	"D01/03/2024\r\n" +
+	"T1,000,000.00\r\n" +
+	"POpening Balance\r\n" +
+	"L[BCA Tahapan]\r\n" +
+	"^\r\n" +
+	"D05/03/2024\r\n" +
+	"T-125,500.00\r\n" +
+	"PIndomaret\r\n" +
+	"MBelanja bulanan\r\n" +
+	"LBelanja:Kebutuhan Rumah\r\n" +
+	"SBelanja:Kebutuhan Rumah\r\n" +
+	"$-100,000.00\r\n" +
+	"SMakanan\r\n" +
+	"$-25,500.00\r\n" +
+	"^\r\n" +
+	"D25/03'24\r\n" +
+	"U8,500,000.00\r\n" +
+	"T8,500,000.00\r\n" +
+	"PPT Maju Jaya\r\n" +
+	"LGaji\r\n" +
+	"^\r\n" +
+	"D31/02/2024\r\n" +
+	"T-10,000.00\r\n" +
+	"PBiaya admin\r\n" +
+	"^\r\n" +
+	"!Type:Cat\r\n" +
+	"NMakanan\r\n" +
+	"E\r\n" +
+	"^\r\n"
+
+func TestParseQIF(t *testing.T) {
+	statements, err := ParseQIF([]byte(sampleQIF), true)
+	if err != nil {
+		t.Fatalf("ParseQIF error: %v", err)
+	}
+	if len(statements) != 1 {
+		t.Fatalf("%d statements, want 1", len(statements))
+	}
+	statement := statements[0]
+	if statement.AccountNumber != "BCA Tahapan" {
+		t.Errorf("account %q, want BCA Tahapan", statement.AccountNumber)
+	}
+	if statement.OpeningBalance == nil || statement.OpeningBalance.String() != "1000000" {
+		t.Errorf("opening balance %v, want 1000000", statement.OpeningBalance)
+	}
+	if statement.ClosingBalance == nil || statement.ClosingBalance.String() != "9374500" ||
+		statement.ClosingDate == nil || statement.ClosingDate.Format("2006-01-02") != "2024-03-25" {
+		t.Errorf("closing balance %v on %v, want 9374500 on 2024-03-25", statement.ClosingBalance, statement.ClosingDate)
+	}
+
+	checkRows(t, "QIF", statement.Rows, []wantRow{
+		{line: 11, date: "2024-03-05", amount: "125500", kind: "expense", description: "Indomaret - Belanja bulanan"},
+		{line: 21, date: "2024-03-25", amount: "8500000", kind: "income", description: "PT Maju Jaya"},
+		{err: true},
+	})
+	if statement.Rows[0].Category != "Kebutuhan Rumah" || statement.Rows[1].Category != "Gaji" {
+		t.Errorf("categories %q, %q", statement.Rows[0].Category, statement.Rows[1].Category)
+	}
+}
+
+func TestParseQIFNoAccount(t *testing.T) {
+	if _, err := ParseQIF([]byte("!Type:Invst\nD01/03/2024\nNBuy\n^\n"), true); err == nil {
+		t.Error("want error for a file without bank transactions")
+	}
+}
+
+func TestParseQIFDate(t *testing.T) {
+	tests := []struct {
+		value    string
+		dayFirst bool
+		want     string
+		wantErr  bool
+	}{
+		{value: "01/05/2024", dayFirst: true, want: "2024-05-01"},
+		{value: "01/05/2024", dayFirst: false, want: "2024-01-05"},
+		{value: "1/ 5'24", dayFirst: false, want: "2024-01-05"},
+		{value: "05.01.24", dayFirst: true, want: "2024-01-05"},
+		{value: "12/31/99", dayFirst: false, want: "1999-12-31"},
+		{value: "2024-01-05", dayFirst: false, want: "2024-01-05"},
+		{value: "31/02/2024", dayFirst: true, wantErr: true},
+		{value: "2024/01", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseQIFDate(tt.value, tt.dayFirst)
+		if tt.wantErr != (err != nil) {
+			t.Errorf("ParseQIFDate(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && got.Format("2006-01-02") != tt.want {
+			t.Errorf("ParseQIFDate(%q, %v) = %s, want %s", tt.value, tt.dayFirst, got.Format("2006-01-02"), tt.want)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/base64"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+func TestMakeThumbnail(t *testing.T) {
+	var pngData bytes.Buffer
+	if err := png.Encode(&pngData, image.NewRGBA(image.Rect(0, 0, 400, 100))); err != nil {
+		t.Fatal(err)
+	}
+	// WebP lossless 1x1
+	webpData, _ := base64.StdEncoding.DecodeString("UklGRhoAAABXRUJQVlA4TA0AAAAvAAAAEAcQERGIiP4HAA==")
+
+	tests := []struct {
+		name   string
+		data   []byte
+		width  int
+		height int
+	}{
+		{name: "png diperkecil", data: pngData.Bytes(), width: 200, height: 50},
+		{name: "webp kecil tidak diperbesar", data: webpData, width: 1, height: 1},
+	}
+	for _, tt := range tests {
+		thumb, err := MakeThumbnail(tt.data, 200)
+		if err != nil {
+			t.Errorf("%s: error %v", tt.name, err)
+			continue
+		}
+		cfg, err := jpeg.DecodeConfig(bytes.NewReader(thumb))
+		if err != nil || cfg.Width != tt.width || cfg.Height != tt.height {
+			t.Errorf("%s: %dx%d, %v, want %dx%d JPEG", tt.name, cfg.Width, cfg.Height, err, tt.width, tt.height)
+		}
+	}
+
+	if _, err := MakeThumbnail([]byte("%PDF-1.4"), 200); err == nil {
+		t.Error("want error for a non-image file")
+	}
+}
@@ -0,0 +1,389 @@
+package utils
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Bagian file XLSX (Office Open XML) yang dibutuhkan untuk membaca nilai sel
+type xlsxWorkbook struct {
+	Sheets []struct {
+		Name string `xml:"name,attr"`
+		RID  string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+type xlsxRelationships struct {
+	Relationships []struct {
+		ID     string `xml:"Id,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+type xlsxRichText struct {
+	Text string `xml:"t"`
+	Runs []struct {
+		Text string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (t xlsxRichText) String() string {
+	if len(t.Runs) == 0 {
+		return t.Text
+	}
+	var b strings.Builder
+	for _, run := range t.Runs {
+		b.WriteString(run.Text)
+	}
+	return b.String()
+}
+
+type xlsxSharedStrings struct {
+	Items []xlsxRichText `xml:"si"`
+}
+
+type xlsxSheet struct {
+	Rows []struct {
+		Cells []struct {
+			Ref    string       `xml:"r,attr"`
+			Type   string       `xml:"t,attr"`
+			Style  int          `xml:"s,attr"`
+			Value  string       `xml:"v"`
+			Inline xlsxRichText `xml:"is"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+type xlsxStyles struct {
+	NumberFormats []struct {
+		ID   int    `xml:"numFmtId,attr"`
+		Code string `xml:"formatCode,attr"`
+	} `xml:"numFmts>numFmt"`
+	CellFormats []struct {
+		NumberFormatID int `xml:"numFmtId,attr"`
+	} `xml:"cellXfs>xf"`
+}
+
+var xlsxFormatLiterals = regexp.MustCompile(`\[[^\]]*\]|"[^"]*"`)
+
+// Format angka bawaan Excel yang berupa tanggal/waktu
+func xlsxBuiltinDateFormat(id int) bool {
+	return (id >= 14 && id <= 22) || (id >= 45 && id <= 47)
+}
+
+func readZipFile(files map[string]*zip.File, name string, target interface{}) error {
+	file, ok := files[name]
+	if !ok {
+		return fmt.Errorf("missing %s", name)
+	}
+	reader, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+	return xml.NewDecoder(io.LimitReader(reader, 64<<20)).Decode(target)
+}
+
+// Kolom sel dari referensi seperti "AB12" (0-based)
+func xlsxColumn(ref string) int {
+	column := 0
+	for _, r := range ref {
+		if r < 'A' || r > 'Z' {
+			break
+		}
+		column = column*26 + int(r-'A'+1)
+	}
+	return column - 1
+}
+
+// Tanggal Excel disimpan sebagai jumlah hari sejak 30 Desember 1899
+func xlsxDate(value string) (string, bool) {
+	days, err := strconv.ParseFloat(value, 64)
+	if err != nil || days < 1 {
+		return "", false
+	}
+	date := time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC).Add(time.Duration(days * 24 * float64(time.Hour)))
+	date = date.Round(time.Second)
+	if date.Hour() == 0 && date.Minute() == 0 && date.Second() == 0 {
+		return date.Format("2006-01-02"), true
+	}
+	return date.Format("2006-01-02 15:04:05"), true
+}
+
+// ReadXLSX membaca sheet pertama file XLSX jadi baris-baris teks, seperti hasil CSV.
+// Sel tanggal diubah ke "YYYY-MM-DD" (plus jam kalau ada), sel kosong diisi "".
+func ReadXLSX(data []byte) ([][]string, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("not an XLSX file")
+	}
+	files := make(map[string]*zip.File, len(archive.File))
+	for _, file := range archive.File {
+		files[file.Name] = file
+	}
+
+	var workbook xlsxWorkbook
+	if err := readZipFile(files, "xl/workbook.xml", &workbook); err != nil {
+		return nil, err
+	}
+	if len(workbook.Sheets) == 0 {
+		return nil, fmt.Errorf("workbook has no sheets")
+	}
+
+	// Lokasi sheet pertama dari relationship workbook, default sheet1.xml
+	sheetPath := "xl/worksheets/sheet1.xml"
+	var relationships xlsxRelationships
+	if err := readZipFile(files, "xl/_rels/workbook.xml.rels", &relationships); err == nil {
+		for _, relationship := range relationships.Relationships {
+			if relationship.ID == workbook.Sheets[0].RID {
+				target := strings.TrimPrefix(relationship.Target, "/")
+				if !strings.HasPrefix(target, "xl/") {
+					target = path.Join("xl", target)
+				}
+				sheetPath = target
+			}
+		}
+	}
+
+	// sharedStrings dan styles optional (file hasil export sederhana kadang tidak punya)
+	var shared xlsxSharedStrings
+	readZipFile(files, "xl/sharedStrings.xml", &shared)
+	var styles xlsxStyles
+	readZipFile(files, "xl/styles.xml", &styles)
+	dateFormats := make(map[int]bool)
+	for _, format := range styles.NumberFormats {
+		// Warna ([Red]) dan teks literal ("...") bukan bagian format tanggal
+		code := strings.ToLower(xlsxFormatLiterals.ReplaceAllString(format.Code, ""))
+		if strings.ContainsAny(code, "dy") || strings.Contains(code, "mm") && strings.Contains(code, "h") {
+			dateFormats[format.ID] = true
+		}
+	}
+
+	var sheet xlsxSheet
+	if err := readZipFile(files, sheetPath, &sheet); err != nil {
+		return nil, err
+	}
+
+	rows := make([][]string, 0, len(sheet.Rows))
+	for _, sourceRow := range sheet.Rows {
+		var row []string
+		for i, cell := range sourceRow.Cells {
+			column := i
+			if cell.Ref != "" {
+				column = xlsxColumn(cell.Ref)
+			}
+			if column < 0 || column > 16383 {
+				continue
+			}
+			for len(row) <= column {
+				row = append(row, "")
+			}
+
+			value := cell.Value
+			switch cell.Type {
+			case "s":
+				index, err := strconv.Atoi(value)
+				if err == nil && index >= 0 && index < len(shared.Items) {
+					value = shared.Items[index].String()
+				}
+			case "inlineStr":
+				value = cell.Inline.String()
+			case "", "n":
+				if cell.Style >= 0 && cell.Style < len(styles.CellFormats) {
+					id := styles.CellFormats[cell.Style].NumberFormatID
+					if xlsxBuiltinDateFormat(id) || dateFormats[id] {
+						if date, ok := xlsxDate(value); ok {
+							value = date
+						}
+					}
+				}
+			}
+			row[column] = strings.TrimSpace(value)
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// XLSXCell adalah satu sel untuk XLSXWriter: teks, angka atau tanggal
+type XLSXCell struct {
+	value  string
+	kind   byte // t = teks, n = angka, d = tanggal
+	format int  // index cellXfs di xlsxStylesXML
+}
+
+// XLSXText membuat sel teks (inline string, tidak bisa dianggap formula oleh Excel)
+func XLSXText(value string) XLSXCell {
+	return XLSXCell{value: value, kind: 't'}
+}
+
+// XLSXNumber membuat sel angka dari string desimal ("-1234.50"). decimals 0, 2 atau 3
+// menentukan format tampilan (dengan pemisah ribuan sesuai locale Excel pembaca).
+func XLSXNumber(value string, decimals int) XLSXCell {
+	format := 4
+	switch {
+	case decimals <= 0:
+		format = 3
+	case decimals >= 3:
+		format = 5
+	}
+	return XLSXCell{value: value, kind: 'n', format: format}
+}
+
+// XLSXDate membuat sel tanggal (tanpa jam), ditampilkan dengan format tanggal writer
+func XLSXDate(date time.Time) XLSXCell {
+	day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+	days := int(day.Sub(time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)).Hours() / 24)
+	return XLSXCell{value: strconv.Itoa(days), kind: 'd', format: 2}
+}
+
+// XLSXWriter menulis file XLSX satu sheet baris demi baris langsung ke writer tujuan,
+// sehingga export besar tidak perlu ditampung di memori. Sheet ditulis paling akhir di zip;
+// Close wajib dipanggil untuk menutup sheet dan menulis central directory zip.
+type XLSXWriter struct {
+	archive *zip.Writer
+	sheet   io.Writer
+	rows    int
+}
+
+const xlsxContentTypesXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+	`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+	`<Default Extension="xml" ContentType="application/xml"/>` +
+	`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+	`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+	`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>` +
+	`</Types>`
+
+const xlsxRootRelsXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+	`</Relationships>`
+
+const xlsxWorkbookRelsXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+	`<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>` +
+	`</Relationships>`
+
+// cellXfs: 0 default, 1 header (bold), 2 tanggal, 3-5 angka dengan 0/2/3 desimal
+const xlsxStylesXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+	`<numFmts count="4"><numFmt numFmtId="164" formatCode="%s"/><numFmt numFmtId="165" formatCode="#,##0"/>` +
+	`<numFmt numFmtId="166" formatCode="#,##0.00"/><numFmt numFmtId="167" formatCode="#,##0.000"/></numFmts>` +
+	`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>` +
+	`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+	`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+	`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+	`<cellXfs count="6"><xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+	`<xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/>` +
+	`<xf numFmtId="164" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`<xf numFmtId="165" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`<xf numFmtId="166" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`<xf numFmtId="167" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/></cellXfs>` +
+	`</styleSheet>`
+
+// NewXLSXWriter menulis bagian workbook dan membuka sheet. dateFormat memakai kode format
+// Excel, misalnya "dd/mm/yyyy".
+func NewXLSXWriter(w io.Writer, sheetName, dateFormat string) (*XLSXWriter, error) {
+	var name bytes.Buffer
+	xml.EscapeText(&name, []byte(sheetName))
+	var format bytes.Buffer
+	xml.EscapeText(&format, []byte(dateFormat))
+
+	parts := []struct{ name, content string }{
+		{"[Content_Types].xml", xlsxContentTypesXML},
+		{"_rels/.rels", xlsxRootRelsXML},
+		{"xl/workbook.xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+			`<sheets><sheet name="` + name.String() + `" sheetId="1" r:id="rId1"/></sheets></workbook>`},
+		{"xl/_rels/workbook.xml.rels", xlsxWorkbookRelsXML},
+		{"xl/styles.xml", fmt.Sprintf(xlsxStylesXML, format.String())},
+	}
+
+	archive := zip.NewWriter(w)
+	for _, part := range parts {
+		file, err := archive.Create(part.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.WriteString(file, part.content); err != nil {
+			return nil, err
+		}
+	}
+
+	sheet, err := archive.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return nil, err
+	}
+	// Baris pertama (header) dibekukan supaya tetap terlihat saat scroll
+	if _, err := io.WriteString(sheet, `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">`+
+		`<sheetViews><sheetView workbookViewId="0"><pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/></sheetView></sheetViews>`+
+		`<sheetData>`); err != nil {
+		return nil, err
+	}
+	return &XLSXWriter{archive: archive, sheet: sheet}, nil
+}
+
+// Nama kolom Excel dari index mulai 0 (0 = A, 26 = AA)
+func xlsxColumnName(index int) string {
+	name := ""
+	for index >= 0 {
+		name = string(rune('A'+index%26)) + name
+		index = index/26 - 1
+	}
+	return name
+}
+
+// WriteHeader menulis baris judul kolom (bold)
+func (x *XLSXWriter) WriteHeader(columns []string) error {
+	cells := make([]XLSXCell, len(columns))
+	for i, column := range columns {
+		cells[i] = XLSXCell{value: column, kind: 't', format: 1}
+	}
+	return x.WriteRow(cells)
+}
+
+// WriteRow menulis satu baris. Sel teks kosong dilewati.
+func (x *XLSXWriter) WriteRow(cells []XLSXCell) error {
+	x.rows++
+	var b bytes.Buffer
+	fmt.Fprintf(&b, `<row r="%d">`, x.rows)
+	for i, cell := range cells {
+		ref := xlsxColumnName(i) + strconv.Itoa(x.rows)
+		switch {
+		case cell.kind == 't' && cell.value == "":
+			continue
+		case cell.kind == 't':
+			fmt.Fprintf(&b, `<c r="%s" t="inlineStr"`, ref)
+			if cell.format != 0 {
+				fmt.Fprintf(&b, ` s="%d"`, cell.format)
+			}
+			b.WriteString(`><is><t xml:space="preserve">`)
+			xml.EscapeText(&b, []byte(cell.value))
+			b.WriteString(`</t></is></c>`)
+		default:
+			fmt.Fprintf(&b, `<c r="%s" s="%d"><v>%s</v></c>`, ref, cell.format, cell.value)
+		}
+	}
+	b.WriteString(`</row>`)
+	_, err := x.sheet.Write(b.Bytes())
+	return err
+}
+
+// Close menutup sheet dan zip. Writer tujuan tidak ikut ditutup.
+func (x *XLSXWriter) Close() error {
+	if _, err := io.WriteString(x.sheet, `</sheetData></worksheet>`); err != nil {
+		return err
+	}
+	return x.archive.Close()
+}